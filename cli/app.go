@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"time"
+	"os/signal"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"github.com/btcsuite/btcutil"
 	"github.com/go-kit/kit/log"
@@ -22,15 +26,74 @@ import (
 var (
 	app = kingpin.New("janus", "Qtum adapter to Ethereum JSON RPC")
 
-	accountsFile = app.Flag("accounts", "account private keys (in WIF) returned by eth_accounts").Envar("ACCOUNTS").File()
+	configFile = app.Flag("config", "YAML config file overriding the flags, the log level, accounts, rate limits and methods are reloaded on SIGHUP").Envar("CONFIG").Default("").String()
 
-	qtumRPC     = app.Flag("qtum-rpc", "URL of qtum RPC service").Envar("QTUM_RPC").Default("").String()
+	accountsFile = app.Flag("accounts", "file of the account private keys (in WIF) returned by eth_accounts").Envar("ACCOUNTS").Default("").String()
+
+	qtumRPC     = app.Flag("qtum-rpc", "comma separated URLs of qtum RPC services, the first one holds the wallet and the others take over when it is unavailable or behind").Envar("QTUM_RPC").Default("").String()
 	qtumNetwork = app.Flag("qtum-network", "").Envar("QTUM_NETWORK").Default("regtest").String()
 	bind        = app.Flag("bind", "network interface to bind to (e.g. 0.0.0.0) ").Default("localhost").String()
 	port        = app.Flag("port", "port to serve proxy").Default("23889").Int()
 	httpsKey    = app.Flag("https-key", "https keyfile").Default("").String()
 	httpsCert   = app.Flag("https-cert", "https certificate").Default("").String()
 	logFile     = app.Flag("log-file", "write logs to a file").Envar("LOG_FILE").Default("").String()
+	logLevel    = app.Flag("log-level", "minimum level of the logs ("+strings.Join(logLevels, ", ")+"), debug with --dev and warn otherwise by default").Envar("LOG_LEVEL").Default("").Enum(append(logLevels, "")...)
+
+	zmqHashBlock = app.Flag("zmq-hashblock", "qtumd -zmqpubhashblock endpoint (e.g. tcp://127.0.0.1:28332) pushing new blocks instead of polling for them").Envar("ZMQ_HASHBLOCK").Default("").String()
+	zmqRawTx     = app.Flag("zmq-rawtx", "qtumd -zmqpubrawtx endpoint (e.g. tcp://127.0.0.1:28333) pushing new transactions instead of polling for them").Envar("ZMQ_RAWTX").Default("").String()
+
+	maxIdleConns        = app.Flag("qtum-max-idle-conns", "maximum idle connections kept open to the qtum RPC services, 0 for no limit").Envar("QTUM_MAX_IDLE_CONNS").Default(strconv.Itoa(qtum.DefaultTransportConfig.MaxIdleConns)).Int()
+	maxIdleConnsPerHost = app.Flag("qtum-max-idle-conns-per-host", "maximum idle connections kept open to each qtum RPC service").Envar("QTUM_MAX_IDLE_CONNS_PER_HOST").Default(strconv.Itoa(qtum.DefaultTransportConfig.MaxIdleConnsPerHost)).Int()
+	maxConnsPerHost     = app.Flag("qtum-max-conns-per-host", "maximum connections open to each qtum RPC service, 0 for no limit").Envar("QTUM_MAX_CONNS_PER_HOST").Default(strconv.Itoa(qtum.DefaultTransportConfig.MaxConnsPerHost)).Int()
+	idleConnTimeout     = app.Flag("qtum-idle-conn-timeout", "close connections to the qtum RPC services idle for that long").Envar("QTUM_IDLE_CONN_TIMEOUT").Default(qtum.DefaultTransportConfig.IdleConnTimeout.String()).Duration()
+	dialTimeout         = app.Flag("qtum-dial-timeout", "timeout connecting to a qtum RPC service").Envar("QTUM_DIAL_TIMEOUT").Default(qtum.DefaultTransportConfig.DialTimeout.String()).Duration()
+	keepAlive           = app.Flag("qtum-keep-alive", "period of TCP keep-alive probes on connections to the qtum RPC services").Envar("QTUM_KEEP_ALIVE").Default(qtum.DefaultTransportConfig.KeepAlive.String()).Duration()
+	tlsHandshakeTimeout = app.Flag("qtum-tls-handshake-timeout", "timeout of the TLS handshake with https qtum RPC services").Envar("QTUM_TLS_HANDSHAKE_TIMEOUT").Default(qtum.DefaultTransportConfig.TLSHandshakeTimeout.String()).Duration()
+	requestTimeout      = app.Flag("qtum-request-timeout", "timeout of a request to a qtum RPC service, waitforlogs excepted, 0 for no limit").Envar("QTUM_REQUEST_TIMEOUT").Default(qtum.DefaultTransportConfig.RequestTimeout.String()).Duration()
+
+	concurrency        = app.Flag("qtum-concurrency", "requests sent to qtum RPC services concurrently at start, the limit then adapts to how busy they are").Envar("QTUM_CONCURRENCY").Default(strconv.Itoa(qtum.DefaultLimiterConfig.Initial)).Int()
+	maxConcurrency     = app.Flag("qtum-max-concurrency", "maximum requests sent to qtum RPC services concurrently").Envar("QTUM_MAX_CONCURRENCY").Default(strconv.Itoa(qtum.DefaultLimiterConfig.Max)).Int()
+	maxQueued          = app.Flag("qtum-max-queued", "maximum requests waiting to be sent to qtum RPC services, further requests fail fast").Envar("QTUM_MAX_QUEUED").Default(strconv.Itoa(qtum.DefaultLimiterConfig.MaxQueued)).Int()
+	breakerFailures    = app.Flag("qtum-breaker-failures", "consecutive requests failing to reach qtum RPC services before failing requests fast, 0 to disable").Envar("QTUM_BREAKER_FAILURES").Default(strconv.Itoa(qtum.DefaultBreakerConfig.Failures)).Int()
+	breakerOpenTimeout = app.Flag("qtum-breaker-timeout", "time requests fail fast before qtum RPC services are tried again").Envar("QTUM_BREAKER_TIMEOUT").Default(qtum.DefaultBreakerConfig.OpenTimeout.String()).Duration()
+
+	maxRetryTime = app.Flag("qtum-max-retry-time", "time requests are retried while qtum RPC services are too busy to handle them").Envar("QTUM_MAX_RETRY_TIME").Default(qtum.DefaultBackoffConfig.MaxRetryTime.String()).Duration()
+	maxBackoff   = app.Flag("qtum-max-backoff", "maximum time between retries of requests qtum RPC services are too busy to handle").Envar("QTUM_MAX_BACKOFF").Default(qtum.DefaultBackoffConfig.MaxBackoff.String()).Duration()
+
+	healthCheckInterval = app.Flag("qtum-health-check-interval", "interval between health checks of the qtum RPC services when several are configured").Envar("QTUM_HEALTH_CHECK_INTERVAL").Default("10s").Duration()
+
+	rpcTimeout     = app.Flag("rpc-timeout", "deadline of eth RPC requests, 0 for no limit").Envar("RPC_TIMEOUT").Default("0s").Duration()
+	methodTimeouts = app.Flag("method-timeout", "deadline of the requests of an eth RPC method overriding --rpc-timeout (e.g. eth_getLogs=2m), can be repeated").PlaceHolder("METHOD=TIMEOUT").StringMap()
+
+	namespaces      = app.Flag("namespace", "namespace of the methods to enable ("+strings.Join(transformer.Namespaces, ", ")+"), all of them when not set, can be repeated").Envar("NAMESPACES").Enums(transformer.Namespaces...)
+	enabledMethods  = app.Flag("enable-method", "method to enable even though its namespace isn't, can be repeated").Envar("ENABLED_METHODS").Strings()
+	disabledMethods = app.Flag("disable-method", "method to disable, answered with a method not found error, can be repeated").Envar("DISABLED_METHODS").Strings()
+	readOnly        = app.Flag("read-only", "disable the methods using the accounts of janus or the wallet of qtumd ("+strings.Join(transformer.WalletMethods, ", ")+")").Envar("READ_ONLY").Default("false").Bool()
+
+	readyMinimumPeers    = app.Flag("ready-min-peers", "peers qtumd must be connected to for /ready to succeed").Envar("READY_MIN_PEERS").Default("1").Int64()
+	readyMaximumBlockAge = app.Flag("ready-max-block-age", "age of the best block above which /ready fails, 0 to disable").Envar("READY_MAX_BLOCK_AGE").Default("1h").Duration()
+
+	apiKeys       = app.Flag("api-key", "API key required to call the eth RPC methods, optionally restricted to a comma separated list of methods where a trailing * matches a prefix (e.g. KEY=eth_call,eth_get*), can be repeated").Envar("API_KEYS").PlaceHolder("KEY[=METHODS]").Strings()
+	apiKeysFile   = app.Flag("api-keys-file", "file of API keys added to --api-key, one KEY[=METHODS] per line").Envar("API_KEYS_FILE").Default("").String()
+	jwtSecret     = app.Flag("jwt-secret", "secret verifying HS256 JWTs, their methods claim restricts the methods they may call").Envar("JWT_SECRET").Default("").String()
+	jwtSecretFile = app.Flag("jwt-secret-file", "file of the secret verifying HS256 JWTs, instead of --jwt-secret").Envar("JWT_SECRET_FILE").Default("").String()
+	jwtPublicKey  = app.Flag("jwt-public-key", "PEM file of the public key verifying ES256 JWTs, their methods claim restricts the methods they may call").Envar("JWT_PUBLIC_KEY").Default("").String()
+
+	rateLimitIP        = app.Flag("rate-limit-ip", "cost of the requests each client IP may send per second, 0 for no limit").Envar("RATE_LIMIT_IP").Default("0").Float64()
+	rateLimitIPBurst   = app.Flag("rate-limit-ip-burst", "cost of the requests a client IP may send at once").Envar("RATE_LIMIT_IP_BURST").Default("100").Float64()
+	rateLimitKey       = app.Flag("rate-limit-key", "cost of the requests each API key or JWT may send per second, 0 for no limit").Envar("RATE_LIMIT_KEY").Default("0").Float64()
+	rateLimitKeyBurst  = app.Flag("rate-limit-key-burst", "cost of the requests an API key or JWT may send at once").Envar("RATE_LIMIT_KEY_BURST").Default("1000").Float64()
+	trustedProxies     = app.Flag("trusted-proxy", "IP or CIDR of a proxy whose X-Forwarded-For header identifies the client IPs, other clients are identified by their remote address, can be repeated").Envar("TRUSTED_PROXIES").Strings()
+	methodCosts        = app.Flag("method-cost", "cost of a method against the rate limits overriding the defaults (e.g. eth_getLogs=20), other methods cost 1, can be repeated").PlaceHolder("METHOD=COST").StringMap()
+	maxWSConnections   = app.Flag("max-ws-connections", "websocket connections each client may keep open, 0 for no limit").Envar("MAX_WS_CONNECTIONS").Default("0").Int()
+	maxWSSubscriptions = app.Flag("max-ws-subscriptions", "eth_subscribe subscriptions each client may hold, 0 for no limit").Envar("MAX_WS_SUBSCRIPTIONS").Default("0").Int()
+
+	maxCallGas  = app.Flag("max-call-gas", "gas limit above which eth_call answers 0x without calling qtumd").Envar("MAX_CALL_GAS").Default(strconv.FormatInt(transformer.MaximumGasLimit, 10)).Int64()
+	minGasLimit = app.Flag("min-gas-limit", "gas limit below which transactions are logged as too low").Envar("MIN_GAS_LIMIT").Default(strconv.FormatInt(transformer.MinimumGasLimit, 10)).Int64()
+
+	newHeadsInterval = app.Flag("new-heads-interval", "interval between polls of qtumd for the newHeads subscriptions, 0 for the default").Envar("NEW_HEADS_INTERVAL").Default("0s").Duration()
+
+	coinSelection = app.Flag("coin-selection", "strategy picking the UTXOs of locally signed transactions").Envar("COIN_SELECTION").Default(qtum.CoinSelectionLargestFirst).Enum(qtum.AllCoinSelections...)
 
 	devMode         = app.Flag("dev", "[Insecure] Developer mode").Envar("DEV").Default("false").Bool()
 	cachingInterval = app.Flag("caching-interval", "[Insecure] Interval(in ms) to cache blocks which would be disabled by default and could be enabled by setting this flag to any number > 0").Envar("CACHING_INTERVAL").Int64()
@@ -43,11 +106,17 @@ var (
 	generateToAddressTo = app.Flag("generateToAddressTo", "[regtest only] configure address to mine blocks to when mining new transactions in blocks").Envar("GENERATE_TO_ADDRESS").Default("").String()
 )
 
-func loadAccounts(r io.Reader, l log.Logger) qtum.Accounts {
+func loadAccounts(file string, l log.Logger) (qtum.Accounts, error) {
 	var accounts qtum.Accounts
 
-	if accountsFile != nil {
-		s := bufio.NewScanner(*accountsFile)
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "opening the accounts file")
+		}
+		defer f.Close()
+
+		s := bufio.NewScanner(f)
 		for s.Scan() {
 			line := s.Text()
 
@@ -67,26 +136,31 @@ func loadAccounts(r io.Reader, l log.Logger) qtum.Accounts {
 		level.Warn(l).Log("msg", "No accounts loaded from account file")
 	}
 
-	return accounts
+	return accounts, nil
 }
 
 func action(pc *kingpin.ParseContext) error {
-	addr := fmt.Sprintf("%s:%d", *bind, *port)
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	addr := fmt.Sprintf("%s:%d", cfg.Server.Bind, cfg.Server.Port)
 	writers := []io.Writer{os.Stdout}
 
-	if logFile != nil && (*logFile) != "" {
-		_, err := os.Stat(*logFile)
+	if cfg.Server.LogFile != "" {
+		_, err := os.Stat(cfg.Server.LogFile)
 		if os.IsNotExist(err) {
-			newLogFile, err := os.Create(*logFile)
+			newLogFile, err := os.Create(cfg.Server.LogFile)
 			if err != nil {
-				return errors.Wrapf(err, "Failed to create log file %s", *logFile)
+				return errors.Wrapf(err, "Failed to create log file %s", cfg.Server.LogFile)
 			} else {
 				writers = append(writers, newLogFile)
 			}
 		} else {
-			existingLogFile, err := os.Open(*logFile)
+			existingLogFile, err := os.Open(cfg.Server.LogFile)
 			if err != nil {
-				return errors.Wrapf(err, "Failed to open log file %s", *logFile)
+				return errors.Wrapf(err, "Failed to open log file %s", cfg.Server.LogFile)
 			} else {
 				writers = append(writers, existingLogFile)
 			}
@@ -94,61 +168,132 @@ func action(pc *kingpin.ParseContext) error {
 	}
 
 	logWriter := io.MultiWriter(writers...)
-	logger := log.NewLogfmtLogger(logWriter)
+	logger, err := newLevelLogger(log.NewLogfmtLogger(logWriter), cfg.Server.LogLevel)
+	if err != nil {
+		return err
+	}
 
-	if !*devMode {
-		logger = level.NewFilter(logger, level.AllowWarn())
+	accounts, err := loadAccounts(cfg.Accounts.File, logger)
+	if err != nil {
+		return err
 	}
 
-	var accounts qtum.Accounts
-	if *accountsFile != nil {
-		accounts = loadAccounts(*accountsFile, logger)
-		(*accountsFile).Close()
+	isMain := cfg.Upstream.Network == qtum.ChainMain
+
+	coinSelector, err := qtum.NewCoinSelector(cfg.Accounts.CoinSelection)
+	if err != nil {
+		return err
 	}
 
-	isMain := *qtumNetwork == qtum.ChainMain
+	qtumRPCURLs := cfg.Upstream.QtumRPC
 
 	qtumJSONRPC, err := qtum.NewClient(
 		isMain,
-		*qtumRPC,
-		qtum.SetDebug(*devMode),
+		qtumRPCURLs[0],
+		qtum.SetFailoverURLs(qtumRPCURLs[1:]...),
+		qtum.SetTransport(qtum.TransportConfig{
+			MaxIdleConns:        cfg.Upstream.MaxIdleConns,
+			MaxIdleConnsPerHost: cfg.Upstream.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     cfg.Upstream.MaxConnsPerHost,
+			IdleConnTimeout:     cfg.Upstream.IdleConnTimeout,
+			DialTimeout:         cfg.Upstream.DialTimeout,
+			KeepAlive:           cfg.Upstream.KeepAlive,
+			TLSHandshakeTimeout: cfg.Upstream.TLSHandshakeTimeout,
+			RequestTimeout:      cfg.Upstream.RequestTimeout,
+		}),
+		qtum.SetConcurrencyLimiter(qtum.LimiterConfig{
+			Initial:   cfg.Upstream.Concurrency,
+			Min:       qtum.DefaultLimiterConfig.Min,
+			Max:       cfg.Upstream.MaxConcurrency,
+			MaxQueued: cfg.Upstream.MaxQueued,
+		}),
+		qtum.SetCircuitBreaker(qtum.BreakerConfig{
+			Failures:    cfg.Upstream.BreakerFailures,
+			OpenTimeout: cfg.Upstream.BreakerTimeout,
+		}),
+		qtum.SetBackoff(qtum.BackoffConfig{
+			MaxRetryTime: cfg.Upstream.MaxRetryTime,
+			MaxBackoff:   cfg.Upstream.MaxBackoff,
+		}),
+		qtum.SetDebug(cfg.Development.Dev),
 		qtum.SetLogWriter(logWriter),
 		qtum.SetLogger(logger),
 		qtum.SetAccounts(accounts),
-		qtum.SetGenerateToAddress(*generateToAddressTo),
-		qtum.SetIgnoreUnknownTransactions(*ignoreUnknownTransactions),
-		qtum.SetDisableSnippingQtumRpcOutput(*disableSnipping),
-		qtum.SetHideQtumdLogs(*hideQtumdLogs),
+		qtum.SetCoinSelector(coinSelector),
+		qtum.SetGenerateToAddress(cfg.Accounts.GenerateToAddress),
+		qtum.SetIgnoreUnknownTransactions(cfg.Development.IgnoreTransactions),
+		qtum.SetDisableSnippingQtumRpcOutput(cfg.Development.DisableSnipping),
+		qtum.SetHideQtumdLogs(cfg.Development.HideQtumdLogs),
 	)
 	if err != nil {
 		return errors.Wrap(err, "jsonrpc#New")
 	}
 
-	qtumClient, err := qtum.New(qtumJSONRPC, *qtumNetwork)
+	qtumClient, err := qtum.New(qtumJSONRPC, cfg.Upstream.Network)
 	if err != nil {
 		return errors.Wrap(err, "qtum#New")
 	}
 
+	// background work runs as long as janus does
+	ctx := context.Background()
+
+	if len(qtumRPCURLs) > 1 {
+		go qtumJSONRPC.RunHealthChecks(ctx, cfg.Upstream.HealthCheckInterval)
+	}
+
+	zmqEndpoints := []struct {
+		endpoint string
+		topic    string
+	}{
+		{cfg.Upstream.ZMQHashBlock, qtum.ZMQTopicHashBlock},
+		{cfg.Upstream.ZMQRawTx, qtum.ZMQTopicRawTx},
+	}
+	for _, zmq := range zmqEndpoints {
+		if zmq.endpoint == "" {
+			continue
+		}
+		subscriber, err := qtum.NewZMQSubscriber(qtumJSONRPC, zmq.endpoint, zmq.topic)
+		if err != nil {
+			return errors.Wrap(err, "qtum#NewZMQSubscriber")
+		}
+		go subscriber.Run(ctx)
+	}
+
 	var cacher *transformer.BlockSyncer
-	if cachingInterval != nil && *cachingInterval > 0 {
-		cacher, _ = transformer.NewBlockSyncerWithBlockPollerAndInterval(qtumClient, &transformer.DefaultBlockPoller{qtumClient}, time.Duration(*cachingInterval)*time.Millisecond)
+	if cfg.Caching.Interval > 0 {
+		cacher, _ = transformer.NewBlockSyncerWithBlockPollerAndInterval(qtumClient, &transformer.DefaultBlockPoller{Qtum: qtumClient}, cfg.Caching.Interval)
 	}
 
-	agent := notifier.NewAgent(context.Background(), qtumClient, nil)
-	proxies := transformer.DefaultProxies(qtumClient, agent, cacher)
+	// the gas limits are read by the proxies, they are set before any request is served
+	transformer.MinimumGasLimit = cfg.Limits.MinGasLimit
+	transformer.MaximumGasLimit = cfg.Limits.MaxCallGas
+
+	agent := notifier.NewAgentWithConfig(ctx, qtumClient, nil, notifier.AgentConfig{
+		NewHeadsInterval:               cfg.Subscriptions.NewHeadsInterval,
+		NewPendingTransactionsInterval: cfg.Subscriptions.NewPendingTransactionsInterval,
+		SyncingInterval:                cfg.Subscriptions.SyncingInterval,
+	})
+	proxies := transformer.DefaultProxies(ctx, qtumClient, agent, cacher)
 	t, err := transformer.New(
 		qtumClient,
 		proxies,
-		transformer.SetDebug(*devMode),
+		transformer.SetDebug(cfg.Development.Dev),
 		transformer.SetLogger(logger),
+		transformer.SetRequestTimeouts(cfg.Limits.RPCTimeout, cfg.Limits.MethodTimeouts),
+		transformer.SetMethodFilter(cfg.methodFilter()),
 	)
 	if err != nil {
 		return errors.Wrap(err, "transformer#New")
 	}
 	agent.SetTransformer(t)
 
-	httpsKeyFile := getEmptyStringIfFileDoesntExist(*httpsKey, logger)
-	httpsCertFile := getEmptyStringIfFileDoesntExist(*httpsCert, logger)
+	authConfig, err := cfg.authConfig()
+	if err != nil {
+		return err
+	}
+
+	httpsKeyFile := getEmptyStringIfFileDoesntExist(cfg.Server.HTTPSKey, logger)
+	httpsCertFile := getEmptyStringIfFileDoesntExist(cfg.Server.HTTPSCert, logger)
 
 	s, err := server.New(
 		qtumClient,
@@ -156,17 +301,72 @@ func action(pc *kingpin.ParseContext) error {
 		addr,
 		server.SetLogWriter(logWriter),
 		server.SetLogger(logger),
-		server.SetDebug(*devMode),
-		server.SetSingleThreaded(*singleThreaded),
+		server.SetDebug(cfg.Development.Dev),
+		server.SetSingleThreaded(cfg.Development.SingleThreaded),
 		server.SetHttps(httpsKeyFile, httpsCertFile),
+		server.SetAuth(authConfig),
+		server.SetRateLimits(cfg.rateLimits()),
+		server.SetReadiness(server.ReadinessConfig{
+			MinimumPeers:    cfg.Server.ReadyMinPeers,
+			MaximumBlockAge: cfg.Server.ReadyMaxBlockAge,
+			Timeout:         server.DefaultReadinessConfig.Timeout,
+		}),
 	)
 	if err != nil {
 		return errors.Wrap(err, "server#New")
 	}
 
+	go reloadOnSignal(cfg, logger, qtumJSONRPC, t, s)
+
 	return s.Start()
 }
 
+// reloadOnSignal reloads the config on SIGHUP and applies the settings that can change without dropping
+// connections, the other settings only apply after a restart
+func reloadOnSignal(current *config, logger *levelLogger, client *qtum.Client, t *transformer.Transformer, s *server.Server) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	for range signals {
+		reloaded, err := loadConfig()
+		if err != nil {
+			level.Error(logger).Log("msg", "Failed to reload the config", "err", err)
+			continue
+		}
+
+		// each setting is applied on its own, an invalid one is reported and the previous one kept
+		if accounts, err := loadAccounts(reloaded.Accounts.File, logger); err != nil {
+			level.Error(logger).Log("msg", "Failed to reload the accounts", "err", err)
+		} else {
+			client.ReloadAccounts(accounts)
+		}
+		if err := t.ReloadMethodFilter(reloaded.methodFilter()); err != nil {
+			level.Error(logger).Log("msg", "Failed to reload the methods", "err", err)
+		}
+		if err := s.ReloadRateLimits(reloaded.rateLimits()); err != nil {
+			level.Error(logger).Log("msg", "Failed to reload the rate limits", "err", err)
+		}
+		if err := logger.SetLevel(reloaded.Server.LogLevel); err != nil {
+			level.Error(logger).Log("msg", "Failed to reload the log level", "err", err)
+		}
+
+		if !reflect.DeepEqual(current.withReloadable(reloaded), reloaded) {
+			level.Warn(logger).Log("msg", "Some changed settings only apply after a restart")
+		}
+		current = current.withReloadable(reloaded)
+		level.Warn(logger).Log("msg", "Reloaded the config")
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func getEmptyStringIfFileDoesntExist(file string, l log.Logger) string {
 	_, err := os.Stat(file)
 	if os.IsNotExist(err) {
@@ -177,9 +377,6 @@ func getEmptyStringIfFileDoesntExist(file string, l log.Logger) string {
 }
 
 func Run() {
-	kingpin.MustParse(app.Parse(os.Args[1:]))
-}
-
-func init() {
 	app.Action(action)
+	kingpin.MustParse(app.Parse(os.Args[1:]))
 }
@@ -0,0 +1,418 @@
+package cli
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/server"
+	"github.com/qtumproject/janus/pkg/transformer"
+	"gopkg.in/yaml.v2"
+)
+
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// config holds the settings of janus, taken from the flags and overridden by the settings of the config file
+type config struct {
+	Server        serverConfig        `yaml:"server"`
+	Upstream      upstreamConfig      `yaml:"upstream"`
+	Accounts      accountsConfig      `yaml:"accounts"`
+	Limits        limitsConfig        `yaml:"limits"`
+	Methods       methodsConfig       `yaml:"methods"`
+	Auth          authConfig          `yaml:"auth"`
+	Caching       cachingConfig       `yaml:"caching"`
+	Subscriptions subscriptionsConfig `yaml:"subscriptions"`
+	Development   developmentConfig   `yaml:"development"`
+}
+
+type serverConfig struct {
+	Bind      string `yaml:"bind"`
+	Port      int    `yaml:"port"`
+	HTTPSKey  string `yaml:"https-key"`
+	HTTPSCert string `yaml:"https-cert"`
+	LogFile   string `yaml:"log-file"`
+	// LogLevel is reloaded on SIGHUP
+	LogLevel         string        `yaml:"log-level"`
+	ReadyMinPeers    int64         `yaml:"ready-min-peers"`
+	ReadyMaxBlockAge time.Duration `yaml:"ready-max-block-age"`
+}
+
+type upstreamConfig struct {
+	QtumRPC             []string      `yaml:"qtum-rpc"`
+	Network             string        `yaml:"network"`
+	ZMQHashBlock        string        `yaml:"zmq-hashblock"`
+	ZMQRawTx            string        `yaml:"zmq-rawtx"`
+	MaxIdleConns        int           `yaml:"max-idle-conns"`
+	MaxIdleConnsPerHost int           `yaml:"max-idle-conns-per-host"`
+	MaxConnsPerHost     int           `yaml:"max-conns-per-host"`
+	IdleConnTimeout     time.Duration `yaml:"idle-conn-timeout"`
+	DialTimeout         time.Duration `yaml:"dial-timeout"`
+	KeepAlive           time.Duration `yaml:"keep-alive"`
+	TLSHandshakeTimeout time.Duration `yaml:"tls-handshake-timeout"`
+	RequestTimeout      time.Duration `yaml:"request-timeout"`
+	Concurrency         int           `yaml:"concurrency"`
+	MaxConcurrency      int           `yaml:"max-concurrency"`
+	MaxQueued           int           `yaml:"max-queued"`
+	BreakerFailures     int           `yaml:"breaker-failures"`
+	BreakerTimeout      time.Duration `yaml:"breaker-timeout"`
+	HealthCheckInterval time.Duration `yaml:"health-check-interval"`
+	MaxRetryTime        time.Duration `yaml:"max-retry-time"`
+	MaxBackoff          time.Duration `yaml:"max-backoff"`
+}
+
+type accountsConfig struct {
+	// File is reloaded on SIGHUP
+	File              string `yaml:"file"`
+	CoinSelection     string `yaml:"coin-selection"`
+	GenerateToAddress string `yaml:"generate-to-address"`
+}
+
+// limitsConfig are the limits of the requests, the rate limits are reloaded on SIGHUP
+type limitsConfig struct {
+	RPCTimeout         time.Duration            `yaml:"rpc-timeout"`
+	MethodTimeouts     map[string]time.Duration `yaml:"method-timeouts"`
+	RateLimitIP        float64                  `yaml:"rate-limit-ip"`
+	RateLimitIPBurst   float64                  `yaml:"rate-limit-ip-burst"`
+	RateLimitKey       float64                  `yaml:"rate-limit-key"`
+	RateLimitKeyBurst  float64                  `yaml:"rate-limit-key-burst"`
+	TrustedProxies     []string                 `yaml:"trusted-proxies"`
+	MethodCosts        map[string]float64       `yaml:"method-costs"`
+	MaxWSConnections   int                      `yaml:"max-ws-connections"`
+	MaxWSSubscriptions int                      `yaml:"max-ws-subscriptions"`
+	MaxCallGas         int64                    `yaml:"max-call-gas"`
+	MinGasLimit        int64                    `yaml:"min-gas-limit"`
+}
+
+// methodsConfig are reloaded on SIGHUP
+type methodsConfig struct {
+	Namespaces []string `yaml:"namespaces"`
+	Enable     []string `yaml:"enable"`
+	Disable    []string `yaml:"disable"`
+	ReadOnly   bool     `yaml:"read-only"`
+}
+
+type authConfig struct {
+	// APIKeys map the keys to the methods they may call
+	APIKeys map[string][]string `yaml:"api-keys"`
+	// APIKeysFile lists more keys, one KEY[=METHODS] per line
+	APIKeysFile   string `yaml:"api-keys-file"`
+	JWTSecret     string `yaml:"jwt-secret"`
+	JWTSecretFile string `yaml:"jwt-secret-file"`
+	JWTPublicKey  string `yaml:"jwt-public-key"`
+}
+
+type cachingConfig struct {
+	// Interval between blocks cached, 0 disables the cache
+	Interval time.Duration `yaml:"interval"`
+}
+
+type subscriptionsConfig struct {
+	NewHeadsInterval               time.Duration `yaml:"new-heads-interval"`
+	NewPendingTransactionsInterval time.Duration `yaml:"new-pending-transactions-interval"`
+	SyncingInterval                time.Duration `yaml:"syncing-interval"`
+}
+
+type developmentConfig struct {
+	Dev                bool `yaml:"dev"`
+	SingleThreaded     bool `yaml:"single-threaded"`
+	IgnoreTransactions bool `yaml:"ignore-transactions"`
+	DisableSnipping    bool `yaml:"disable-snipping"`
+	HideQtumdLogs      bool `yaml:"hide-qtumd-logs"`
+}
+
+// loadConfig reads the flags and then the config file, if any
+func loadConfig() (*config, error) {
+	c, err := configFromFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		data, err := ioutil.ReadFile(*configFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading the config file")
+		}
+
+		// the maps of the config file replace the ones of the flags rather than being merged with them
+		flags := *c
+		c.Limits.MethodTimeouts, c.Limits.MethodCosts, c.Auth.APIKeys = nil, nil, nil
+		if err := yaml.UnmarshalStrict(data, c); err != nil {
+			return nil, errors.Wrapf(err, "parsing the config file %s", *configFile)
+		}
+		if c.Limits.MethodTimeouts == nil {
+			c.Limits.MethodTimeouts = flags.Limits.MethodTimeouts
+		}
+		if c.Limits.MethodCosts == nil {
+			c.Limits.MethodCosts = flags.Limits.MethodCosts
+		}
+		if c.Auth.APIKeys == nil {
+			c.Auth.APIKeys = flags.Auth.APIKeys
+		}
+	}
+
+	for method, cost := range server.DefaultMethodCosts {
+		if _, ok := c.Limits.MethodCosts[method]; !ok {
+			c.Limits.MethodCosts[method] = cost
+		}
+	}
+
+	if c.Server.LogLevel == "" {
+		c.Server.LogLevel = "warn"
+		if c.Development.Dev {
+			c.Server.LogLevel = "debug"
+		}
+	}
+
+	return c, c.validate()
+}
+
+func configFromFlags() (*config, error) {
+	c := &config{
+		Server: serverConfig{
+			Bind:             *bind,
+			Port:             *port,
+			HTTPSKey:         *httpsKey,
+			HTTPSCert:        *httpsCert,
+			LogFile:          *logFile,
+			LogLevel:         *logLevel,
+			ReadyMinPeers:    *readyMinimumPeers,
+			ReadyMaxBlockAge: *readyMaximumBlockAge,
+		},
+		Upstream: upstreamConfig{
+			Network:             *qtumNetwork,
+			ZMQHashBlock:        *zmqHashBlock,
+			ZMQRawTx:            *zmqRawTx,
+			MaxIdleConns:        *maxIdleConns,
+			MaxIdleConnsPerHost: *maxIdleConnsPerHost,
+			MaxConnsPerHost:     *maxConnsPerHost,
+			IdleConnTimeout:     *idleConnTimeout,
+			DialTimeout:         *dialTimeout,
+			KeepAlive:           *keepAlive,
+			TLSHandshakeTimeout: *tlsHandshakeTimeout,
+			RequestTimeout:      *requestTimeout,
+			Concurrency:         *concurrency,
+			MaxConcurrency:      *maxConcurrency,
+			MaxQueued:           *maxQueued,
+			BreakerFailures:     *breakerFailures,
+			BreakerTimeout:      *breakerOpenTimeout,
+			HealthCheckInterval: *healthCheckInterval,
+			MaxRetryTime:        *maxRetryTime,
+			MaxBackoff:          *maxBackoff,
+		},
+		Accounts: accountsConfig{
+			File:              *accountsFile,
+			CoinSelection:     *coinSelection,
+			GenerateToAddress: *generateToAddressTo,
+		},
+		Limits: limitsConfig{
+			RPCTimeout:         *rpcTimeout,
+			MethodTimeouts:     make(map[string]time.Duration, len(*methodTimeouts)),
+			RateLimitIP:        *rateLimitIP,
+			RateLimitIPBurst:   *rateLimitIPBurst,
+			RateLimitKey:       *rateLimitKey,
+			RateLimitKeyBurst:  *rateLimitKeyBurst,
+			TrustedProxies:     *trustedProxies,
+			MethodCosts:        make(map[string]float64, len(server.DefaultMethodCosts)+len(*methodCosts)),
+			MaxWSConnections:   *maxWSConnections,
+			MaxWSSubscriptions: *maxWSSubscriptions,
+			MaxCallGas:         *maxCallGas,
+			MinGasLimit:        *minGasLimit,
+		},
+		Methods: methodsConfig{
+			Namespaces: *namespaces,
+			Enable:     *enabledMethods,
+			Disable:    *disabledMethods,
+			ReadOnly:   *readOnly,
+		},
+		Auth: authConfig{
+			APIKeys:       make(map[string][]string, len(*apiKeys)),
+			APIKeysFile:   *apiKeysFile,
+			JWTSecret:     *jwtSecret,
+			JWTSecretFile: *jwtSecretFile,
+			JWTPublicKey:  *jwtPublicKey,
+		},
+		Subscriptions: subscriptionsConfig{
+			NewHeadsInterval: *newHeadsInterval,
+		},
+		Development: developmentConfig{
+			Dev:                *devMode,
+			SingleThreaded:     *singleThreaded,
+			IgnoreTransactions: *ignoreUnknownTransactions,
+			DisableSnipping:    *disableSnipping,
+			HideQtumdLogs:      *hideQtumdLogs,
+		},
+	}
+
+	for _, url := range strings.Split(*qtumRPC, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			c.Upstream.QtumRPC = append(c.Upstream.QtumRPC, url)
+		}
+	}
+
+	if cachingInterval != nil {
+		c.Caching.Interval = time.Duration(*cachingInterval) * time.Millisecond
+	}
+
+	var err error
+	for method, timeout := range *methodTimeouts {
+		if c.Limits.MethodTimeouts[method], err = time.ParseDuration(timeout); err != nil {
+			return nil, errors.Wrapf(err, "invalid timeout of %s", method)
+		}
+	}
+
+	for method, cost := range *methodCosts {
+		if c.Limits.MethodCosts[method], err = strconv.ParseFloat(cost, 64); err != nil {
+			return nil, errors.Wrapf(err, "invalid cost of %s", method)
+		}
+	}
+
+	for _, apiKey := range *apiKeys {
+		key, methods := parseAPIKey(apiKey)
+		c.Auth.APIKeys[key] = methods
+	}
+
+	return c, nil
+}
+
+// parseAPIKey splits KEY[=METHODS] into the key and its comma separated methods
+func parseAPIKey(apiKey string) (string, []string) {
+	parts := strings.SplitN(apiKey, "=", 2)
+	var methods []string
+	if len(parts) == 2 {
+		for _, method := range strings.Split(parts[1], ",") {
+			if method = strings.TrimSpace(method); method != "" {
+				methods = append(methods, method)
+			}
+		}
+	}
+	return parts[0], methods
+}
+
+// validate checks the settings that aren't validated by the packages they configure
+func (c *config) validate() error {
+	if len(c.Upstream.QtumRPC) == 0 {
+		return errors.New("the URL of a qtum RPC service is required")
+	}
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		return errors.Errorf("invalid port %d", c.Server.Port)
+	}
+	if !contains(logLevels, c.Server.LogLevel) {
+		return errors.Errorf("invalid log level %s, expected one of %s", c.Server.LogLevel, strings.Join(logLevels, ", "))
+	}
+	if !contains(qtum.AllCoinSelections, c.Accounts.CoinSelection) {
+		return errors.Errorf("invalid coin selection %s", c.Accounts.CoinSelection)
+	}
+	for _, ns := range c.Methods.Namespaces {
+		if !contains(transformer.Namespaces, ns) {
+			return errors.Errorf("unknown namespace %s", ns)
+		}
+	}
+	if c.Limits.MinGasLimit < 0 || c.Limits.MaxCallGas < c.Limits.MinGasLimit {
+		return errors.New("gas limits must satisfy 0 <= min-gas-limit <= max-call-gas")
+	}
+	for _, interval := range []time.Duration{c.Caching.Interval, c.Subscriptions.NewHeadsInterval, c.Subscriptions.NewPendingTransactionsInterval, c.Subscriptions.SyncingInterval} {
+		if interval < 0 {
+			return errors.New("intervals must not be negative")
+		}
+	}
+	return nil
+}
+
+func (c *config) methodFilter() transformer.MethodFilter {
+	filter := transformer.MethodFilter{
+		Namespaces:      c.Methods.Namespaces,
+		EnabledMethods:  c.Methods.Enable,
+		DisabledMethods: append([]string{}, c.Methods.Disable...),
+	}
+	if c.Methods.ReadOnly {
+		for _, method := range transformer.WalletMethods {
+			if !contains(filter.DisabledMethods, method) {
+				filter.DisabledMethods = append(filter.DisabledMethods, method)
+			}
+		}
+	}
+	return filter
+}
+
+func (c *config) rateLimits() server.RateLimitConfig {
+	return server.RateLimitConfig{
+		IPRate:           c.Limits.RateLimitIP,
+		IPBurst:          c.Limits.RateLimitIPBurst,
+		KeyRate:          c.Limits.RateLimitKey,
+		KeyBurst:         c.Limits.RateLimitKeyBurst,
+		TrustedProxies:   c.Limits.TrustedProxies,
+		MethodCosts:      c.Limits.MethodCosts,
+		MaxConnections:   c.Limits.MaxWSConnections,
+		MaxSubscriptions: c.Limits.MaxWSSubscriptions,
+	}
+}
+
+// withReloadable returns a copy of c with the settings reloaded on SIGHUP taken from reloaded,
+// so that comparing it with reloaded tells whether other settings changed
+func (c *config) withReloadable(reloaded *config) *config {
+	merged := *c
+	merged.Server.LogLevel = reloaded.Server.LogLevel
+	merged.Accounts.File = reloaded.Accounts.File
+	merged.Methods = reloaded.Methods
+	merged.Limits.RateLimitIP = reloaded.Limits.RateLimitIP
+	merged.Limits.RateLimitIPBurst = reloaded.Limits.RateLimitIPBurst
+	merged.Limits.RateLimitKey = reloaded.Limits.RateLimitKey
+	merged.Limits.RateLimitKeyBurst = reloaded.Limits.RateLimitKeyBurst
+	merged.Limits.TrustedProxies = reloaded.Limits.TrustedProxies
+	merged.Limits.MethodCosts = reloaded.Limits.MethodCosts
+	merged.Limits.MaxWSConnections = reloaded.Limits.MaxWSConnections
+	merged.Limits.MaxWSSubscriptions = reloaded.Limits.MaxWSSubscriptions
+	return &merged
+}
+
+func (c *config) authConfig() (server.AuthConfig, error) {
+	config := server.AuthConfig{
+		APIKeys:   make(map[string][]string, len(c.Auth.APIKeys)),
+		JWTSecret: []byte(c.Auth.JWTSecret),
+	}
+	for key, methods := range c.Auth.APIKeys {
+		config.APIKeys[key] = methods
+	}
+
+	if c.Auth.APIKeysFile != "" {
+		data, err := ioutil.ReadFile(c.Auth.APIKeysFile)
+		if err != nil {
+			return config, errors.Wrap(err, "reading the API keys file")
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				key, methods := parseAPIKey(line)
+				config.APIKeys[key] = methods
+			}
+		}
+	}
+
+	if c.Auth.JWTSecretFile != "" {
+		if c.Auth.JWTSecret != "" {
+			return config, errors.New("the JWT secret and the JWT secret file are mutually exclusive")
+		}
+		data, err := ioutil.ReadFile(c.Auth.JWTSecretFile)
+		if err != nil {
+			return config, errors.Wrap(err, "reading the JWT secret file")
+		}
+		if config.JWTSecret = []byte(strings.TrimSpace(string(data))); len(config.JWTSecret) == 0 {
+			return config, errors.New("the JWT secret file is empty")
+		}
+	}
+
+	if c.Auth.JWTPublicKey != "" {
+		pem, err := ioutil.ReadFile(c.Auth.JWTPublicKey)
+		if err != nil {
+			return config, errors.Wrap(err, "reading the JWT public key")
+		}
+		if config.JWTPublicKey, err = jwt.ParseECPublicKeyFromPEM(pem); err != nil {
+			return config, errors.Wrap(err, "parsing the JWT public key")
+		}
+	}
+
+	return config, nil
+}
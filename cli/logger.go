@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"sync"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// levelLogger filters out the logs below a level that can be changed while janus runs
+type levelLogger struct {
+	next log.Logger
+
+	mutex    sync.RWMutex
+	filtered log.Logger
+}
+
+func newLevelLogger(next log.Logger, lvl string) (*levelLogger, error) {
+	l := &levelLogger{next: next}
+	return l, l.SetLevel(lvl)
+}
+
+func (l *levelLogger) SetLevel(lvl string) error {
+	var allowed level.Option
+	switch lvl {
+	case "debug":
+		allowed = level.AllowDebug()
+	case "info":
+		allowed = level.AllowInfo()
+	case "warn":
+		allowed = level.AllowWarn()
+	case "error":
+		allowed = level.AllowError()
+	default:
+		return errors.Errorf("invalid log level %s", lvl)
+	}
+
+	l.mutex.Lock()
+	l.filtered = level.NewFilter(l.next, allowed)
+	l.mutex.Unlock()
+	return nil
+}
+
+func (l *levelLogger) Log(keyvals ...interface{}) error {
+	l.mutex.RLock()
+	filtered := l.filtered
+	l.mutex.RUnlock()
+	return filtered.Log(keyvals...)
+}
@@ -0,0 +1,62 @@
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// BloomLength is the length in bytes of the 2048 bits logs bloom of receipts and blocks
+const BloomLength = 256
+
+// Bloom is the logs bloom light clients and indexers match against the addresses and topics they look for
+// before fetching the receipts of a block
+type Bloom [BloomLength]byte
+
+// Add sets the 3 bits of data taken from its keccak256 hash, as in the yellow paper
+func (b *Bloom) Add(data []byte) {
+	hash := crypto.Keccak256(data)
+	for i := 0; i < 6; i += 2 {
+		bit := (uint(hash[i])<<8 | uint(hash[i+1])) & (BloomLength*8 - 1)
+		b[BloomLength-1-bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// AddLog adds the address and topics of the log
+func (b *Bloom) AddLog(log Log) error {
+	address, err := hexutil.Decode(log.Address)
+	if err != nil {
+		return errors.Wrapf(err, "invalid log address %s", log.Address)
+	}
+	b.Add(address)
+	for _, topic := range log.Topics {
+		decoded, err := hexutil.Decode(topic)
+		if err != nil {
+			return errors.Wrapf(err, "invalid log topic %s", topic)
+		}
+		b.Add(decoded)
+	}
+	return nil
+}
+
+// Or merges the bits of other into b, the bloom of a block being the union of the blooms of its receipts
+func (b *Bloom) Or(other Bloom) {
+	for i := range b {
+		b[i] |= other[i]
+	}
+}
+
+func (b Bloom) String() string {
+	return hexutil.Encode(b[:])
+}
+
+// LogsBloom is the bloom of the logs of a receipt
+func LogsBloom(logs []Log) (Bloom, error) {
+	var bloom Bloom
+	for _, log := range logs {
+		if err := bloom.AddLog(log); err != nil {
+			return Bloom{}, err
+		}
+	}
+	return bloom, nil
+}
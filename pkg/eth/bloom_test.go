@@ -0,0 +1,70 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// bloom9 is the big.Int based implementation of go-ethereum
+func bloom9(data []byte) *big.Int {
+	hash := crypto.Keccak256(data)
+	r := new(big.Int)
+	for i := 0; i < 6; i += 2 {
+		t := big.NewInt(1)
+		b := (uint(hash[i+1]) + (uint(hash[i]) << 8)) & 2047
+		r.Or(r, t.Lsh(t, b))
+	}
+	return r
+}
+
+func TestLogsBloom(t *testing.T) {
+	logs := []Log{
+		{
+			Address: "0xdb46f738bf32cdafb9a4a70eb8b44c76646bcaf0",
+			Topics: []string{
+				"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+				"0x0000000000000000000000007926223070547d2d15b2ef5e7383e541c338ffe9",
+			},
+		},
+		{
+			Address: "0x0000000000000000000000000000000000000086",
+		},
+	}
+
+	bloom, err := LogsBloom(logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bits := new(big.Int)
+	for _, value := range []string{logs[0].Address, logs[0].Topics[0], logs[0].Topics[1], logs[1].Address} {
+		bits.Or(bits, bloom9(hexutil.MustDecode(value)))
+	}
+	var want Bloom
+	copy(want[BloomLength-len(bits.Bytes()):], bits.Bytes())
+	if bloom != want {
+		t.Errorf("bloom differs from go-ethereum\nwant: %s\n got: %s", want, bloom)
+	}
+
+	var block Bloom
+	block.Or(bloom)
+	block.Or(Bloom{})
+	if block != bloom {
+		t.Errorf("merging an empty bloom changed the bloom")
+	}
+
+	empty, err := LogsBloom(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.String() != EmptyLogsBloom {
+		t.Errorf("expected the empty logs bloom, got %s", empty)
+	}
+
+	if _, err := LogsBloom([]Log{{Address: "not hex"}}); err == nil {
+		t.Errorf("expected an invalid address to fail")
+	}
+}
@@ -91,8 +91,9 @@ func (t *SendTransactionRequest) GasPriceHex() string {
 // ========== eth_sendRawTransaction ============= //
 
 type (
-	// Presents hexed string of a raw transaction
-	SendRawTransactionRequest [1]string
+	// Presents hexed string of a raw transaction, either a serialized Qtum transaction
+	// or a signed Ethereum transaction optionally followed by the signer's hexed Qtum public key or WIF key
+	SendRawTransactionRequest [2]string
 	// Presents hexed string of a transaction hash
 	SendRawTransactionResponse string
 )
@@ -394,15 +395,15 @@ type (
 	}
 
 	Log struct {
-		Removed          string   `json:"removed,omitempty"` // TAG - true when the log was removed, due to a chain reorganization. false if its a valid log.
-		LogIndex         string   `json:"logIndex"`          // QUANTITY - integer of the log index position in the block. null when its pending log.
-		TransactionIndex string   `json:"transactionIndex"`  // QUANTITY - integer of the transactions index position log was created from. null when its pending log.
-		TransactionHash  string   `json:"transactionHash"`   // DATA, 32 Bytes - hash of the transactions this log was created from. null when its pending log.
-		BlockHash        string   `json:"blockHash"`         // DATA, 32 Bytes - hash of the block where this log was in. null when its pending. null when its pending log.
-		BlockNumber      string   `json:"blockNumber"`       // QUANTITY - the block number where this log was in. null when its pending. null when its pending log.
-		Address          string   `json:"address"`           // DATA, 20 Bytes - address from which this log originated.
-		Data             string   `json:"data"`              // DATA - contains one or more 32 Bytes non-indexed arguments of the log.
-		Topics           []string `json:"topics"`            // Array of DATA - Array of 0 to 4 32 Bytes DATA of indexed log arguments.
+		Removed          bool     `json:"removed"`          // TAG - true when the log was removed, due to a chain reorganization. false if its a valid log.
+		LogIndex         string   `json:"logIndex"`         // QUANTITY - integer of the log index position in the block. null when its pending log.
+		TransactionIndex string   `json:"transactionIndex"` // QUANTITY - integer of the transactions index position log was created from. null when its pending log.
+		TransactionHash  string   `json:"transactionHash"`  // DATA, 32 Bytes - hash of the transactions this log was created from. null when its pending log.
+		BlockHash        string   `json:"blockHash"`        // DATA, 32 Bytes - hash of the block where this log was in. null when its pending. null when its pending log.
+		BlockNumber      string   `json:"blockNumber"`      // QUANTITY - the block number where this log was in. null when its pending. null when its pending log.
+		Address          string   `json:"address"`          // DATA, 20 Bytes - address from which this log originated.
+		Data             string   `json:"data"`             // DATA - contains one or more 32 Bytes non-indexed arguments of the log.
+		Topics           []string `json:"topics"`           // Array of DATA - Array of 0 to 4 32 Bytes DATA of indexed log arguments.
 		Type             string   `json:"type,omitempty"`
 	}
 )
@@ -458,6 +459,10 @@ func (r *GetCodeRequest) UnmarshalJSON(data []byte) error {
 // a filter id
 type NewBlockFilterResponse string
 
+// ========== eth_newPendingTransactionFilter ============= //
+
+type NewPendingTransactionFilterResponse string
+
 // ========== eth_uninstallFilter ============= //
 // the filter id
 type UninstallFilterRequest string
@@ -654,6 +659,17 @@ func newErrInvalidParameterType(idx int, gotType interface{}, wantedType interfa
 	return errors.Errorf("invalid %d parameter of %T type, but %T type is expected", idx, gotType, wantedType)
 }
 
+// ========== eth_syncing ============= //
+
+type (
+	// SyncingStatus is the eth_syncing result while the node is catching up with the network, false is returned otherwise
+	SyncingStatus struct {
+		StartingBlock string `json:"startingBlock"`
+		CurrentBlock  string `json:"currentBlock"`
+		HighestBlock  string `json:"highestBlock"`
+	}
+)
+
 // ========== eth_subscribe ============= //
 
 type (
@@ -711,6 +727,11 @@ type (
 		Timestamp        string `json:"timestamp"`
 		TransactionsRoot string `json:"transactionsRoot"`
 	}
+
+	EthSubscriptionSyncingResponse struct {
+		Syncing bool           `json:"syncing"`
+		Status  *SyncingStatus `json:"status,omitempty"`
+	}
 )
 
 var ErrInvalidAddresses = errors.New("Invalid addresses")
@@ -866,10 +887,17 @@ type GetBalanceResponse string
 type (
 	GetTransactionCountRequest struct {
 		Address string
-		Tag     string
+		// block number or one of "earliest", "latest" and "pending", defaults to "latest"
+		Tag string
 	}
 )
 
+func (r *GetTransactionCountRequest) UnmarshalJSON(data []byte) error {
+	tmp := []interface{}{&r.Address, &r.Tag}
+
+	return json.Unmarshal(data, &tmp)
+}
+
 // ========== getstorage ============= //
 type (
 	GetStorageRequest struct {
@@ -0,0 +1,246 @@
+package eth
+
+import (
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+)
+
+// Transaction types defined by EIP-2718, legacy transactions have no type byte
+const (
+	LegacyTxType     byte = 0x00
+	AccessListTxType byte = 0x01
+	DynamicFeeTxType byte = 0x02
+)
+
+// RawTransaction is an Ethereum transaction decoded from its signed RLP encoding,
+// as received by eth_sendRawTransaction
+type RawTransaction struct {
+	Type    byte
+	ChainID *big.Int // nil for legacy transactions signed without EIP-155 replay protection
+	Nonce   uint64
+	// GasPrice of a dynamic fee transaction is its max fee per gas
+	GasPrice *big.Int
+	Gas      uint64
+	// To is empty for contract creations
+	To    []byte
+	Value *big.Int
+	Data  []byte
+
+	V, R, S *big.Int
+
+	signingHash []byte
+}
+
+type legacyTx struct {
+	Nonce    uint64
+	GasPrice *big.Int
+	Gas      uint64
+	To       []byte
+	Value    *big.Int
+	Data     []byte
+	V, R, S  *big.Int
+}
+
+type accessListTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasPrice   *big.Int
+	Gas        uint64
+	To         []byte
+	Value      *big.Int
+	Data       []byte
+	AccessList rlp.RawValue
+	V, R, S    *big.Int
+}
+
+type dynamicFeeTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         []byte
+	Value      *big.Int
+	Data       []byte
+	AccessList rlp.RawValue
+	V, R, S    *big.Int
+}
+
+// IsRawTransaction reports whether the payload looks like a RLP encoded Ethereum transaction
+// rather than a serialized Qtum transaction, which starts with a little endian version number
+func IsRawTransaction(raw []byte) bool {
+	if len(raw) < 2 {
+		return false
+	}
+	if raw[0] >= 0xc0 {
+		// legacy transactions are a plain RLP list
+		return true
+	}
+	// typed transactions are a type byte followed by a RLP list
+	return raw[0] <= 0x7f && raw[1] >= 0xc0
+}
+
+// DecodeRawTransaction decodes a signed legacy (optionally EIP-155 protected) or EIP-2718 typed transaction
+func DecodeRawTransaction(raw []byte) (*RawTransaction, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("empty raw transaction")
+	}
+
+	if raw[0] >= 0xc0 {
+		return decodeLegacyTransaction(raw)
+	}
+
+	payload := raw[1:]
+	switch raw[0] {
+	case AccessListTxType:
+		var tx accessListTx
+		if err := rlp.DecodeBytes(payload, &tx); err != nil {
+			return nil, errors.Wrap(err, "couldn't decode access list transaction")
+		}
+		unsigned := []interface{}{tx.ChainID, tx.Nonce, tx.GasPrice, tx.Gas, tx.To, tx.Value, tx.Data, tx.AccessList}
+		hash, err := typedSigningHash(AccessListTxType, unsigned)
+		if err != nil {
+			return nil, err
+		}
+		return &RawTransaction{
+			Type:        AccessListTxType,
+			ChainID:     tx.ChainID,
+			Nonce:       tx.Nonce,
+			GasPrice:    tx.GasPrice,
+			Gas:         tx.Gas,
+			To:          tx.To,
+			Value:       tx.Value,
+			Data:        tx.Data,
+			V:           tx.V,
+			R:           tx.R,
+			S:           tx.S,
+			signingHash: hash,
+		}, nil
+	case DynamicFeeTxType:
+		var tx dynamicFeeTx
+		if err := rlp.DecodeBytes(payload, &tx); err != nil {
+			return nil, errors.Wrap(err, "couldn't decode dynamic fee transaction")
+		}
+		unsigned := []interface{}{tx.ChainID, tx.Nonce, tx.GasTipCap, tx.GasFeeCap, tx.Gas, tx.To, tx.Value, tx.Data, tx.AccessList}
+		hash, err := typedSigningHash(DynamicFeeTxType, unsigned)
+		if err != nil {
+			return nil, err
+		}
+		return &RawTransaction{
+			Type:        DynamicFeeTxType,
+			ChainID:     tx.ChainID,
+			Nonce:       tx.Nonce,
+			GasPrice:    tx.GasFeeCap,
+			Gas:         tx.Gas,
+			To:          tx.To,
+			Value:       tx.Value,
+			Data:        tx.Data,
+			V:           tx.V,
+			R:           tx.R,
+			S:           tx.S,
+			signingHash: hash,
+		}, nil
+	default:
+		return nil, errors.Errorf("unsupported transaction type: %d", raw[0])
+	}
+}
+
+func decodeLegacyTransaction(raw []byte) (*RawTransaction, error) {
+	var tx legacyTx
+	if err := rlp.DecodeBytes(raw, &tx); err != nil {
+		return nil, errors.Wrap(err, "couldn't decode legacy transaction")
+	}
+
+	var (
+		chainID  *big.Int
+		unsigned = []interface{}{tx.Nonce, tx.GasPrice, tx.Gas, tx.To, tx.Value, tx.Data}
+	)
+	// EIP-155: v = chainId * 2 + 35 + {0, 1}, unprotected transactions use v = 27 + {0, 1}
+	if tx.V.BitLen() > 8 || (tx.V.Uint64() != 27 && tx.V.Uint64() != 28) {
+		chainID = new(big.Int).Sub(tx.V, big.NewInt(35))
+		chainID.Rsh(chainID, 1)
+		unsigned = append(unsigned, chainID, uint(0), uint(0))
+	}
+
+	encoded, err := rlp.EncodeToBytes(unsigned)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't encode transaction for signing")
+	}
+
+	return &RawTransaction{
+		Type:        LegacyTxType,
+		ChainID:     chainID,
+		Nonce:       tx.Nonce,
+		GasPrice:    tx.GasPrice,
+		Gas:         tx.Gas,
+		To:          tx.To,
+		Value:       tx.Value,
+		Data:        tx.Data,
+		V:           tx.V,
+		R:           tx.R,
+		S:           tx.S,
+		signingHash: crypto.Keccak256(encoded),
+	}, nil
+}
+
+func typedSigningHash(txType byte, unsigned []interface{}) ([]byte, error) {
+	encoded, err := rlp.EncodeToBytes(unsigned)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't encode transaction for signing")
+	}
+	return crypto.Keccak256(append([]byte{txType}, encoded...)), nil
+}
+
+// SigningHash returns the hash that was signed by the sender
+func (tx *RawTransaction) SigningHash() []byte {
+	return tx.signingHash
+}
+
+// IsCreateContract reports whether the transaction deploys a new contract
+func (tx *RawTransaction) IsCreateContract() bool {
+	return len(tx.To) == 0
+}
+
+// RecoveryID returns the signature's y parity
+func (tx *RawTransaction) RecoveryID() (byte, error) {
+	v := new(big.Int).Set(tx.V)
+	switch {
+	case tx.Type != LegacyTxType:
+	case tx.ChainID != nil:
+		v.Sub(v, new(big.Int).Add(new(big.Int).Lsh(tx.ChainID, 1), big.NewInt(35)))
+	default:
+		v.Sub(v, big.NewInt(27))
+	}
+	if v.Sign() < 0 || v.Cmp(big.NewInt(1)) > 0 {
+		return 0, errors.Errorf("invalid signature v value: %s", hexutil.EncodeBig(tx.V))
+	}
+	return byte(v.Uint64()), nil
+}
+
+// Sender recovers the public key that signed the transaction
+func (tx *RawTransaction) Sender() (*btcec.PublicKey, error) {
+	recoveryID, err := tx.RecoveryID()
+	if err != nil {
+		return nil, err
+	}
+	if tx.R == nil || tx.S == nil || tx.R.BitLen() > 256 || tx.S.BitLen() > 256 {
+		return nil, errors.New("invalid signature r, s values")
+	}
+
+	// btcec expects a compact signature: header byte followed by 32 byte r and s
+	sig := make([]byte, 65)
+	sig[0] = 27 + recoveryID
+	copy(sig[33-len(tx.R.Bytes()):33], tx.R.Bytes())
+	copy(sig[65-len(tx.S.Bytes()):], tx.S.Bytes())
+
+	pubKey, _, err := btcec.RecoverCompact(btcec.S256(), sig, tx.signingHash)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't recover transaction sender")
+	}
+	return pubKey, nil
+}
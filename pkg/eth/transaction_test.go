@@ -0,0 +1,109 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Private key and signed transaction from the EIP-155 specification
+var eip155PrivateKey, _ = hex.DecodeString("4646464646464646464646464646464646464646464646464646464646464646")
+
+const eip155SignedTx = "f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+
+func TestDecodeLegacyEIP155RawTransaction(t *testing.T) {
+	raw, _ := hex.DecodeString(eip155SignedTx)
+	if !IsRawTransaction(raw) {
+		t.Fatal("expected payload to be recognized as an ethereum transaction")
+	}
+
+	tx, err := DecodeRawTransaction(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.ChainID.Int64() != 1 || tx.Nonce != 9 || tx.Gas != 21000 {
+		t.Fatalf("unexpected transaction fields: %+v", tx)
+	}
+	if tx.Value.String() != "1000000000000000000" {
+		t.Fatalf("unexpected value: %s", tx.Value)
+	}
+
+	expectedHash, _ := hex.DecodeString("daf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53")
+	if !bytes.Equal(tx.SigningHash(), expectedHash) {
+		t.Fatalf("unexpected signing hash: %x", tx.SigningHash())
+	}
+
+	assertSender(t, tx)
+}
+
+func TestDecodeTypedRawTransactions(t *testing.T) {
+	to, _ := hex.DecodeString("3535353535353535353535353535353535353535")
+	accessList := []interface{}{}
+	chainID := big.NewInt(8890)
+
+	for _, txType := range []byte{AccessListTxType, DynamicFeeTxType} {
+		var unsigned []interface{}
+		if txType == AccessListTxType {
+			unsigned = []interface{}{chainID, uint64(1), big.NewInt(40), uint64(250000), to, big.NewInt(0), []byte{0x01}, accessList}
+		} else {
+			unsigned = []interface{}{chainID, uint64(1), big.NewInt(1), big.NewInt(40), uint64(250000), to, big.NewInt(0), []byte{0x01}, accessList}
+		}
+
+		encoded, err := rlp.EncodeToBytes(unsigned)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := crypto.Keccak256(append([]byte{txType}, encoded...))
+
+		privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), eip155PrivateKey)
+		sig, err := btcec.SignCompact(btcec.S256(), privKey, hash, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed := append(unsigned, uint(sig[0]-27), new(big.Int).SetBytes(sig[1:33]), new(big.Int).SetBytes(sig[33:]))
+		encoded, err = rlp.EncodeToBytes(signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw := append([]byte{txType}, encoded...)
+		if !IsRawTransaction(raw) {
+			t.Fatalf("expected type %d payload to be recognized as an ethereum transaction", txType)
+		}
+
+		tx, err := DecodeRawTransaction(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx.Type != txType || tx.ChainID.Cmp(chainID) != 0 || tx.GasPrice.Int64() != 40 || tx.IsCreateContract() {
+			t.Fatalf("unexpected transaction fields: %+v", tx)
+		}
+		if !bytes.Equal(tx.SigningHash(), hash) {
+			t.Fatalf("unexpected signing hash: %x", tx.SigningHash())
+		}
+
+		assertSender(t, tx)
+	}
+}
+
+func TestIsRawTransactionRejectsQtumTransactions(t *testing.T) {
+	raw, _ := hex.DecodeString("0200000001")
+	if IsRawTransaction(raw) {
+		t.Fatal("qtum transaction recognized as an ethereum transaction")
+	}
+}
+
+func assertSender(t *testing.T, tx *RawTransaction) {
+	sender, err := tx.Sender()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), eip155PrivateKey)
+	if !sender.IsEqual(pubKey) {
+		t.Fatalf("recovered sender %x, expected %x", sender.SerializeCompressed(), pubKey.SerializeCompressed())
+	}
+}
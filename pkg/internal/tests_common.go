@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -57,16 +56,60 @@ func (d *doerMappedMock) updateId(id int) {
 func (d *doerMappedMock) Do(request *http.Request) (*http.Response, error) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
-	requestJSON, err := parseRequestFromBody(request)
+	requestBody, err := ioutil.ReadAll(request.Body)
 	if err != nil {
 		return nil, err
 	}
 
+	var responseBody []byte
+	if len(requestBody) != 0 && requestBody[0] == '[' {
+		responseBody, err = d.batchResponse(requestBody)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		requestJSON := eth.JSONRPCRequest{}
+		if err := json.Unmarshal(requestBody, &requestJSON); err != nil {
+			return nil, err
+		}
+		responseBody = d.response(&requestJSON)
+	}
+
+	return &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader(responseBody)),
+	}, nil
+}
+
+// batchResponse answers each request of a batch with its mocked response, requests without one are left unanswered
+func (d *doerMappedMock) batchResponse(requestBody []byte) ([]byte, error) {
+	var requestsJSON []eth.JSONRPCRequest
+	if err := json.Unmarshal(requestBody, &requestsJSON); err != nil {
+		return nil, err
+	}
+
+	responses := make([]*eth.JSONRPCResult, 0, len(requestsJSON))
+	for i := range requestsJSON {
+		responseRaw := d.response(&requestsJSON[i])
+		if len(responseRaw) == 0 {
+			continue
+		}
+		var response eth.JSONRPCResult
+		if err := json.Unmarshal(responseRaw, &response); err != nil {
+			return nil, err
+		}
+		// mocked responses are matched by method, not id
+		response.ID = requestsJSON[i].ID
+		responses = append(responses, &response)
+	}
+	return json.Marshal(responses)
+}
+
+func (d *doerMappedMock) response(requestJSON *eth.JSONRPCRequest) []byte {
 	if d.Responses[requestJSON.Method] == nil && d.ResponsesDict[requestJSON.Method] == nil {
 		log.Printf("No mocked response for %s\n", requestJSON.Method)
 	}
 
-	var responseWriter io.ReadCloser
 	if responses, ok := d.ResponsesDict[requestJSON.Method]; ok {
 		params := requestJSON.Params
 		buffer := new(bytes.Buffer)
@@ -80,18 +123,11 @@ func (d *doerMappedMock) Do(request *http.Request) (*http.Response, error) {
 		}
 
 		if response, ok := responses[string(params)]; ok {
-			responseWriter = ioutil.NopCloser(bytes.NewReader(response))
+			return response
 		}
 	}
 
-	if responseWriter == nil {
-		responseWriter = ioutil.NopCloser(bytes.NewReader(d.popResponse(requestJSON.Method)))
-	}
-
-	return &http.Response{
-		StatusCode: 200,
-		Body:       responseWriter,
-	}, nil
+	return d.popResponse(requestJSON.Method)
 }
 
 func PrepareEthRPCRequest(id int, params []json.RawMessage) (*eth.JSONRPCRequest, error) {
@@ -270,21 +306,6 @@ func (d *doerMappedMock) AddErrorWithRequestID(requestID int, requestType string
 	return nil
 }
 
-func parseRequestFromBody(request *http.Request) (*eth.JSONRPCRequest, error) {
-	requestJSON := eth.JSONRPCRequest{}
-	requestBody, err := ioutil.ReadAll(request.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	err = json.Unmarshal(requestBody, &requestJSON)
-	if err != nil {
-		return nil, err
-	}
-
-	return &requestJSON, err
-}
-
 func CreateMockedClient(doerInstance Doer) (qtumClient *qtum.Qtum, err error) {
 	logger := kitLog.NewLogfmtLogger(os.Stdout)
 	if !isDebugEnvironmentVariableSet() {
@@ -334,6 +355,9 @@ var (
 		To:               "0x0000000000000000000000000000000000000000",
 		Gas:              "0x0",
 		GasPrice:         "0x0",
+		V:                "0x4595",
+		R:                "0x72d64a1f4ea2d54b7b05050fc853ab192c91cc5ca17e23007867f92f2ab59d92",
+		S:                "0x2b8c9ab9348c8edbb3b98b1788382c8f37642ec9bd6a4429817ab79927319200",
 	}
 
 	GetTransactionByHashResponse = CreateTransactionByHashResponse()
@@ -342,7 +366,7 @@ var (
 		Number:           GetTransactionByHashBlockNumberHex,
 		Hash:             GetTransactionByHashBlockHexHash,
 		ParentHash:       "0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be",
-		Miner:            "0x0000000000000000000000000000000000000000",
+		Miner:            "0x6b22910b1e302cf74803ffd1691c2ecb858d3712",
 		Size:             "0x26c",
 		Nonce:            "0x0000000000000000",
 		TransactionsRoot: "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
@@ -367,7 +391,7 @@ var (
 		Number:           GetTransactionByHashBlockNumberHex,
 		Hash:             GetTransactionByHashBlockHexHash,
 		ParentHash:       "0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be",
-		Miner:            "0x0000000000000000000000000000000000000000",
+		Miner:            "0x6b22910b1e302cf74803ffd1691c2ecb858d3712",
 		Size:             "0x26c",
 		Nonce:            "0x0000000000000000",
 		TransactionsRoot: "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
@@ -390,7 +414,7 @@ var (
 		Number:           GetTransactionByHashBlockNumberHex,
 		Hash:             GetTransactionByHashBlockHexHash,
 		ParentHash:       "0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be",
-		Miner:            "0x0000000000000000000000000000000000000000",
+		Miner:            "0x6b22910b1e302cf74803ffd1691c2ecb858d3712",
 		Size:             "0x26c",
 		Nonce:            "0x0000000000000000",
 		TransactionsRoot: "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
@@ -445,7 +469,7 @@ func CreateTransactionByHashResponse() eth.GetBlockByHashResponse {
 		Number:           GetTransactionByHashBlockNumberHex,
 		Hash:             GetTransactionByHashBlockHexHash,
 		ParentHash:       "0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be",
-		Miner:            "0x0000000000000000000000000000000000000000",
+		Miner:            "0x6b22910b1e302cf74803ffd1691c2ecb858d3712",
 		Size:             "0x26c",
 		Nonce:            "0x0000000000000000",
 		TransactionsRoot: "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
@@ -583,14 +607,24 @@ func SetupGetBlockByHashResponses(t *testing.T, mockedClientDoer Doer) {
 		t.Fatal(err)
 	}
 
-	getTransactionReceiptResponse := qtum.GetTransactionReceiptResponse{}
-	err = mockedClientDoer.AddResponse(qtum.MethodGetTransactionReceipt, &getTransactionReceiptResponse)
+	// the transactions of the block have no contract outputs
+	err = mockedClientDoer.AddResponse(qtum.MethodGetTransactionReceipt, []qtum.TransactionReceipt{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mockedClientDoer.AddResponse(qtum.MethodSearchLogs, qtum.SearchLogsResponse{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// TODO: Get an actual response for this (only addresses are used in this test though)
 	getRawTransactionResponse := qtum.GetRawTransactionResponse{
+		Hex: "020000000159c0514feea50f915854d9ec45bc6458bb14419c78b17e7be3f7fd5f563475b5010000006a473044022072d64a1f4ea2d54b7b05050fc853ab192c91cc5ca17e23007867f92f2ab59d9202202b8c9ab9348c8edbb3b98b1788382c8f37642ec9bd6a4429817ab79927319200012103520b1500a400483f19b93c4cb277a2f29693ea9d6739daaf6ae6e971d29e3140feffffff02000000000000000063010403400d0301644440c10f190000000000000000000000006b22910b1e302cf74803ffd1691c2ecb858d3712000000000000000000000000000000000000000000000000000000000000000a14be528c8378ff082e4ba43cb1baa363dbf3f577bfc260e66272970100001976a9146b22910b1e302cf74803ffd1691c2ecb858d371288acb00f0000",
+		Vins: []qtum.RawTransactionVin{{
+			ID:    "7f5350dc474f2953a3f30282c1afcad2fb61cdcea5bd949c808ecc6f64ce1503",
+			VoutN: 0,
+		}},
 		Vouts: []qtum.RawTransactionVout{
 			{
 				Details: struct {
@@ -1,13 +1,15 @@
 package internal
 
 import (
+	"context"
+
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/eth"
 )
 
 type ETHProxy interface {
-	Request(*eth.JSONRPCRequest, echo.Context) (interface{}, error)
+	Request(context.Context, *eth.JSONRPCRequest, echo.Context) (interface{}, error)
 	Method() string
 }
 
@@ -15,12 +17,12 @@ type mockTransformer struct {
 	proxies map[string]ETHProxy
 }
 
-func (t *mockTransformer) Transform(req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (t *mockTransformer) Transform(ctx context.Context, req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	proxy, ok := t.proxies[req.Method]
 	if !ok {
 		return nil, errors.New("couldn't get proxy")
 	}
-	resp, err := proxy.Request(req, c)
+	resp, err := proxy.Request(ctx, req, c)
 	if err != nil {
 		return nil, errors.WithMessagef(err, "couldn't proxy %s request", req.Method)
 	}
@@ -55,7 +57,7 @@ func NewMockETHProxy(method string, response interface{}) ETHProxy {
 	}
 }
 
-func (e *mockETHProxy) Request(*eth.JSONRPCRequest, echo.Context) (interface{}, error) {
+func (e *mockETHProxy) Request(context.Context, *eth.JSONRPCRequest, echo.Context) (interface{}, error) {
 	return e.response, nil
 }
 
@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "janus"
+
+// Registry holds the metrics of janus along the runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+// durationBuckets reach a minute as eth_getLogs and waitforlogs can be slow
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+var (
+	ethRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "eth",
+		Name:      "requests_total",
+		Help:      "eth RPC requests by method",
+	}, []string{"method"})
+	ethRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "eth",
+		Name:      "request_duration_seconds",
+		Help:      "time spent answering eth RPC requests by method",
+		Buckets:   durationBuckets,
+	}, []string{"method"})
+	ethErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "eth",
+		Name:      "errors_total",
+		Help:      "eth RPC requests answered with an error by method and JSON-RPC error code",
+	}, []string{"method", "code"})
+
+	qtumRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "qtum",
+		Name:      "requests_total",
+		Help:      "requests sent to qtumd by method and result (ok, error, overloaded, failed or cancelled)",
+	}, []string{"method", "result"})
+	qtumRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "qtum",
+		Name:      "request_duration_seconds",
+		Help:      "time qtumd took to answer requests by method",
+		Buckets:   durationBuckets,
+	}, []string{"method"})
+	qtumBackoffs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "qtum",
+		Name:      "backoffs_total",
+		Help:      "requests retried later because the work queue of qtumd was full",
+	})
+
+	websocketConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "connections",
+		Help:      "open websocket connections",
+	})
+	subscriptions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "subscriptions",
+		Help:      "active eth_subscribe subscriptions by type",
+	}, []string{"type"})
+
+	blockCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "block_cache",
+		Name:      "requests_total",
+		Help:      "blocks looked up in the block cache by result (hit or miss)",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		ethRequests,
+		ethRequestDuration,
+		ethErrors,
+		qtumRequests,
+		qtumRequestDuration,
+		qtumBackoffs,
+		websocketConnections,
+		subscriptions,
+		blockCacheRequests,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveETHRequest records an eth RPC request, code is the JSON-RPC error code it was answered with, if any
+func ObserveETHRequest(method string, duration time.Duration, code string) {
+	ethRequests.WithLabelValues(method).Inc()
+	ethRequestDuration.WithLabelValues(method).Observe(duration.Seconds())
+	if code != "" {
+		ethErrors.WithLabelValues(method, code).Inc()
+	}
+}
+
+func ObserveQtumRequest(method string, duration time.Duration, result string) {
+	qtumRequests.WithLabelValues(method, result).Inc()
+	qtumRequestDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+func QtumBackoff() {
+	qtumBackoffs.Inc()
+}
+
+func WebsocketOpened() {
+	websocketConnections.Inc()
+}
+
+func WebsocketClosed() {
+	websocketConnections.Dec()
+}
+
+func SubscriptionAdded(kind string) {
+	subscriptions.WithLabelValues(kind).Inc()
+}
+
+func SubscriptionRemoved(kind string) {
+	subscriptions.WithLabelValues(kind).Dec()
+}
+
+// BlockCacheLookup records whether a block was found in the block cache, the hit ratio is
+// rate(janus_block_cache_requests_total{result="hit"}[5m]) / rate(janus_block_cache_requests_total[5m])
+func BlockCacheLookup(hit bool) {
+	if hit {
+		blockCacheRequests.WithLabelValues("hit").Inc()
+	} else {
+		blockCacheRequests.WithLabelValues("miss").Inc()
+	}
+}
+
+// RegisterGaugeFunc exports a value read when the metrics are scraped, a gauge already registered is kept
+func RegisterGaugeFunc(subsystem string, name string, help string, value func() float64) {
+	register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, value))
+}
+
+// RegisterCounterFunc exports a count read when the metrics are scraped, a counter already registered is kept
+func RegisterCounterFunc(subsystem string, name string, help string, value func() float64) {
+	register(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, value))
+}
+
+func register(collector prometheus.Collector) {
+	if err := Registry.Register(collector); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T) string {
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := ioutil.ReadAll(recorder.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestMetricsExposition(t *testing.T) {
+	ObserveETHRequest("eth_blockNumber", 20*time.Millisecond, "")
+	ObserveETHRequest("eth_call", time.Second, "-32000")
+	ObserveQtumRequest("getblockcount", 10*time.Millisecond, "ok")
+	QtumBackoff()
+	WebsocketOpened()
+	SubscriptionAdded("newHeads")
+	SubscriptionAdded("newHeads")
+	SubscriptionRemoved("newHeads")
+	BlockCacheLookup(true)
+	BlockCacheLookup(false)
+
+	value := 1.0
+	RegisterGaugeFunc("qtum", "test_gauge", "test gauge", func() float64 { return value })
+	// registering again keeps the first gauge
+	RegisterGaugeFunc("qtum", "test_gauge", "test gauge", func() float64 { return 2 })
+	value = 3
+
+	body := scrape(t)
+	for _, line := range []string{
+		`janus_eth_requests_total{method="eth_blockNumber"} 1`,
+		`janus_eth_request_duration_seconds_count{method="eth_call"} 1`,
+		`janus_eth_errors_total{code="-32000",method="eth_call"} 1`,
+		`janus_qtum_requests_total{method="getblockcount",result="ok"} 1`,
+		`janus_qtum_backoffs_total 1`,
+		`janus_websocket_connections 1`,
+		`janus_websocket_subscriptions{type="newHeads"} 1`,
+		`janus_block_cache_requests_total{result="hit"} 1`,
+		`janus_block_cache_requests_total{result="miss"} 1`,
+		`janus_qtum_test_gauge 3`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("expected %q in the metrics\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, `janus_eth_errors_total{code="",`) {
+		t.Error("requests answered successfully shouldn't count as errors")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/metrics"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/qtumproject/janus/pkg/utils"
 )
@@ -20,13 +21,34 @@ var agentConfigNewHeadsInterval = 10 * time.Second
 
 // Allows dependency injection of eth rpc calls as the transformer package imports this package
 type Transformer interface {
-	Transform(req *eth.JSONRPCRequest, c echo.Context) (interface{}, error)
+	Transform(ctx context.Context, req *eth.JSONRPCRequest, c echo.Context) (interface{}, error)
 }
 
 func NewAgent(ctx context.Context, qtum *qtum.Qtum, transformer Transformer) *Agent {
 	return newAgentWithConfiguration(ctx, qtum, transformer, make(map[string]interface{}))
 }
 
+// AgentConfig sets how often qtumd is polled for the subscriptions, zero values keep the defaults
+type AgentConfig struct {
+	NewHeadsInterval               time.Duration
+	NewPendingTransactionsInterval time.Duration
+	SyncingInterval                time.Duration
+}
+
+func NewAgentWithConfig(ctx context.Context, qtum *qtum.Qtum, transformer Transformer, config AgentConfig) *Agent {
+	configuration := make(map[string]interface{})
+	for key, interval := range map[string]time.Duration{
+		agentConfigNewHeadsKey:               config.NewHeadsInterval,
+		agentConfigNewPendingTransactionsKey: config.NewPendingTransactionsInterval,
+		agentConfigSyncingKey:                config.SyncingInterval,
+	} {
+		if interval > 0 {
+			configuration[key] = interval
+		}
+	}
+	return newAgentWithConfiguration(ctx, qtum, transformer, configuration)
+}
+
 func newAgentWithConfiguration(ctx context.Context, qtum *qtum.Qtum, transformer Transformer, configuration map[string]interface{}) *Agent {
 	if ctx == nil {
 		panic("ctx cannot be nil")
@@ -42,10 +64,13 @@ func newAgentWithConfiguration(ctx context.Context, qtum *qtum.Qtum, transformer
 		running:       false,
 		config:        configuration,
 		stop:          make(chan interface{}, 1000),
-		newHeads:      newSubscriptionRegistry(),
-		logs:          newSubscriptionRegistry(),
-		newPendingTxs: newSubscriptionRegistry(),
-		syncing:       newSubscriptionRegistry(),
+		wake:          make(chan struct{}, 1),
+		newHeads:      newSubscriptionRegistry("newHeads"),
+		logs:          newSubscriptionRegistry("logs"),
+		newPendingTxs: newSubscriptionRegistry("newPendingTransactions"),
+		syncing:       newSubscriptionRegistry("syncing"),
+
+		pendingTransactions: newPendingTransactionsFeed(),
 	}
 
 	go agent.run()
@@ -53,13 +78,16 @@ func newAgentWithConfiguration(ctx context.Context, qtum *qtum.Qtum, transformer
 }
 
 type subscriptionRegistry struct {
+	// the subscription type, as in eth_subscribe
+	kind              string
 	mutex             sync.RWMutex
 	subscriptionCount int
 	subscriptions     map[string]*subscriptionInformation
 }
 
-func newSubscriptionRegistry() *subscriptionRegistry {
+func newSubscriptionRegistry(kind string) *subscriptionRegistry {
 	return &subscriptionRegistry{
+		kind:              kind,
 		mutex:             sync.RWMutex{},
 		subscriptionCount: 0,
 		subscriptions:     make(map[string]*subscriptionInformation),
@@ -106,11 +134,15 @@ type Agent struct {
 	mutex         sync.RWMutex
 	running       bool
 	stop          chan interface{}
+	wake          chan struct{}
 	config        map[string]interface{}
 	newHeads      *subscriptionRegistry
 	logs          *subscriptionRegistry
 	newPendingTxs *subscriptionRegistry
 	syncing       *subscriptionRegistry
+
+	pendingTransactions *pendingTransactionsFeed
+	syncStatus          qtum.SyncStatus
 }
 
 func (a *Agent) SetTransformer(transformer Transformer) {
@@ -203,6 +235,7 @@ func addSubscription(subscription *subscriptionInformation, registry *subscripti
 	registry.subscriptions[subscription.id] = subscription
 	if !collision {
 		registry.subscriptionCount = registry.subscriptionCount + 1
+		metrics.SubscriptionAdded(registry.kind)
 	}
 
 	go subscription.run()
@@ -218,6 +251,7 @@ func removeSubscription(id string, registry *subscriptionRegistry) {
 		if exists {
 			delete(registry.subscriptions, id)
 			registry.subscriptionCount = registry.subscriptionCount - 1
+			metrics.SubscriptionRemoved(registry.kind)
 		}
 		registry.mutex.Unlock()
 	}
@@ -258,6 +292,13 @@ func (a *Agent) NewSubscription(notifier *Notifier, params *eth.EthSubscriptionR
 		return "", errors.New(fmt.Sprintf("Unknown subscription type %s", params.Method))
 	}
 
+	a.start()
+
+	return subscription.id, nil
+}
+
+// start makes the processing thread pick up new work, starting it if nothing is running
+func (a *Agent) start() {
 	a.mutex.RLock()
 	if !a.running {
 		// start processing subscriptions if nothing is running
@@ -266,7 +307,10 @@ func (a *Agent) NewSubscription(notifier *Notifier, params *eth.EthSubscriptionR
 	}
 	a.mutex.RUnlock()
 
-	return subscription.id, nil
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
 }
 
 func (a *Agent) isRunning() bool {
@@ -290,6 +334,8 @@ func (a *Agent) run() {
 
 		a.qtum.GetDebugLogger().Log("msg", "Agent exited subscription processing thread")
 
+		// transactions entering the mempool while nothing polls it are missed, start from a new snapshot
+		a.pendingTransactions.reset()
 		a.running = false
 	}()
 
@@ -313,68 +359,74 @@ func (a *Agent) run() {
 		panic(fmt.Sprintf("Unexpected %s type", agentConfigNewHeadsKey))
 	}
 
-	// TODO: newPendingTransactions
+	newPendingTransactionsIntervalValue := a.getConfigValue(agentConfigNewPendingTransactionsKey, agentConfigNewPendingTransactionsInterval)
+	newPendingTransactionsInterval, ok := newPendingTransactionsIntervalValue.(time.Duration)
+	if !ok {
+		panic(fmt.Sprintf("Unexpected %s type", agentConfigNewPendingTransactionsKey))
+	}
+
+	syncingIntervalValue := a.getConfigValue(agentConfigSyncingKey, agentConfigSyncingInterval)
+	syncingInterval, ok := syncingIntervalValue.(time.Duration)
+	if !ok {
+		panic(fmt.Sprintf("Unexpected %s type", agentConfigSyncingKey))
+	}
+
+	// qtumd events trigger processing right away, polling catches up with missed ones
+	events := a.qtum.Events()
+	blockEvents, unsubscribeBlocks := events.Subscribe(qtum.EventBlock)
+	defer unsubscribeBlocks()
+	transactionEvents, unsubscribeTransactions := events.Subscribe(qtum.EventTransaction)
+	defer unsubscribeTransactions()
+
 	a.qtum.GetDebugLogger().Log("msg", "Agent started subscription processing thread")
 
+	var lastSyncing *eth.EthSubscriptionSyncingResponse
+	var nextNewHeads, nextNewPendingTransactions, nextSyncing time.Time
 	for {
-		// infinite loop while we have subscriptions
-		newHeadsSubscriptions := a.newHeads.Count()
-		if newHeadsSubscriptions == 0 {
+		// infinite loop while we have subscriptions or pending transaction filters are being read
+		processNewHeads := a.newHeads.Count() > 0
+		processPendingTransactions := a.newPendingTxs.Count() > 0 || a.pendingTransactions.isActive()
+		processSyncing := a.syncing.Count() > 0
+		if !processNewHeads && !processPendingTransactions && !processSyncing {
 			return
 		}
 
-		a.mutex.RLock()
-		transformer := a.transformer
-		a.mutex.RUnlock()
-		if transformer == nil {
-			a.qtum.GetErrorLogger().Log("msg", "Agent does not have access to eth transformer, cannot process 'newHeads' subscriptions")
-		} else {
-			blockchainInfo, err := a.qtum.GetBlockChainInfo()
-			if err != nil {
-				a.qtum.GetErrorLogger().Log("msg", "Failure getting blockchaininfo", "err", err)
-			} else {
-				latestBlock := blockchainInfo.Blocks
-				if lastBlock == 0 {
-					// prevent sending the current head to the first client connected
-					lastBlock = latestBlock
-					a.qtum.GetDebugLogger().Log("msg", "Got getblockchaininfo response for same block", "block", lastBlock)
-				} else if latestBlock > lastBlock {
-					a.qtum.GetDebugLogger().Log("msg", "New head detected", "block", latestBlock)
-					// get the latest block as an eth_getBlockByHash request
-					params, err := json.Marshal([]interface{}{
-						utils.AddHexPrefix(blockchainInfo.Bestblockhash),
-						false,
-					})
-					if err != nil {
-						panic(fmt.Sprintf("Failed to serialize eth_getBlockByHash request parameters: %s", err))
-					}
-					result, err := transformer.Transform(&eth.JSONRPCRequest{
-						JSONRPC: "2.0",
-						Method:  "eth_getBlockByHash",
-						Params:  params,
-					}, nil)
-					if err != nil {
-						a.qtum.GetErrorLogger().Log("msg", "Failed to eth_getBlockByHash", "hash", blockchainInfo.Bestblockhash, "err", err)
-					} else {
-						getBlockByHashResponse, ok := result.(*eth.GetBlockByHashResponse)
-						if !ok {
-							a.qtum.GetErrorLogger().Log("msg", "Failed to eth_getBlockByHash, unexpected response type", "hash", blockchainInfo.Bestblockhash)
-						} else {
-							lastBlock = latestBlock
-							// notify newHead
-							newHeadRespose := eth.NewEthSubscriptionNewHeadResponse(getBlockByHashResponse)
-							a.newHeads.SendAll(newHeadRespose)
-						}
-					}
-				} else {
-					a.qtum.GetDebugLogger().Log("msg", "Detected same head", "block", latestBlock)
-				}
+		var next time.Time
+		if processNewHeads {
+			if !time.Now().Before(nextNewHeads) {
+				lastBlock = a.processNewHeads(lastBlock)
+				nextNewHeads = time.Now().Add(events.PollInterval(qtum.EventBlock, newHeadsInterval))
+			}
+			next = nextNewHeads
+		}
+		if processPendingTransactions {
+			if !time.Now().Before(nextNewPendingTransactions) {
+				a.processPendingTransactions()
+				nextNewPendingTransactions = time.Now().Add(events.PollInterval(qtum.EventTransaction, newPendingTransactionsInterval))
+			}
+			if next.IsZero() || nextNewPendingTransactions.Before(next) {
+				next = nextNewPendingTransactions
+			}
+		}
+		if processSyncing {
+			if !time.Now().Before(nextSyncing) {
+				lastSyncing = a.processSyncing(lastSyncing)
+				nextSyncing = time.Now().Add(syncingInterval)
+			}
+			if next.IsZero() || nextSyncing.Before(next) {
+				next = nextSyncing
 			}
 		}
 
 		select {
-		case <-time.After(newHeadsInterval):
+		case <-time.After(time.Until(next)):
 			// continue
+		case <-a.wake:
+			// new work, continue
+		case <-blockEvents:
+			nextNewHeads = time.Time{}
+		case <-transactionEvents:
+			nextNewPendingTransactions = time.Time{}
 		case <-a.ctx.Done():
 			return
 		case <-a.stop:
@@ -382,3 +434,60 @@ func (a *Agent) run() {
 		}
 	}
 }
+
+// processNewHeads notifies 'newHeads' subscriptions when the chain tip moved past lastBlock and returns the latest notified block
+func (a *Agent) processNewHeads(lastBlock int64) int64 {
+	a.mutex.RLock()
+	transformer := a.transformer
+	a.mutex.RUnlock()
+	if transformer == nil {
+		a.qtum.GetErrorLogger().Log("msg", "Agent does not have access to eth transformer, cannot process 'newHeads' subscriptions")
+		return lastBlock
+	}
+
+	blockchainInfo, err := a.qtum.GetBlockChainInfo(a.ctx)
+	if err != nil {
+		a.qtum.GetErrorLogger().Log("msg", "Failure getting blockchaininfo", "err", err)
+		return lastBlock
+	}
+
+	latestBlock := blockchainInfo.Blocks
+	if lastBlock == 0 {
+		// prevent sending the current head to the first client connected
+		a.qtum.GetDebugLogger().Log("msg", "Got getblockchaininfo response for same block", "block", latestBlock)
+		return latestBlock
+	}
+	if latestBlock <= lastBlock {
+		a.qtum.GetDebugLogger().Log("msg", "Detected same head", "block", latestBlock)
+		return lastBlock
+	}
+
+	a.qtum.GetDebugLogger().Log("msg", "New head detected", "block", latestBlock)
+	// get the latest block as an eth_getBlockByHash request
+	params, err := json.Marshal([]interface{}{
+		utils.AddHexPrefix(blockchainInfo.Bestblockhash),
+		false,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("Failed to serialize eth_getBlockByHash request parameters: %s", err))
+	}
+	result, err := transformer.Transform(a.ctx, &eth.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getBlockByHash",
+		Params:  params,
+	}, nil)
+	if err != nil {
+		a.qtum.GetErrorLogger().Log("msg", "Failed to eth_getBlockByHash", "hash", blockchainInfo.Bestblockhash, "err", err)
+		return lastBlock
+	}
+	getBlockByHashResponse, ok := result.(*eth.GetBlockByHashResponse)
+	if !ok {
+		a.qtum.GetErrorLogger().Log("msg", "Failed to eth_getBlockByHash, unexpected response type", "hash", blockchainInfo.Bestblockhash)
+		return lastBlock
+	}
+
+	// notify newHead
+	newHeadRespose := eth.NewEthSubscriptionNewHeadResponse(getBlockByHashResponse)
+	a.newHeads.SendAll(newHeadRespose)
+	return latestBlock
+}
@@ -20,7 +20,7 @@ func TestAgentAddSubscriptionLogs(t *testing.T) {
 	defer cancel()
 
 	expectedSubscriptionID := "0x08e2af779d38a09e4c11442d9de22413"
-	want := `{"subscription":"` + expectedSubscriptionID + `","result":{"address":"0x0000000000000000000000000000000000000000","blockHash":"0xbba11e1bacc69ba535d478cf1f2e542da3735a517b0b8eebaf7e6bb25eeb48c5","blockNumber":"0xf8f","data":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","logIndex":"0x0","topics":["0xtopic1"],"transactionHash":"0x11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5","transactionIndex":"0x2"}}`
+	want := `{"subscription":"` + expectedSubscriptionID + `","result":{"address":"0x0000000000000000000000000000000000000000","blockHash":"0xbba11e1bacc69ba535d478cf1f2e542da3735a517b0b8eebaf7e6bb25eeb48c5","blockNumber":"0xf8f","data":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","logIndex":"0x0","removed":false,"topics":["0xtopic1"],"transactionHash":"0x11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5","transactionIndex":"0x2"}}`
 
 	doer := internal.NewDoerMappedMock()
 	topic1 := "topic1"
@@ -162,7 +162,7 @@ func TestAgentAddSubscriptionNewHeads(t *testing.T) {
 	defer cancel()
 
 	expectedSubscriptionID := "0x08e2af779d38a09e4c11442d9de22413"
-	want := `{"subscription":"` + expectedSubscriptionID + `","result":{"difficulty":"0x4","extraData":"0x0000000000000000000000000000000000000000000000000000000000000000","gasLimit":"0x5208","gasUsed":"0x0","logsBloom":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","miner":"0x0000000000000000000000000000000000000000","nonce":"0x0000000000000000","number":"0xf8f","parentHash":"0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be","receiptRoot":"0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334","sha3Uncles":"0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347","stateRoot":"","timestamp":"0x5b95ebd0","transactionsRoot":"0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334"}}`
+	want := `{"subscription":"` + expectedSubscriptionID + `","result":{"difficulty":"0x4","extraData":"0x0000000000000000000000000000000000000000000000000000000000000000","gasLimit":"0x2625a00","gasUsed":"0x0","logsBloom":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","miner":"0x6b22910b1e302cf74803ffd1691c2ecb858d3712","nonce":"0x0000000000000000","number":"0xf8f","parentHash":"0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be","receiptRoot":"0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334","sha3Uncles":"0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347","stateRoot":"","timestamp":"0x5b95ebd0","transactionsRoot":"0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334"}}`
 
 	doer := internal.NewDoerMappedMock()
 
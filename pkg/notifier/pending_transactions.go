@@ -0,0 +1,138 @@
+package notifier
+
+import (
+	"sync"
+	"time"
+
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+var agentConfigNewPendingTransactionsKey = "newPendingTransactionsInterval"
+var agentConfigNewPendingTransactionsInterval = 2 * time.Second
+
+// pendingTransactionsFilterTimeout is how long the mempool keeps being polled after a pending transaction filter was last read
+const pendingTransactionsFilterTimeout = 5 * time.Minute
+
+// pendingTransactionsBacklog bounds the number of transaction ids kept for pending transaction filters
+const pendingTransactionsBacklog = 10000
+
+// pendingTransactionsFeed tracks the transactions entering the mempool, every new transaction gets a sequence number
+// so that pending transaction filters can read the ones they haven't seen yet
+type pendingTransactionsFeed struct {
+	mutex       sync.Mutex
+	initialized bool
+	known       map[string]bool
+	// recent holds the most recent transaction ids, the last one has sequence number sequence
+	recent   []string
+	sequence uint64
+	lastRead time.Time
+}
+
+func newPendingTransactionsFeed() *pendingTransactionsFeed {
+	return &pendingTransactionsFeed{
+		known: make(map[string]bool),
+	}
+}
+
+// update replaces the mempool snapshot and returns the transactions that weren't in the previous one,
+// the first snapshot is only recorded so that the existing mempool isn't reported as new transactions
+func (f *pendingTransactionsFeed) update(mempool []string) []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	known := make(map[string]bool, len(mempool))
+	var added []string
+	for _, txid := range mempool {
+		known[txid] = true
+		if f.initialized && !f.known[txid] {
+			added = append(added, txid)
+		}
+	}
+	f.known = known
+	f.initialized = true
+
+	f.recent = append(f.recent, added...)
+	if len(f.recent) > pendingTransactionsBacklog {
+		f.recent = append([]string(nil), f.recent[len(f.recent)-pendingTransactionsBacklog:]...)
+	}
+	f.sequence += uint64(len(added))
+
+	return added
+}
+
+// reset forgets the mempool snapshot once it stops being polled, the sequence numbers keep growing
+func (f *pendingTransactionsFeed) reset() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.initialized = false
+	f.known = make(map[string]bool)
+}
+
+func (f *pendingTransactionsFeed) cursor() uint64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.lastRead = time.Now()
+	return f.sequence
+}
+
+func (f *pendingTransactionsFeed) since(cursor uint64) ([]string, uint64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.lastRead = time.Now()
+	missed := f.sequence - cursor
+	if cursor > f.sequence {
+		missed = 0
+	}
+	if missed > uint64(len(f.recent)) {
+		// transactions older than the backlog are lost
+		missed = uint64(len(f.recent))
+	}
+
+	txids := make([]string, 0, missed)
+	for _, txid := range f.recent[uint64(len(f.recent))-missed:] {
+		txids = append(txids, utils.AddHexPrefix(txid))
+	}
+	return txids, f.sequence
+}
+
+func (f *pendingTransactionsFeed) isActive() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return !f.lastRead.IsZero() && time.Since(f.lastRead) < pendingTransactionsFilterTimeout
+}
+
+// NewPendingTransactionsCursor starts polling the mempool for a pending transaction filter
+// and returns the cursor to pass to PendingTransactionsSince
+func (a *Agent) NewPendingTransactionsCursor() uint64 {
+	cursor := a.pendingTransactions.cursor()
+	a.start()
+	return cursor
+}
+
+// PendingTransactionsSince returns the ids of the transactions that entered the mempool after cursor
+// along with the cursor to read the next ones
+func (a *Agent) PendingTransactionsSince(cursor uint64) ([]string, uint64) {
+	txids, next := a.pendingTransactions.since(cursor)
+	a.start()
+	return txids, next
+}
+
+func (a *Agent) processPendingTransactions() {
+	mempool, err := a.qtum.GetRawMempool(a.ctx)
+	if err != nil {
+		a.qtum.GetErrorLogger().Log("msg", "Failure getting rawmempool", "err", err)
+		return
+	}
+
+	added := a.pendingTransactions.update(mempool)
+	if len(added) > 0 {
+		a.qtum.GetDebugLogger().Log("msg", "New pending transactions detected", "count", len(added))
+	}
+	for _, txid := range added {
+		a.newPendingTxs.SendAll(utils.AddHexPrefix(txid))
+	}
+}
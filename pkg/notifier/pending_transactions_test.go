@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestPendingTransactionsFeed(t *testing.T) {
+	feed := newPendingTransactionsFeed()
+
+	if added := feed.update([]string{"a", "b"}); len(added) != 0 {
+		t.Fatalf("first mempool snapshot reported as new transactions: %v", added)
+	}
+	cursor := feed.cursor()
+
+	if added := feed.update([]string{"b", "c", "d"}); !reflect.DeepEqual(added, []string{"c", "d"}) {
+		t.Fatalf("unexpected new transactions: %v", added)
+	}
+	feed.update([]string{"d", "e"})
+
+	txids, next := feed.since(cursor)
+	if want := []string{"0xc", "0xd", "0xe"}; !reflect.DeepEqual(txids, want) {
+		t.Fatalf("unexpected filter changes\nwant: %v\ngot: %v", want, txids)
+	}
+	if txids, _ := feed.since(next); len(txids) != 0 {
+		t.Fatalf("transactions read twice: %v", txids)
+	}
+
+	if !feed.isActive() {
+		t.Fatal("feed should be active after being read")
+	}
+}
+
+func TestAgentAddSubscriptionNewPendingTransactions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	doer := internal.NewDoerMappedMock()
+	doer.AddResponse(qtum.MethodGetRawMempool, qtum.GetRawMempoolResponse{"a"})
+	doer.AddResponse(qtum.MethodGetRawMempool, qtum.GetRawMempoolResponse{"a", "b"})
+
+	mockedClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentTestConfig := make(map[string]interface{})
+	// adjust newPendingTransactions interval to tick quicker for unit tests
+	agentTestConfig[agentConfigNewPendingTransactionsKey] = 100 * time.Millisecond
+
+	agent := newAgentWithConfiguration(ctx, mockedClient, nil, agentTestConfig)
+
+	notifierContext, cancelNotifierContext := context.WithCancel(ctx)
+
+	sentValuesChannel := make(chan []byte, 10)
+	send := func(v []byte) error {
+		sentValuesChannel <- v
+		return nil
+	}
+
+	notifier := NewNotifier(notifierContext, cancelNotifierContext, send, log.NewLogfmtLogger(os.Stdout))
+
+	id, err := agent.NewSubscription(notifier, &eth.EthSubscriptionRequest{
+		Method: "newPendingTransactions",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notifier.ResponseSent()
+
+	select {
+	case gotBytes := <-sentValuesChannel:
+		var receivedEthSubscription eth.EthSubscription
+		if err := json.Unmarshal(gotBytes, &receivedEthSubscription); err != nil {
+			t.Fatalf("Failed to unmarshal: %s: %s", string(gotBytes), err)
+		}
+		if receivedEthSubscription.SubscriptionID != id || receivedEthSubscription.Result != "0xb" {
+			t.Fatalf("unexpected newPendingTransactions notification: %s", string(gotBytes))
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatalf("Timed out waiting for subscription")
+	}
+
+	if !notifier.Unsubscribe(id) {
+		t.Fatalf("Failed to unsubscribe to subscription %s", id)
+	}
+
+	// check that the agent run loop has exited
+	for i := 0; i < 100 && agent.isRunning(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if agent.isRunning() {
+		t.Fatal("agent loop has not exited yet")
+	}
+}
@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+// logsReorgDepth is the number of blocks logs subscriptions remember the sent logs of,
+// qtumd refuses reorganizations deeper than 500 blocks
+const logsReorgDepth = 500
+
+// sentLogsBlock holds the logs of a block sent by a logs subscription
+type sentLogsBlock struct {
+	number uint64
+	hash   string
+	logs   []eth.Log
+	sent   map[string]bool
+}
+
+// sentLogs remembers the logs a logs subscription sent for the most recent blocks along with their block hash,
+// so that duplicates are dropped and the logs of replaced blocks can be sent again with `removed: true`
+type sentLogs struct {
+	depth uint64
+	// blocks are sorted by number
+	blocks []*sentLogsBlock
+}
+
+func newSentLogs(depth uint64) *sentLogs {
+	return &sentLogs{depth: depth}
+}
+
+func normalizeBlockHash(hash string) string {
+	return strings.ToLower(utils.RemoveHexPrefix(hash))
+}
+
+func (s *sentLogs) find(number uint64) (int, bool) {
+	index := sort.Search(len(s.blocks), func(i int) bool {
+		return s.blocks[i].number >= number
+	})
+	return index, index < len(s.blocks) && s.blocks[index].number == number
+}
+
+// conflicts reports whether logs were sent for another block at the same height
+func (s *sentLogs) conflicts(number uint64, hash string) bool {
+	index, found := s.find(number)
+	return found && s.blocks[index].hash != normalizeBlockHash(hash)
+}
+
+// add records a log and returns false when it was already sent
+func (s *sentLogs) add(number uint64, hash string, log eth.Log) bool {
+	index, found := s.find(number)
+	if !found {
+		if len(s.blocks) > 0 && number+s.depth <= s.blocks[len(s.blocks)-1].number {
+			// too old to be tracked, this can only be a log sent before
+			return false
+		}
+		block := &sentLogsBlock{
+			number: number,
+			hash:   normalizeBlockHash(hash),
+			sent:   make(map[string]bool),
+		}
+		s.blocks = append(s.blocks, nil)
+		copy(s.blocks[index+1:], s.blocks[index:])
+		s.blocks[index] = block
+	}
+
+	block := s.blocks[index]
+	key := computeHash(log)
+	if block.sent[key] {
+		return false
+	}
+	block.sent[key] = true
+	block.logs = append(block.logs, log)
+
+	s.prune()
+	return true
+}
+
+func (s *sentLogs) prune() {
+	highest := s.blocks[len(s.blocks)-1].number
+	keep := 0
+	for keep < len(s.blocks) && s.blocks[keep].number+s.depth <= highest {
+		keep++
+	}
+	s.blocks = s.blocks[keep:]
+}
+
+// remove forgets the blocks from number onwards and returns their logs flagged as removed, in the order they were sent
+func (s *sentLogs) remove(number uint64) []eth.Log {
+	index, _ := s.find(number)
+	var removed []eth.Log
+	for _, block := range s.blocks[index:] {
+		for _, log := range block.logs {
+			log.Removed = true
+			removed = append(removed, log)
+		}
+	}
+	s.blocks = s.blocks[:index]
+	return removed
+}
+
+// findReorg compares the remembered blocks with the main chain and returns the lowest replaced block number
+func (s *sentLogs) findReorg(ctx context.Context, client *qtum.Qtum) (uint64, bool, error) {
+	if len(s.blocks) == 0 {
+		return 0, false, nil
+	}
+
+	blockCount, err := client.GetBlockCount(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	// blocks of the main chain link to their parents, so the highest block that is still on it ends the search
+	for i := len(s.blocks) - 1; i >= 0; i-- {
+		block := s.blocks[i]
+		if block.number > blockCount.Uint64() {
+			continue
+		}
+		hash, err := client.GetBlockHash(ctx, new(big.Int).SetUint64(block.number))
+		if err != nil {
+			return 0, false, err
+		}
+		if normalizeBlockHash(string(hash)) == block.hash {
+			if i == len(s.blocks)-1 {
+				return 0, false, nil
+			}
+			return s.blocks[i+1].number, true, nil
+		}
+	}
+	return s.blocks[0].number, true, nil
+}
@@ -96,49 +96,85 @@ func (s *subscriptionInformation) run() {
 	// duplicate logs are only to be sent on a reorg
 	// the previous log that was sent on the old chain is sent with a `removed: true`
 	// then the new log is sent
-	// that functionality isn't supported in this implementation yet
-	// however, in order to not send duplicate logs we can do that with a simple hash map
-	// each hash is a 128bit MD5 hash, the hashing algorithim doesn't really matter here
-	// as this is only for preventing duplicate logs being sent over a websocket
-	// there are 8000 bits in a kilobyte, thats enough for 62.5 hashes
-	// 1MB = 1024KB = 1024KB/1KB = 1024 * 62.5 = 64,000 hashes
-	// when proving this as a service, that can add up if tens of thousands are using the service
-	// we want to put an upper limit on ram usage for an ip/connection
-	// we could also put an absolute upper limit on ram usage for this feature
-	// TODO: Deal with RAM usage here when Janus gets large enough
-	// some kind of FIFO hashmap?
-	sentHashes := make(map[string]bool)
+	// the logs sent for the last blocks are remembered with their block hash to detect both
+	sent := newSentLogs(logsReorgDepth)
+
+	// when qtumd pushes new blocks, logs are fetched once per block instead of holding a waitforlogs call open
+	blockEvents, unsubscribeBlocks := s.qtum.Events().Subscribe(qtum.EventBlock)
+	defer unsubscribeBlocks()
+	waitForBlock := false
 
 	failures := 0
 	for {
+		if s.qtum.Events().IsPushed(qtum.EventBlock) {
+			if waitForBlock {
+				select {
+				case <-s.ctx.Done():
+					return
+				case <-blockEvents:
+				case <-time.After(qtum.PushedPollInterval):
+				}
+			}
+			waitForBlock = true
+
+			blockCount, err := s.qtum.GetBlockCount(s.ctx)
+			if err != nil {
+				s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "err", err)
+				continue
+			}
+			if nextBlock == nil {
+				// like waitforlogs, start with the logs of the next block
+				nextBlock = int(blockCount.Int64() + 1)
+				continue
+			}
+			if int64(nextBlock.(int)) > blockCount.Int64() {
+				continue
+			}
+			req.ToBlock = int(blockCount.Int64())
+		} else {
+			waitForBlock = false
+			req.ToBlock = nil
+		}
+
+		replaced, reorg, err := sent.findReorg(s.ctx, s.qtum)
+		if err != nil {
+			s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "msg", "failed to check for replaced blocks", "err", err)
+		} else if reorg {
+			s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "msg", "chain reorganization detected", "block", replaced)
+			if !s.notifyLogs(sent.remove(replaced)) {
+				return
+			}
+			// fetch the logs of the new chain
+			nextBlock = int(replaced)
+		}
+
 		req.FromBlock = nextBlock
 		timeBeforeCall := time.Now()
 		rolling.Push(&timeBeforeCall)
-		resp, err := s.qtum.WaitForLogsWithContext(s.ctx, req)
+		resp, err := s.qtum.WaitForLogs(s.ctx, req)
 		timeAfterCall := time.Now()
 		if err == nil {
 			nextBlock = int(resp.NextBlock)
 			for _, qtumLog := range resp.Entries {
+				if sent.conflicts(qtumLog.BlockNumber, qtumLog.BlockHash) {
+					s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "msg", "chain reorganization detected", "block", qtumLog.BlockNumber)
+					if !s.notifyLogs(sent.remove(qtumLog.BlockNumber)) {
+						return
+					}
+				}
+
 				qtumLogs := []qtum.Log{qtumLog.Log()}
 				logs := conversion.FilterQtumLogs(stringAddresses, qtumTopics, qtumLogs)
 				ethLogs := conversion.ExtractETHLogsFromTransactionReceipt(qtumLog, logs)
+				newLogs := make([]eth.Log, 0, len(ethLogs))
 				for _, ethLog := range ethLogs {
-					subscription := &eth.EthSubscription{
-						SubscriptionID: s.Subscription.id,
-						Result:         ethLog,
-					}
-					hash := computeHash(subscription)
-					if _, ok := sentHashes[hash]; !ok {
-						sentHashes[hash] = true
-						s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "msg", "notifying of logs")
-						jsonRpcNotification, err := eth.NewJSONRPCNotification("eth_subscription", subscription)
-						if err != nil {
-							s.qtum.GetErrorLogger().Log("subscriptionId", s.id, "err", err)
-							return
-						}
-						s.Send(jsonRpcNotification)
+					if sent.add(qtumLog.BlockNumber, qtumLog.BlockHash, ethLog) {
+						newLogs = append(newLogs, ethLog)
 					}
 				}
+				if !s.notifyLogs(newLogs) {
+					return
+				}
 			}
 			oldest := rolling.Oldest()
 			a := time.Now()
@@ -185,6 +221,24 @@ func (s *subscriptionInformation) run() {
 	}
 }
 
+// notifyLogs sends each log as a notification, it returns false when the subscription can't continue
+func (s *subscriptionInformation) notifyLogs(logs []eth.Log) bool {
+	for _, ethLog := range logs {
+		subscription := &eth.EthSubscription{
+			SubscriptionID: s.Subscription.id,
+			Result:         ethLog,
+		}
+		s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "msg", "notifying of logs", "removed", ethLog.Removed)
+		jsonRpcNotification, err := eth.NewJSONRPCNotification("eth_subscription", subscription)
+		if err != nil {
+			s.qtum.GetErrorLogger().Log("subscriptionId", s.id, "err", err)
+			return false
+		}
+		s.Send(jsonRpcNotification)
+	}
+	return true
+}
+
 // Compute hash for the json serialization of the passed in argument
 func computeHash(value interface{}) string {
 	b, err := json.Marshal(value)
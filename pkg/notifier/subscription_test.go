@@ -1,8 +1,18 @@
 package notifier
 
 import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
 	"testing"
 	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
 )
 
 func TestRollingLimit(t *testing.T) {
@@ -51,3 +61,120 @@ func TestRollingLimit(t *testing.T) {
 		t.Fatalf("Expected newest slot to be 0, not %d", l.newest())
 	}
 }
+
+func TestSentLogs(t *testing.T) {
+	sent := newSentLogs(3)
+
+	first := eth.Log{LogIndex: "0x0"}
+	if !sent.add(10, "0xaa", first) {
+		t.Fatal("first log should be sent")
+	}
+	if sent.add(10, "aa", first) {
+		t.Fatal("duplicate log should not be sent")
+	}
+	if !sent.add(11, "bb", eth.Log{LogIndex: "0x1"}) {
+		t.Fatal("log of a new block should be sent")
+	}
+	if !sent.conflicts(11, "cc") || sent.conflicts(11, "0xBB") {
+		t.Fatal("conflicting block hash not detected")
+	}
+
+	removed := sent.remove(11)
+	if len(removed) != 1 || !removed[0].Removed || removed[0].LogIndex != "0x1" {
+		t.Fatalf("unexpected removed logs: %v", removed)
+	}
+	if !sent.add(11, "cc", eth.Log{LogIndex: "0x1"}) {
+		t.Fatal("log of the replacing block should be sent")
+	}
+
+	// blocks fall out of the window
+	sent.add(13, "dd", eth.Log{})
+	if _, found := sent.find(10); found {
+		t.Fatal("block 10 should have been pruned")
+	}
+	if sent.add(10, "aa", first) {
+		t.Fatal("logs older than the window should not be sent")
+	}
+}
+
+func TestAgentLogsSubscriptionReorg(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	entry := internal.QtumWaitForLogsEntry(qtum.Log{
+		Address: internal.QtumTransactionReceipt(nil).ContractAddress,
+		Topics:  []string{},
+		Data:    "00",
+	})
+	replacingEntry := entry
+	replacingEntry.BlockHash = "1d7a3d8dd2da1e1e2e6bb9e1c4e3e76eefb9e5b2cc6d6b0e0a0c1d3b2a4e7f51"
+
+	doer := internal.NewDoerMappedMock()
+	doer.AddResponse(qtum.MethodWaitForLogs, qtum.WaitForLogsResponse{Entries: []qtum.WaitForLogsEntry{entry}, Count: 1, NextBlock: entry.BlockNumber + 1})
+	doer.AddResponse(qtum.MethodWaitForLogs, qtum.WaitForLogsResponse{Entries: []qtum.WaitForLogsEntry{replacingEntry}, Count: 1, NextBlock: entry.BlockNumber + 1})
+	doer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(int64(entry.BlockNumber))})
+	doer.AddResponse(qtum.MethodGetBlockHash, qtum.GetBlockHashResponse(replacingEntry.BlockHash))
+
+	mockedClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent := NewAgent(ctx, mockedClient, nil)
+
+	notifierContext, cancelNotifierContext := context.WithCancel(ctx)
+	sentValuesChannel := make(chan []byte, 10)
+	send := func(v []byte) error {
+		sentValuesChannel <- v
+		return nil
+	}
+	notifier := NewNotifier(notifierContext, cancelNotifierContext, send, log.NewLogfmtLogger(os.Stdout))
+
+	id, err := agent.NewSubscription(notifier, &eth.EthSubscriptionRequest{
+		Method: "logs",
+		Params: &eth.EthLogSubscriptionParameter{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier.ResponseSent()
+
+	// the log is sent, removed once its block is replaced, then sent from the new block
+	wants := []struct {
+		blockHash string
+		removed   bool
+	}{
+		{utils.AddHexPrefix(entry.BlockHash), false},
+		{utils.AddHexPrefix(entry.BlockHash), true},
+		{utils.AddHexPrefix(replacingEntry.BlockHash), false},
+	}
+	for _, want := range wants {
+		select {
+		case gotBytes := <-sentValuesChannel:
+			var notification eth.JSONRPCNotification
+			if err := json.Unmarshal(gotBytes, &notification); err != nil {
+				t.Fatalf("Failed to unmarshal: %s: %s", string(gotBytes), err)
+			}
+			var got struct {
+				Result eth.Log `json:"result"`
+			}
+			if err := json.Unmarshal(notification.Params, &got); err != nil {
+				t.Fatalf("Failed to unmarshal: %s: %s", string(gotBytes), err)
+			}
+			if got.Result.BlockHash != want.blockHash || got.Result.Removed != want.removed {
+				t.Fatalf("unexpected log notification, want block %s removed %t\ngot: %s", want.blockHash, want.removed, string(gotBytes))
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for subscription")
+		}
+	}
+
+	select {
+	case gotBytes := <-sentValuesChannel:
+		t.Fatalf("duplicate log sent: %s", string(gotBytes))
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	if !notifier.Unsubscribe(id) {
+		t.Fatalf("Failed to unsubscribe to subscription %s", id)
+	}
+}
@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"time"
+
+	"github.com/qtumproject/janus/pkg/eth"
+)
+
+var agentConfigSyncingKey = "syncingInterval"
+var agentConfigSyncingInterval = 10 * time.Second
+
+// processSyncing notifies 'syncing' subscriptions when the node starts or stops syncing and of its progress in between,
+// last is the previously seen status, nil before the first poll
+func (a *Agent) processSyncing(last *eth.EthSubscriptionSyncingResponse) *eth.EthSubscriptionSyncingResponse {
+	blockchainInfo, err := a.qtum.GetBlockChainInfo(a.ctx)
+	if err != nil {
+		a.qtum.GetErrorLogger().Log("msg", "Failure getting blockchaininfo", "err", err)
+		return last
+	}
+
+	status := a.syncStatus.Update(&blockchainInfo)
+	current := &eth.EthSubscriptionSyncingResponse{
+		Syncing: status != nil,
+		Status:  status,
+	}
+
+	if last == nil {
+		// like newHeads, only changes are sent, clients get the current status from eth_syncing
+		a.qtum.GetDebugLogger().Log("msg", "Got initial syncing status", "syncing", current.Syncing)
+		return current
+	}
+	if current.Syncing == last.Syncing && (!current.Syncing || current.Status.CurrentBlock == last.Status.CurrentBlock) {
+		return last
+	}
+
+	a.qtum.GetDebugLogger().Log("msg", "Syncing status changed", "syncing", current.Syncing, "block", blockchainInfo.Blocks, "headers", blockchainInfo.Headers)
+	a.syncing.SendAll(current)
+	return current
+}
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestAgentAddSubscriptionSyncing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	doer := internal.NewDoerMappedMock()
+	doer.AddResponse(qtum.MethodGetBlockChainInfo, qtum.GetBlockChainInfoResponse{Blocks: 1000, Headers: 1500, InitialBlockDownload: true})
+	doer.AddResponse(qtum.MethodGetBlockChainInfo, qtum.GetBlockChainInfoResponse{Blocks: 1000, Headers: 1500, InitialBlockDownload: true})
+	doer.AddResponse(qtum.MethodGetBlockChainInfo, qtum.GetBlockChainInfoResponse{Blocks: 1200, Headers: 1500, InitialBlockDownload: true})
+	doer.AddResponse(qtum.MethodGetBlockChainInfo, qtum.GetBlockChainInfoResponse{Blocks: 1500, Headers: 1500})
+
+	mockedClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentTestConfig := make(map[string]interface{})
+	// adjust syncing interval to tick quicker for unit tests
+	agentTestConfig[agentConfigSyncingKey] = 100 * time.Millisecond
+
+	agent := newAgentWithConfiguration(ctx, mockedClient, nil, agentTestConfig)
+
+	notifierContext, cancelNotifierContext := context.WithCancel(ctx)
+
+	sentValuesChannel := make(chan []byte, 10)
+	send := func(v []byte) error {
+		sentValuesChannel <- v
+		return nil
+	}
+
+	notifier := NewNotifier(notifierContext, cancelNotifierContext, send, log.NewLogfmtLogger(os.Stdout))
+
+	id, err := agent.NewSubscription(notifier, &eth.EthSubscriptionRequest{
+		Method: "syncing",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notifier.ResponseSent()
+
+	// the unchanged second status isn't sent, progress and the end of the sync are
+	wants := []string{
+		`{"subscription":"` + id + `","result":{"syncing":true,"status":{"startingBlock":"0x3e8","currentBlock":"0x4b0","highestBlock":"0x5dc"}}}`,
+		`{"subscription":"` + id + `","result":{"syncing":false}}`,
+	}
+	for _, want := range wants {
+		select {
+		case gotBytes := <-sentValuesChannel:
+			var receivedEthSubscription eth.EthSubscription
+			if err := json.Unmarshal(gotBytes, &receivedEthSubscription); err != nil {
+				t.Fatalf("Failed to unmarshal: %s: %s", string(gotBytes), err)
+			}
+			if got := string(gotBytes); got != want {
+				t.Fatalf("syncing subscription error\nwant: %s\ngot: %s", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for subscription")
+		}
+	}
+
+	select {
+	case gotBytes := <-sentValuesChannel:
+		t.Fatalf("unexpected notification once synced: %s", string(gotBytes))
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	if !notifier.Unsubscribe(id) {
+		t.Fatalf("Failed to unsubscribe to subscription %s", id)
+	}
+}
@@ -0,0 +1,228 @@
+package qtum
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// backendSyncedTolerance is how many blocks a backend may lag behind the best one and still be considered synced,
+// backends often learn about a new block a moment apart
+const backendSyncedTolerance = 1
+
+// walletMethods use the keys of the wallet of the first backend, they are never routed to another node
+var walletMethods = map[string]bool{
+	MethodSendToContract:        true,
+	MethodCreateContract:        true,
+	MethodSendToAddress:         true,
+	MethodGetTransaction:        true,
+	MethodGetAddressesByAccount: true,
+	MethodListUnspent:           true,
+	MethodSignRawTx:             true,
+	MethodSignMessage:           true,
+	MethodGenerateToAddress:     true,
+}
+
+// backend is a qtumd node serving RPC requests
+type backend struct {
+	url string
+
+	mutex     sync.RWMutex
+	healthy   bool
+	blocks    int64
+	lastError error
+}
+
+func newBackend(rpcURL string) *backend {
+	return &backend{url: rpcURL, healthy: true}
+}
+
+func (b *backend) state() (bool, int64) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.healthy, b.blocks
+}
+
+// markFailed takes the backend out of rotation until a health check succeeds
+func (b *backend) markFailed(err error) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	wasHealthy := b.healthy
+	b.healthy = false
+	b.lastError = err
+	return wasHealthy
+}
+
+func (b *backend) markChecked(blocks int64) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	wasHealthy := b.healthy
+	b.healthy = true
+	b.blocks = blocks
+	b.lastError = nil
+	return !wasHealthy
+}
+
+// redactedURL hides the password of the backend in logs
+func (b *backend) redactedURL() string {
+	u, err := url.Parse(b.url)
+	if err != nil {
+		return ""
+	}
+	if u.User != nil {
+		u.User = url.User(u.User.Username())
+	}
+	return u.String()
+}
+
+// SetFailoverURLs adds qtumd nodes serving requests when the node of the client URL is unavailable or behind.
+// Wallet methods are only sent to the client URL, as only its node holds the keys
+func SetFailoverURLs(rpcURLs ...string) func(*Client) error {
+	return func(c *Client) error {
+		for _, rpcURL := range rpcURLs {
+			if err := checkRPCURL(rpcURL); err != nil {
+				return err
+			}
+			c.backends = append(c.backends, newBackend(rpcURL))
+		}
+		return nil
+	}
+}
+
+// backendsFor returns the backends to try in order for a method: the synced ones first in configured order,
+// then the ones falling behind and last the ones failing health checks
+func (c *Client) backendsFor(method string) []*backend {
+	if walletMethods[method] || len(c.backends) == 1 {
+		return c.backends[:1]
+	}
+
+	var best int64
+	for _, b := range c.backends {
+		if healthy, blocks := b.state(); healthy && blocks > best {
+			best = blocks
+		}
+	}
+
+	var synced, lagging, unhealthy []*backend
+	for _, b := range c.backends {
+		healthy, blocks := b.state()
+		switch {
+		case !healthy:
+			unhealthy = append(unhealthy, b)
+		case blocks+backendSyncedTolerance >= best:
+			synced = append(synced, b)
+		default:
+			lagging = append(lagging, b)
+		}
+	}
+	return append(append(synced, lagging...), unhealthy...)
+}
+
+// doWithFailover sends a request to the backends of its method until one answers,
+// nodes that are unreachable or busy are skipped
+func (c *Client) doWithFailover(ctx context.Context, method string, body []byte) ([]byte, error) {
+	var (
+		respBody []byte
+		err      error
+		busy     bool
+	)
+	for _, b := range c.backendsFor(method) {
+		reqCtx, cancel := c.requestContext(ctx, method)
+		respBody, err = c.do(reqCtx, b.url, body)
+		cancel()
+		if err != nil {
+			if ctx != nil && ctx.Err() != nil {
+				// the request was cancelled, the backend is fine
+				return nil, err
+			}
+			if b.markFailed(err) && len(c.backends) > 1 {
+				c.GetErrorLogger().Log("msg", "qtumd backend failed, failing over", "backend", b.redactedURL(), "method", method, "err", err)
+			}
+			continue
+		}
+		if string(respBody) == ErrQtumWorkQueueDepth.Error() {
+			busy = true
+			continue
+		}
+		return respBody, nil
+	}
+
+	if busy {
+		// let the caller back off and retry
+		return []byte(ErrQtumWorkQueueDepth.Error()), nil
+	}
+	return nil, &backendError{err}
+}
+
+// CheckBackends queries getblockchaininfo on every backend, so that requests are routed to nodes that are up and synced
+func (c *Client) CheckBackends(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, b := range c.backends {
+		wg.Add(1)
+		go func(b *backend) {
+			defer wg.Done()
+			c.checkBackend(ctx, b)
+		}(b)
+	}
+	wg.Wait()
+}
+
+func (c *Client) checkBackend(ctx context.Context, b *backend) {
+	req, err := c.NewRPCRequest(MethodGetBlockChainInfo, nil)
+	if err != nil {
+		return
+	}
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+
+	info, err := func() (*GetBlockChainInfoResponse, error) {
+		reqCtx, cancel := c.requestContext(ctx, MethodGetBlockChainInfo)
+		defer cancel()
+		respBody, err := c.do(reqCtx, b.url, reqBody)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.responseBodyToResult(respBody)
+		if err != nil {
+			return nil, err
+		}
+		var info GetBlockChainInfoResponse
+		if err := json.Unmarshal(res.RawResult, &info); err != nil {
+			return nil, err
+		}
+		if info.InitialBlockDownload {
+			return nil, errors.New("node is in initial block download")
+		}
+		return &info, nil
+	}()
+
+	if err != nil {
+		if b.markFailed(err) {
+			c.GetErrorLogger().Log("msg", "qtumd backend failed health check", "backend", b.redactedURL(), "err", err)
+		}
+		return
+	}
+	if b.markChecked(info.Blocks) {
+		c.GetLogger().Log("msg", "qtumd backend is healthy again", "backend", b.redactedURL(), "blocks", info.Blocks)
+	}
+}
+
+// RunHealthChecks checks the backends every interval until ctx is done
+func (c *Client) RunHealthChecks(ctx context.Context, interval time.Duration) {
+	for {
+		c.CheckBackends(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package qtum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/metrics"
+)
+
+// maxBatchSize limits the requests sent in a single JSON-RPC batch, larger batches are split
+var maxBatchSize = 200
+
+var errMissingBatchResponse = errors.New("no response for the request in the batch")
+
+// BatchRequest is a request sent along others in a single JSON-RPC batch
+type BatchRequest struct {
+	Method string
+	Params interface{}
+	// Result is unmarshalled from the response when set
+	Result interface{}
+
+	// RawResult and Err are set once the batch is answered
+	RawResult json.RawMessage
+	Err       error
+}
+
+func NewBatchRequest(method string, params interface{}, result interface{}) *BatchRequest {
+	return &BatchRequest{
+		Method: method,
+		Params: params,
+		Result: result,
+	}
+}
+
+// RequestBatch sends the requests to qtumd in JSON-RPC batches and sets the response of each request, matched by id.
+// The error returned is about the batch as a whole, errors of single requests are set in their Err
+func (c *Client) RequestBatch(ctx context.Context, requests []*BatchRequest) error {
+	for start := 0; start < len(requests); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+		if err := c.requestBatch(ctx, requests[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) requestBatch(ctx context.Context, requests []*BatchRequest) error {
+	rpcReqs := make([]*JSONRPCRequest, 0, len(requests))
+	byID := make(map[string]*BatchRequest, len(requests))
+	for _, request := range requests {
+		rpcReq, err := c.NewRPCRequest(request.Method, request.Params)
+		if err != nil {
+			return errors.WithMessage(err, "couldn't make new rpc request")
+		}
+		rpcReqs = append(rpcReqs, rpcReq)
+		byID[string(rpcReq.ID)] = request
+	}
+
+	var (
+		results []*JSONRPCResult
+		err     error
+	)
+	max := c.backoff.tries()
+	for i := 0; i < max; i++ {
+		err = c.send(ctx, batchRoutingMethod(rpcReqs), func() error {
+			var err error
+			start := time.Now()
+			results, err = c.doBatch(ctx, rpcReqs)
+			observeRequest(ctx, "batch", start, err)
+			return err
+		})
+		if err != ErrQtumWorkQueueDepth || i == max-1 {
+			break
+		}
+		backoffTime := computeBackoff(i, c.backoff.MaxBackoff, true)
+		metrics.QtumBackoff()
+		c.GetLogger().Log("msg", fmt.Sprintf("QTUM process busy, backing off batch for %f seconds", backoffTime.Seconds()), "requests", len(rpcReqs))
+		if err := waitBackoff(ctx, backoffTime); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		request, ok := byID[string(result.ID)]
+		if !ok {
+			c.GetDebugLogger().Log("msg", "Unexpected response id in batch", "id", string(result.ID))
+			continue
+		}
+		delete(byID, string(result.ID))
+
+		if result.Error != nil {
+			request.Err = result.Error.TryGetKnownError()
+			continue
+		}
+		request.RawResult = result.RawResult
+		if request.Result != nil {
+			if err := json.Unmarshal(result.RawResult, request.Result); err != nil {
+				request.Err = errors.Wrap(err, "couldn't unmarshal response result field")
+			}
+		}
+	}
+	for _, request := range byID {
+		request.Err = errMissingBatchResponse
+	}
+	return nil
+}
+
+func (c *Client) doBatch(ctx context.Context, rpcReqs []*JSONRPCRequest) ([]*JSONRPCResult, error) {
+	reqBody, err := json.Marshal(rpcReqs)
+	if err != nil {
+		return nil, err
+	}
+
+	debugLogger := c.GetDebugLogger()
+	debugLogger.Log("method", "batch", "requests", len(rpcReqs))
+
+	if c.IsDebugEnabled() && !c.GetFlagBool(FLAG_HIDE_QTUMD_LOGS) {
+		fmt.Fprintf(c.logWriter, "=> qtum RPC batch request\n%s\n", reqBody)
+	}
+
+	respBody, err := c.doWithFailover(ctx, batchRoutingMethod(rpcReqs), reqBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "Client#do")
+	}
+	if string(respBody) == ErrQtumWorkQueueDepth.Error() {
+		return nil, ErrQtumWorkQueueDepth
+	}
+
+	var results []*JSONRPCResult
+	if err := json.Unmarshal(respBody, &results); err != nil {
+		// qtumd answers a single error when the batch as a whole is rejected
+		if _, err := c.responseBodyToResult(respBody); err != nil {
+			return nil, err
+		}
+		debugLogger.Log("msg", "Failed to parse batch response body", "body", string(respBody), "error", err)
+		return nil, errors.Wrap(err, "couldn't unmarshal batch response")
+	}
+	return results, nil
+}
+
+// batchRoutingMethod is the method deciding the backends of a batch, batches with wallet methods stay on the wallet backend
+func batchRoutingMethod(rpcReqs []*JSONRPCRequest) string {
+	for _, rpcReq := range rpcReqs {
+		if walletMethods[rpcReq.Method] {
+			return rpcReq.Method
+		}
+	}
+	return rpcReqs[0].Method
+}
+
+// prefetchedResponse is a response of a batch answering a later request
+type prefetchedResponse struct {
+	rawResult json.RawMessage
+	err       error
+}
+
+// prefetchedResponses are never modified once built, views sharing them don't need locking
+type prefetchedResponses map[string]prefetchedResponse
+
+func prefetchKey(method string, params interface{}) (string, error) {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	return method + string(paramsJSON), nil
+}
+
+// answer unmarshals the prefetched response of a request, if any
+func (responses prefetchedResponses) answer(method string, params interface{}, result interface{}) (bool, error) {
+	if len(responses) == 0 {
+		return false, nil
+	}
+	key, err := prefetchKey(method, params)
+	if err != nil {
+		return false, nil
+	}
+	response, ok := responses[key]
+	if !ok {
+		return false, nil
+	}
+	if response.err != nil {
+		return true, response.err
+	}
+	if err := json.Unmarshal(response.rawResult, result); err != nil {
+		return true, errors.Wrap(err, "couldn't unmarshal response result field")
+	}
+	return true, nil
+}
+
+// Prefetch sends the requests in a batch and returns a view of the client answering them from the responses,
+// so that code requesting them one at a time doesn't wait on qtumd for each. Other requests still go to qtumd.
+// Only successful responses and known errors are kept, others are requested again when needed.
+// The view is meant to be short lived as the responses aren't refreshed
+func (q *Qtum) Prefetch(ctx context.Context, requests []*BatchRequest) (*Qtum, error) {
+	responses := make(prefetchedResponses, len(q.Method.prefetched)+len(requests))
+	for key, response := range q.Method.prefetched {
+		responses[key] = response
+	}
+
+	// requests already answered or repeated are sent once
+	keys := make([]string, len(requests))
+	var unique []*BatchRequest
+	sent := map[string]bool{}
+	for i, request := range requests {
+		key, err := prefetchKey(request.Method, request.Params)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+		if _, ok := responses[key]; ok || sent[key] {
+			continue
+		}
+		sent[key] = true
+		unique = append(unique, &BatchRequest{Method: request.Method, Params: request.Params})
+	}
+
+	answered := make(map[string]prefetchedResponse, len(unique))
+	if len(unique) != 0 {
+		if err := q.RequestBatch(ctx, unique); err != nil {
+			return nil, err
+		}
+		for _, request := range unique {
+			key, _ := prefetchKey(request.Method, request.Params)
+			response := prefetchedResponse{rawResult: request.RawResult, err: request.Err}
+			answered[key] = response
+			if request.Err == nil || IsKnownError(request.Err) {
+				responses[key] = response
+			}
+		}
+	}
+
+	for i, request := range requests {
+		response, ok := answered[keys[i]]
+		if !ok {
+			response = responses[keys[i]]
+		}
+		request.RawResult, request.Err = response.rawResult, response.err
+		if request.Err == nil && request.Result != nil {
+			if err := json.Unmarshal(request.RawResult, request.Result); err != nil {
+				request.Err = errors.Wrap(err, "couldn't unmarshal response result field")
+			}
+		}
+	}
+
+	return &Qtum{
+		Client: q.Client,
+		Method: &Method{Client: q.Client, prefetched: responses},
+		chain:  q.chain,
+	}, nil
+}
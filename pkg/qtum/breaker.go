@@ -0,0 +1,127 @@
+package qtum
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// BreakerConfig opens the circuit breaker after Failures consecutive requests couldn't reach qtumd,
+// requests then fail fast with ErrCircuitOpen for OpenTimeout before a single one probes qtumd again.
+// 0 Failures disables the breaker
+type BreakerConfig struct {
+	Failures    int
+	OpenTimeout time.Duration
+}
+
+var DefaultBreakerConfig = BreakerConfig{
+	Failures:    5,
+	OpenTimeout: 5 * time.Second,
+}
+
+func (config BreakerConfig) validate() error {
+	if config.Failures < 0 || config.OpenTimeout < 0 {
+		return errors.New("circuit breaker settings must not be negative")
+	}
+	return nil
+}
+
+type circuitBreaker struct {
+	mutex  sync.Mutex
+	config BreakerConfig
+	now    func() time.Time
+
+	current  string
+	failures int
+	openedAt time.Time
+	// a request is probing qtumd while half-open
+	probing bool
+}
+
+func newCircuitBreaker(config BreakerConfig) *circuitBreaker {
+	return &circuitBreaker{
+		config:  config,
+		now:     time.Now,
+		current: BreakerClosed,
+	}
+}
+
+// allow fails fast while the breaker is open, once the timeout elapsed a single request is let through
+func (b *circuitBreaker) allow() error {
+	if b.config.Failures == 0 {
+		return nil
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.current {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.config.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		b.current = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	}
+	return nil
+}
+
+// done records the outcome of an allowed request, returns whether the breaker opened
+func (b *circuitBreaker) done(outcome requestOutcome) bool {
+	if b.config.Failures == 0 {
+		return false
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.current == BreakerHalfOpen {
+		b.probing = false
+	}
+
+	switch outcome {
+	case outcomeAnswered, outcomeOverloaded:
+		b.current = BreakerClosed
+		b.failures = 0
+	case outcomeFailed:
+		b.failures++
+		if b.current == BreakerHalfOpen || (b.current == BreakerClosed && b.failures >= b.config.Failures) {
+			opened := b.current == BreakerClosed
+			b.current = BreakerOpen
+			b.openedAt = b.now()
+			return opened
+		}
+	}
+	return false
+}
+
+func (b *circuitBreaker) state() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.current
+}
+
+// SetCircuitBreaker fails requests fast while qtumd can't be reached
+func SetCircuitBreaker(config BreakerConfig) func(*Client) error {
+	return func(c *Client) error {
+		if err := config.validate(); err != nil {
+			return err
+		}
+		c.breaker = newCircuitBreaker(config)
+		return nil
+	}
+}
@@ -14,11 +14,13 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/metrics"
 )
 
 var FLAG_GENERATE_ADDRESS_TO = "REGTEST_GENERATE_ADDRESS_TO"
@@ -26,15 +28,54 @@ var FLAG_IGNORE_UNKNOWN_TX = "IGNORE_UNKNOWN_TX"
 var FLAG_DISABLE_SNIPPING_LOGS = "DISABLE_SNIPPING_LOGS"
 var FLAG_HIDE_QTUMD_LOGS = "HIDE_QTUMD_LOGS"
 
-var maximumRequestTime = 10000
-var maximumBackoff = (2 * time.Second).Milliseconds()
+// set once qtumd is found to run without -logevents, so that receipts and logs aren't requested again
+var FLAG_LOG_EVENTS_DISABLED = "LOG_EVENTS_DISABLED"
+
+// BackoffConfig bounds the retries of the requests answered while the work queue of qtumd is full
+type BackoffConfig struct {
+	// MaxRetryTime over MaxBackoff is the number of times a request is tried
+	MaxRetryTime time.Duration
+	// MaxBackoff caps the exponential backoff between tries
+	MaxBackoff time.Duration
+}
+
+var DefaultBackoffConfig = BackoffConfig{
+	MaxRetryTime: 10 * time.Second,
+	MaxBackoff:   2 * time.Second,
+}
+
+func (config BackoffConfig) validate() error {
+	if config.MaxBackoff <= 0 || config.MaxRetryTime < 0 {
+		return errors.New("maximum backoff must be positive and maximum retry time must not be negative")
+	}
+	return nil
+}
+
+// tries is the number of times a request is sent while qtumd is busy
+func (config BackoffConfig) tries() int {
+	return int(math.Max(float64(config.MaxRetryTime/config.MaxBackoff), 1))
+}
 
 type Client struct {
 	URL  string
 	doer doer
 
-	// hex addresses to return for eth_accounts
-	Accounts Accounts
+	// connections to qtumd and the time a request to a backend may take
+	pool           *poolStats
+	requestTimeout time.Duration
+
+	// qtumd nodes serving requests, the first one is URL
+	backends []*backend
+
+	// bound the requests in flight and fail fast while qtumd is down
+	limiter *concurrencyLimiter
+	breaker *circuitBreaker
+
+	// hex addresses to return for eth_accounts, they can be replaced while janus runs
+	accounts      Accounts
+	accountsMutex sync.RWMutex
+
+	backoff BackoffConfig
 
 	logWriter io.Writer
 	logger    log.Logger
@@ -43,6 +84,12 @@ type Client struct {
 	// is this client using the main network?
 	isMain bool
 
+	// picks the UTXOs funding locally signed transactions
+	coinSelector CoinSelector
+
+	// block and transaction events pushed by qtumd
+	events *EventBus
+
 	id      *big.Int
 	idStep  *big.Int
 	idMutex sync.Mutex
@@ -66,16 +113,25 @@ func NewClient(isMain bool, rpcURL string, opts ...func(*Client) error) (*Client
 		return nil, err
 	}
 
+	pool := &poolStats{}
 	c := &Client{
-		isMain: isMain,
-		doer:   http.DefaultClient,
-		URL:    rpcURL,
-		logger: log.NewNopLogger(),
-		debug:  false,
-		id:     big.NewInt(0),
-		idStep: big.NewInt(1),
-		mutex:  &sync.RWMutex{},
-		flags:  make(map[string]interface{}),
+		isMain:         isMain,
+		doer:           &http.Client{Transport: newTransport(DefaultTransportConfig, pool)},
+		pool:           pool,
+		requestTimeout: DefaultTransportConfig.RequestTimeout,
+		URL:            rpcURL,
+		backends:       []*backend{newBackend(rpcURL)},
+		limiter:        newConcurrencyLimiter(DefaultLimiterConfig),
+		breaker:        newCircuitBreaker(DefaultBreakerConfig),
+		backoff:        DefaultBackoffConfig,
+		logger:         log.NewNopLogger(),
+		debug:          false,
+		coinSelector:   &LargestFirstSelector{},
+		events:         NewEventBus(),
+		id:             big.NewInt(0),
+		idStep:         big.NewInt(1),
+		mutex:          &sync.RWMutex{},
+		flags:          make(map[string]interface{}),
 	}
 
 	for _, opt := range opts {
@@ -87,6 +143,11 @@ func NewClient(isMain bool, rpcURL string, opts ...func(*Client) error) (*Client
 	return c, nil
 }
 
+// Events returns the bus of the block and transaction events pushed by qtumd, when none are pushed components poll qtumd
+func (c *Client) Events() *EventBus {
+	return c.events
+}
+
 func (c *Client) IsMain() bool {
 	return c.isMain
 }
@@ -102,15 +163,24 @@ func (c *Client) RequestWithContext(ctx context.Context, method string, params i
 	}
 
 	var resp *SuccessJSONRPCResult
-	max := int(math.Floor(math.Max(float64(maximumRequestTime/int(maximumBackoff)), 1)))
+	max := c.backoff.tries()
 	for i := 0; i < max; i++ {
-		resp, err = c.Do(ctx, req)
+		err = c.send(ctx, method, func() error {
+			var err error
+			start := time.Now()
+			resp, err = c.Do(ctx, req)
+			observeRequest(ctx, method, start, err)
+			return err
+		})
 		if err != nil {
 			if strings.Contains(err.Error(), ErrQtumWorkQueueDepth.Error()) && i != max-1 {
 				requestString := marshalToString(req)
-				backoffTime := computeBackoff(i, true)
+				backoffTime := computeBackoff(i, c.backoff.MaxBackoff, true)
+				metrics.QtumBackoff()
 				c.GetLogger().Log("msg", fmt.Sprintf("QTUM process busy, backing off for %f seconds", backoffTime.Seconds()), "request", requestString)
-				time.Sleep(backoffTime)
+				if err := waitBackoff(ctx, backoffTime); err != nil {
+					return err
+				}
 				c.GetLogger().Log("msg", "Retrying QTUM command")
 			} else {
 				if i != 0 {
@@ -145,7 +215,7 @@ func (c *Client) Do(ctx context.Context, req *JSONRPCRequest) (*SuccessJSONRPCRe
 		fmt.Fprintf(c.logWriter, "=> qtum RPC request\n%s\n", reqBody)
 	}
 
-	respBody, err := c.do(ctx, bytes.NewReader(reqBody))
+	respBody, err := c.doWithFailover(ctx, req.Method, reqBody)
 	if err != nil {
 		return nil, errors.Wrap(err, "Client#do")
 	}
@@ -203,19 +273,18 @@ func (c *Client) NewRPCRequest(method string, params interface{}) (*JSONRPCReque
 	}, nil
 }
 
-func (c *Client) do(ctx context.Context, body io.Reader) ([]byte, error) {
-	var req *http.Request
-	var err error
-	if ctx != nil {
-		req, err = http.NewRequestWithContext(ctx, http.MethodPost, c.URL, body)
-	} else {
-		req, err = http.NewRequest(http.MethodPost, c.URL, body)
+func (c *Client) do(ctx context.Context, rpcURL string, body []byte) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
 	}
+	req, err := http.NewRequestWithContext(c.pool.trace(ctx), http.MethodPost, rpcURL, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Close = true
+	atomic.AddUint64(&c.pool.requests, 1)
+	atomic.AddInt64(&c.pool.active, 1)
+	defer atomic.AddInt64(&c.pool.active, -1)
 
 	resp, err := c.doer.Do(req)
 	if err != nil {
@@ -310,7 +379,39 @@ func SetLogger(l log.Logger) func(*Client) error {
 
 func SetAccounts(accounts Accounts) func(*Client) error {
 	return func(c *Client) error {
-		c.Accounts = accounts
+		c.ReloadAccounts(accounts)
+		return nil
+	}
+}
+
+// SetBackoff configures the retries of the requests qtumd is too busy to handle
+func SetBackoff(config BackoffConfig) func(*Client) error {
+	return func(c *Client) error {
+		if err := config.validate(); err != nil {
+			return err
+		}
+		c.backoff = config
+		return nil
+	}
+}
+
+// GetAccounts returns the accounts loaded in janus
+func (c *Client) GetAccounts() Accounts {
+	c.accountsMutex.RLock()
+	defer c.accountsMutex.RUnlock()
+	return c.accounts
+}
+
+// ReloadAccounts replaces the accounts loaded in janus
+func (c *Client) ReloadAccounts(accounts Accounts) {
+	c.accountsMutex.Lock()
+	c.accounts = accounts
+	c.accountsMutex.Unlock()
+}
+
+func SetCoinSelector(selector CoinSelector) func(*Client) error {
+	return func(c *Client) error {
+		c.coinSelector = selector
 		return nil
 	}
 }
@@ -345,6 +446,10 @@ func SetHideQtumdLogs(hide bool) func(*Client) error {
 	}
 }
 
+func (c *Client) GetCoinSelector() CoinSelector {
+	return c.coinSelector
+}
+
 func (c *Client) GetLogWriter() io.Writer {
 	return c.logWriter
 }
@@ -388,7 +493,7 @@ func (c *Client) responseBodyToResult(body []byte) (*SuccessJSONRPCResult, error
 	}, nil
 }
 
-func computeBackoff(i int, random bool) time.Duration {
+func computeBackoff(i int, maxBackoff time.Duration, random bool) time.Duration {
 	i = int(math.Min(float64(i), 10))
 	randomNumberMilliseconds := 0
 	if random {
@@ -396,10 +501,24 @@ func computeBackoff(i int, random bool) time.Duration {
 	}
 	exponentialBase := math.Pow(2, float64(i)) * 0.25
 	exponentialBaseInSeconds := time.Duration(exponentialBase*float64(time.Second)) + time.Duration(randomNumberMilliseconds)*time.Millisecond
-	backoffTimeInMilliseconds := math.Min(float64(exponentialBaseInSeconds.Milliseconds()), float64(maximumBackoff))
+	backoffTimeInMilliseconds := math.Min(float64(exponentialBaseInSeconds.Milliseconds()), float64(maxBackoff.Milliseconds()))
 	return time.Duration(backoffTimeInMilliseconds * float64(time.Millisecond))
 }
 
+// waitBackoff sleeps for the backoff time, unless the request is canceled first
+func waitBackoff(ctx context.Context, backoff time.Duration) error {
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	select {
+	case <-time.After(backoff):
+		return nil
+	case <-done:
+		return ctx.Err()
+	}
+}
+
 func checkRPCURL(u string) error {
 	if u == "" {
 		return errors.New("URL must be set")
@@ -1,28 +1,29 @@
 package qtum
 
 import (
+	"context"
 	"testing"
 	"time"
 )
 
 func TestComputeBackoff(t *testing.T) {
-	backoffOne := computeBackoff(0, false)
+	backoffOne := computeBackoff(0, DefaultBackoffConfig.MaxBackoff, false)
 	if backoffOne != 250*time.Millisecond {
 		t.Errorf("Unexpected backoff time %d != %d", backoffOne.Milliseconds(), (250 * time.Millisecond).Milliseconds())
 	}
-	backoffTwo := computeBackoff(1, false)
+	backoffTwo := computeBackoff(1, DefaultBackoffConfig.MaxBackoff, false)
 	if backoffTwo != 500*time.Millisecond {
 		t.Errorf("Unexpected backoff time %d != %d", backoffTwo.Milliseconds(), (500 * time.Millisecond).Milliseconds())
 	}
-	backoffThree := computeBackoff(2, false)
+	backoffThree := computeBackoff(2, DefaultBackoffConfig.MaxBackoff, false)
 	if backoffThree != 1000*time.Millisecond {
 		t.Errorf("Unexpected backoff time %d != %d", backoffThree.Milliseconds(), (1000 * time.Millisecond).Milliseconds())
 	}
-	maxBackoff := computeBackoff(10, false)
+	maxBackoff := computeBackoff(10, DefaultBackoffConfig.MaxBackoff, false)
 	if maxBackoff != 2000*time.Millisecond {
 		t.Errorf("Unexpected backoff time %d != %d", maxBackoff.Milliseconds(), (2000 * time.Millisecond).Milliseconds())
 	}
-	overflow := computeBackoff(1000000, false)
+	overflow := computeBackoff(1000000, DefaultBackoffConfig.MaxBackoff, false)
 	if overflow != 2000*time.Millisecond {
 		t.Errorf("Unexpected backoff time %d != %d", overflow.Milliseconds(), (2000 * time.Millisecond).Milliseconds())
 	}
@@ -31,7 +32,7 @@ func TestComputeBackoff(t *testing.T) {
 func TestComputeBackoffWithRandom(t *testing.T) {
 	randomRange := time.Duration(250)
 	for i := 0; i < 10000; i++ {
-		backoff := computeBackoff(0, true)
+		backoff := computeBackoff(0, DefaultBackoffConfig.MaxBackoff, true)
 		min := (250 - randomRange) * time.Millisecond
 		max := (250 + randomRange) * time.Millisecond
 		if backoff < min || backoff > max {
@@ -39,8 +40,24 @@ func TestComputeBackoffWithRandom(t *testing.T) {
 		}
 	}
 
-	overflow := computeBackoff(1000000, true)
+	overflow := computeBackoff(1000000, DefaultBackoffConfig.MaxBackoff, true)
 	if overflow != 2000*time.Millisecond {
 		t.Fatalf("Unexpected backoff time %d != %d", overflow.Milliseconds(), (2000 * time.Millisecond).Milliseconds())
 	}
 }
+
+func TestWaitBackoff(t *testing.T) {
+	if err := waitBackoff(nil, time.Millisecond); err != nil {
+		t.Fatalf("unexpected error without a context: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := waitBackoff(ctx, time.Minute); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("expected a canceled request to stop backing off")
+	}
+}
@@ -0,0 +1,243 @@
+package qtum
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// CoinbaseMaturity is the number of confirmations before coinbase and coinstake outputs can be spent
+	CoinbaseMaturity = 500
+
+	// DefaultFeeRate is qtumd's default minimum relay fee, in satoshis per kB
+	DefaultFeeRate = 400000
+)
+
+const (
+	CoinSelectionLargestFirst    = "largest-first"
+	CoinSelectionBranchAndBound  = "branch-and-bound"
+	CoinSelectionConsolidateDust = "consolidate-dust"
+)
+
+var AllCoinSelections = []string{CoinSelectionLargestFirst, CoinSelectionBranchAndBound, CoinSelectionConsolidateDust}
+
+var ErrInsufficientUTXOs = errors.New("Insufficient UTXO value attempted to be sent")
+
+// CoinSelector picks the UTXOs funding a transaction.
+//
+// All amounts are in satoshis, feePerInput is the fee paid for the bytes of every spent input,
+// so the selected UTXOs cover target plus feePerInput for each of them
+type CoinSelector interface {
+	Select(utxos []UTXO, target decimal.Decimal, feePerInput decimal.Decimal) ([]UTXO, error)
+}
+
+func NewCoinSelector(name string) (CoinSelector, error) {
+	switch name {
+	case CoinSelectionLargestFirst:
+		return &LargestFirstSelector{}, nil
+	case CoinSelectionBranchAndBound:
+		return &BranchAndBoundSelector{Fallback: &LargestFirstSelector{}}, nil
+	case CoinSelectionConsolidateDust:
+		return &ConsolidateDustSelector{MaxInputs: DefaultConsolidateMaxInputs, Fallback: &LargestFirstSelector{}}, nil
+	default:
+		return nil, fmt.Errorf("unknown coin selection strategy: %s", name)
+	}
+}
+
+// FeePerInput returns the fee of spending a P2PKH input at feeRate satoshis per kB
+func FeePerInput(feeRate decimal.Decimal, compressedPubKey bool) decimal.Decimal {
+	return EstimateFee(InputSize(compressedPubKey), feeRate)
+}
+
+// FilterSpendableUTXOs drops immature coinbase/coinstake outputs at the current block height
+// and outputs already spent by transactions in the mempool
+func FilterSpendableUTXOs(utxos []UTXO, height int64, mempool []MempoolAddressDelta) []UTXO {
+	locked := make(map[string]bool)
+	for _, delta := range mempool {
+		if delta.PrevTxID != "" {
+			locked[fmt.Sprintf("%s:%d", delta.PrevTxID, delta.PrevOut)] = true
+		}
+	}
+
+	spendable := make([]UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		if utxo.IsStake && utxo.Height != nil && height-utxo.Height.Int64()+1 < CoinbaseMaturity {
+			continue
+		}
+		if locked[fmt.Sprintf("%s:%d", utxo.TXID, utxo.OutputIndex)] {
+			continue
+		}
+		spendable = append(spendable, utxo)
+	}
+	return spendable
+}
+
+// LargestFirstSelector spends the biggest UTXOs first, minimizing the number of inputs
+type LargestFirstSelector struct{}
+
+func (s *LargestFirstSelector) Select(utxos []UTXO, target decimal.Decimal, feePerInput decimal.Decimal) ([]UTXO, error) {
+	sorted := sortedUTXOs(utxos, func(a, b UTXO) bool {
+		return a.Satoshis.GreaterThan(b.Satoshis)
+	})
+	return accumulate(sorted, target, feePerInput, 0)
+}
+
+// DefaultConsolidateMaxInputs bounds the size of the transactions built by ConsolidateDustSelector
+const DefaultConsolidateMaxInputs = 50
+
+// ConsolidateDustSelector spends the smallest UTXOs first to merge dust into the change output.
+// Outputs worth less than the fee of spending them are skipped, and when covering the target
+// would require more than MaxInputs inputs the Fallback selector is used instead
+type ConsolidateDustSelector struct {
+	MaxInputs int
+	Fallback  CoinSelector
+}
+
+func (s *ConsolidateDustSelector) Select(utxos []UTXO, target decimal.Decimal, feePerInput decimal.Decimal) ([]UTXO, error) {
+	sorted := sortedUTXOs(utxos, func(a, b UTXO) bool {
+		return a.Satoshis.LessThan(b.Satoshis)
+	})
+
+	economical := sorted[:0]
+	for _, utxo := range sorted {
+		if utxo.Satoshis.GreaterThan(feePerInput) {
+			economical = append(economical, utxo)
+		}
+	}
+
+	selected, err := accumulate(economical, target, feePerInput, s.MaxInputs)
+	if err != nil && s.Fallback != nil {
+		return s.Fallback.Select(utxos, target, feePerInput)
+	}
+	return selected, err
+}
+
+// BranchAndBoundSelector searches for a set of UTXOs matching the target closely enough
+// to avoid creating a change output, see https://murch.one/wp-content/uploads/2016/11/erhardt2016coinselection.pdf.
+// When no such set is found within MaxTries steps, the Fallback selector is used
+type BranchAndBoundSelector struct {
+	MaxTries int
+	Fallback CoinSelector
+}
+
+const defaultBranchAndBoundTries = 100000
+
+func (s *BranchAndBoundSelector) Select(utxos []UTXO, target decimal.Decimal, feePerInput decimal.Decimal) ([]UTXO, error) {
+	maxTries := s.MaxTries
+	if maxTries <= 0 {
+		maxTries = defaultBranchAndBoundTries
+	}
+
+	// work with effective values, that is the value of an output minus the fee of spending it
+	sorted := sortedUTXOs(utxos, func(a, b UTXO) bool {
+		return a.Satoshis.GreaterThan(b.Satoshis)
+	})
+	fee := feePerInput.IntPart()
+	var (
+		candidates []UTXO
+		values     []int64
+		available  int64
+	)
+	for _, utxo := range sorted {
+		if value := utxo.Satoshis.IntPart() - fee; value > 0 {
+			candidates = append(candidates, utxo)
+			values = append(values, value)
+			available += value
+		}
+	}
+
+	var (
+		targetValue = target.IntPart()
+		// an exact match may exceed the target by the cost of a change output we avoid creating
+		upperBound = targetValue + fee
+		selected   = make([]bool, len(values))
+		best       []bool
+		bestExcess int64 = -1
+		tries      int
+	)
+
+	var search func(depth int, current, remaining int64)
+	search = func(depth int, current, remaining int64) {
+		tries++
+		if tries > maxTries || current > upperBound || current+remaining < targetValue {
+			return
+		}
+		if current >= targetValue {
+			if excess := current - targetValue; bestExcess < 0 || excess < bestExcess {
+				bestExcess = excess
+				best = append(best[:0], selected...)
+			}
+			return
+		}
+		if depth == len(values) {
+			return
+		}
+
+		remaining -= values[depth]
+		selected[depth] = true
+		search(depth+1, current+values[depth], remaining)
+		selected[depth] = false
+		if bestExcess == 0 {
+			return
+		}
+		search(depth+1, current, remaining)
+	}
+	search(0, 0, available)
+
+	if best == nil {
+		if s.Fallback != nil {
+			return s.Fallback.Select(utxos, target, feePerInput)
+		}
+		return nil, ErrInsufficientUTXOs
+	}
+
+	var result []UTXO
+	for i, ok := range best {
+		if ok {
+			result = append(result, candidates[i])
+		}
+	}
+	return result, nil
+}
+
+func sortedUTXOs(utxos []UTXO, less func(a, b UTXO) bool) []UTXO {
+	sorted := make([]UTXO, len(utxos))
+	copy(sorted, utxos)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// accumulate takes UTXOs in order until they cover the target and the fee of every input,
+// maxInputs of 0 means unbounded
+func accumulate(utxos []UTXO, target decimal.Decimal, feePerInput decimal.Decimal, maxInputs int) ([]UTXO, error) {
+	var (
+		selected []UTXO
+		sum      decimal.Decimal
+	)
+	for _, utxo := range utxos {
+		if maxInputs > 0 && len(selected) == maxInputs {
+			break
+		}
+		selected = append(selected, utxo)
+		sum = sum.Add(utxo.Satoshis)
+		needed := target.Add(feePerInput.Mul(decimal.NewFromInt(int64(len(selected)))))
+		if sum.GreaterThanOrEqual(needed) {
+			return selected, nil
+		}
+	}
+	return nil, ErrInsufficientUTXOs
+}
+
+// SumUTXOs returns the total value of utxos in satoshis
+func SumUTXOs(utxos []UTXO) decimal.Decimal {
+	var sum decimal.Decimal
+	for _, utxo := range utxos {
+		sum = sum.Add(utxo.Satoshis)
+	}
+	return sum
+}
@@ -0,0 +1,91 @@
+package qtum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func testUTXOs(amounts ...int64) []UTXO {
+	utxos := make([]UTXO, 0, len(amounts))
+	for i, amount := range amounts {
+		utxos = append(utxos, UTXO{
+			TXID:        "7e1f6e0a2a0ad7e9c0bd0dff3e0a3a1a0a6a2a1f7b4f0c0e2b9a6b1d7e2c3a4b",
+			OutputIndex: uint(i),
+			Satoshis:    decimal.NewFromInt(amount),
+		})
+	}
+	return utxos
+}
+
+func selectedIndexes(utxos []UTXO) []uint {
+	var indexes []uint
+	for _, utxo := range utxos {
+		indexes = append(indexes, utxo.OutputIndex)
+	}
+	return indexes
+}
+
+func TestCoinSelectors(t *testing.T) {
+	utxos := testUTXOs(500, 3000, 20000, 5000, 100)
+	fee := decimal.NewFromInt(100)
+
+	tests := []struct {
+		name     string
+		selector CoinSelector
+		target   int64
+		want     []uint
+	}{
+		{"largest first", &LargestFirstSelector{}, 21000, []uint{2, 3}},
+		// 20000 + 3000 - 2 * 100 is exactly 22800, avoiding any change
+		{"branch and bound", &BranchAndBoundSelector{Fallback: &LargestFirstSelector{}}, 22800, []uint{2, 1}},
+		// the 100 satoshis output isn't worth its fee
+		{"consolidate dust", &ConsolidateDustSelector{MaxInputs: 10}, 8000, []uint{0, 1, 3}},
+		{"consolidate dust over max inputs", &ConsolidateDustSelector{MaxInputs: 2, Fallback: &LargestFirstSelector{}}, 8000, []uint{2}},
+	}
+
+	for _, test := range tests {
+		selected, err := test.selector.Select(utxos, decimal.NewFromInt(test.target), fee)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		got := selectedIndexes(selected)
+		if len(got) != len(test.want) {
+			t.Fatalf("%s: selected %v, want %v", test.name, got, test.want)
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Fatalf("%s: selected %v, want %v", test.name, got, test.want)
+			}
+		}
+	}
+}
+
+func TestCoinSelectorInsufficientFunds(t *testing.T) {
+	for _, name := range AllCoinSelections {
+		selector, err := NewCoinSelector(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the inputs cover the target but not their own fee
+		if _, err := selector.Select(testUTXOs(1000, 1000), decimal.NewFromInt(2000), decimal.NewFromInt(10)); err != ErrInsufficientUTXOs {
+			t.Fatalf("%s: expected insufficient funds error, got %v", name, err)
+		}
+	}
+}
+
+func TestFilterSpendableUTXOs(t *testing.T) {
+	utxos := testUTXOs(100, 200, 300)
+	utxos[0].IsStake = true
+	utxos[0].Height = big.NewInt(900)
+	utxos[1].IsStake = true
+	utxos[1].Height = big.NewInt(100)
+
+	mempool := []MempoolAddressDelta{{PrevTxID: utxos[2].TXID, PrevOut: 2}}
+
+	spendable := FilterSpendableUTXOs(utxos, 1000, mempool)
+	if got := selectedIndexes(spendable); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("expected only the mature stake to be spendable, got %v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package qtum
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	EventBlock       = "block"
+	EventTransaction = "transaction"
+)
+
+// PushedPollInterval is how often components still poll qtumd when its events are pushed,
+// catching up with notifications dropped by a full ZMQ queue or a reconnection
+const PushedPollInterval = time.Minute
+
+// eventBufferSize bounds the events queued for a slow subscriber, further events are dropped
+const eventBufferSize = 16
+
+// Event tells that qtumd connected a block or accepted a transaction, Hash is the block hash or the txid
+type Event struct {
+	Type string
+	Hash string
+}
+
+// EventBus fans out the block and transaction events of qtumd to the components that would otherwise poll for them.
+//
+// Events are wake up calls, subscribers still query qtumd for the state they need, so a dropped event only
+// delays them until the next one or their next poll
+type EventBus struct {
+	mutex       sync.RWMutex
+	subscribers map[chan Event]string
+	pushed      map[string]int
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[chan Event]string),
+		pushed:      make(map[string]int),
+	}
+}
+
+// Subscribe returns the events of a type along with the function to stop receiving them
+func (b *EventBus) Subscribe(eventType string) (<-chan Event, func()) {
+	events := make(chan Event, eventBufferSize)
+
+	b.mutex.Lock()
+	b.subscribers[events] = eventType
+	b.mutex.Unlock()
+
+	return events, func() {
+		b.mutex.Lock()
+		delete(b.subscribers, events)
+		b.mutex.Unlock()
+	}
+}
+
+func (b *EventBus) Publish(event Event) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for events, eventType := range b.subscribers {
+		if eventType != event.Type {
+			continue
+		}
+		select {
+		case events <- event:
+		default:
+			// the subscriber is already woken up by the queued events
+		}
+	}
+}
+
+// SetPushed records whether a source currently pushes events of a type
+func (b *EventBus) SetPushed(eventType string, pushed bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if pushed {
+		b.pushed[eventType]++
+	} else if b.pushed[eventType] > 0 {
+		b.pushed[eventType]--
+	}
+}
+
+func (b *EventBus) IsPushed(eventType string) bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return b.pushed[eventType] > 0
+}
+
+// PollInterval returns how often to poll qtumd for an event type, interval without a connected push source
+func (b *EventBus) PollInterval(eventType string, interval time.Duration) time.Duration {
+	if b.IsPushed(eventType) && interval < PushedPollInterval {
+		return PushedPollInterval
+	}
+	return interval
+}
@@ -0,0 +1,75 @@
+package qtum
+
+import (
+	"context"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultFeeConfirmationTarget is the number of blocks estimatesmartfee aims to confirm transactions within
+const DefaultFeeConfirmationTarget = 6
+
+// P2PKHInputSize is the serialized size in bytes of an input spending a P2PKH output with a compressed public key
+const P2PKHInputSize = 148
+
+// uncompressedPubKeyExtraSize is the additional size of an input revealing an uncompressed public key
+const uncompressedPubKeyExtraSize = 32
+
+// InputSize returns the serialized size in bytes of an input spending a P2PKH output
+func InputSize(compressedPubKey bool) int {
+	if !compressedPubKey {
+		return P2PKHInputSize + uncompressedPubKeyExtraSize
+	}
+	return P2PKHInputSize
+}
+
+// EstimateTransactionSize returns the serialized size of a signed transaction spending numInputs
+// P2PKH outputs of a single key into outputs locked by scripts
+func EstimateTransactionSize(numInputs int, compressedPubKey bool, scripts ...[]byte) int {
+	inputSize := InputSize(compressedPubKey)
+
+	// version and lock time
+	size := 4 + 4
+	size += wire.VarIntSerializeSize(uint64(numInputs)) + numInputs*inputSize
+	size += wire.VarIntSerializeSize(uint64(len(scripts)))
+	for _, script := range scripts {
+		size += 8 + wire.VarIntSerializeSize(uint64(len(script))) + len(script)
+	}
+	return size
+}
+
+// EstimateFee returns the fee in satoshis of size bytes at feeRate satoshis per kB
+func EstimateFee(size int, feeRate decimal.Decimal) decimal.Decimal {
+	return feeRate.Mul(decimal.NewFromInt(int64(size))).Div(decimal.NewFromInt(1000)).Ceil()
+}
+
+// GetFeeRate returns the fee rate in satoshis per kB of the transactions built by Janus,
+// that is the higher of qtumd's smart fee estimate and its minimum relay fee
+func (m *Method) GetFeeRate(ctx context.Context) (decimal.Decimal, error) {
+	networkInfo, err := m.GetNetworkInfo(ctx)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	satoshisPerQtum := decimal.NewFromInt(1e8)
+	feeRate := networkInfo.RelayFee.Mul(satoshisPerQtum)
+	if !feeRate.IsPositive() {
+		feeRate = decimal.NewFromInt(DefaultFeeRate)
+	}
+
+	// estimatesmartfee has no estimate until enough transactions were seen, in particular on regtest
+	estimate, err := m.EstimateSmartFee(ctx, DefaultFeeConfirmationTarget)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if estimate.FeeRate != nil {
+		if estimated := estimate.FeeRate.Mul(satoshisPerQtum); estimated.GreaterThan(feeRate) {
+			feeRate = estimated
+		}
+	} else if m.IsDebugEnabled() {
+		m.GetDebugLogger().Log("function", "GetFeeRate", "msg", "no fee estimate, using relay fee", "errors", marshalToString(estimate.Errors))
+	}
+
+	return feeRate, nil
+}
@@ -20,6 +20,7 @@ const (
 	MethodGetTransaction        = "gettransaction"
 	MethodGetPeerInfo           = "getpeerinfo"
 	MethodGetNetworkInfo        = "getnetworkinfo"
+	MethodEstimateSmartFee      = "estimatesmartfee"
 	MethodGetRawTransaction     = "getrawtransaction"
 	MethodCreateContract        = "createcontract"
 	MethodSendToAddress         = "sendtoaddress"
@@ -40,10 +41,14 @@ const (
 	MethodGetStorage            = "getstorage"
 	MethodCreateRawTx           = "createrawtransaction"
 	MethodSignRawTx             = "signrawtransactionwithwallet"
+	MethodSignMessage           = "signmessage"
 	MethodSendRawTx             = "sendrawtransaction"
 	MethodGetStakingInfo        = "getstakinginfo"
 	MethodGetAddressBalance     = "getaddressbalance"
 	MethodGetAddressUTXOs       = "getaddressutxos"
+	MethodGetAddressMempool     = "getaddressmempool"
+	MethodGetAddressDeltas      = "getaddressdeltas"
+	MethodGetRawMempool         = "getrawmempool"
 )
 
 type JSONRPCRequest struct {
@@ -79,6 +84,10 @@ func (err *JSONRPCError) Error() string {
 // in which we may be interesting. If returned error is unknown, returns original
 // error value
 func (err *JSONRPCError) TryGetKnownError() error {
+	// qtumd without -logevents refuses the receipt and log requests with a generic code
+	if err.Message == ErrLogEventsDisabled.Error() {
+		return ErrLogEventsDisabled
+	}
 	knownError := errorCodeMap[err.Code]
 	if knownError == nil {
 		return err
@@ -172,6 +181,13 @@ var (
 
 	// Http server work queue is full, returned as a raw string, not inside a JSON response
 	ErrQtumWorkQueueDepth = errors.New("Work queue depth exceeded")
+	// Returned without contacting qtumd while it can't be reached, see circuitBreaker
+	ErrCircuitOpen = &eth.JSONRPCError{Code: -32002, Message: "qtumd is unavailable, failing fast until it recovers"}
+	// Returned when too many requests are already waiting for qtumd, see concurrencyLimiter.
+	// Its code differs from the -32005 of clients exceeding their rate limit
+	ErrOverloaded = &eth.JSONRPCError{Code: -32010, Message: "too many requests waiting for qtumd, try again later"}
+	// Returned by qtumd when it runs without -logevents
+	ErrLogEventsDisabled = errors.New("Events indexing disabled")
 	// Sometimes truffle is too quick for qtumd and truffle gives up after one error
 	// couldn't proxy eth_blockNumber request: Client#do: Post \"***qtum:3889\": dial tcp: lookup qtum: Try again
 	ErrTryAgain = errors.New("Try again")
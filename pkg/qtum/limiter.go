@@ -0,0 +1,284 @@
+package qtum
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/metrics"
+)
+
+// limiterBackoffRatio scales the limit down when qtumd reports its work queue full
+const limiterBackoffRatio = 0.5
+
+// LimiterConfig bounds the requests sent to qtumd concurrently. The limit starts at Initial, grows while qtumd keeps
+// up and halves when qtumd answers its work queue is full (AIMD), so that it settles around the -rpcworkqueue of qtumd
+type LimiterConfig struct {
+	Initial int
+	Min     int
+	Max     int
+	// MaxQueued requests wait for a slot, further requests fail fast with ErrOverloaded
+	MaxQueued int
+}
+
+// DefaultLimiterConfig starts at the default -rpcworkqueue of qtumd
+var DefaultLimiterConfig = LimiterConfig{
+	Initial:   16,
+	Min:       1,
+	Max:       128,
+	MaxQueued: 1000,
+}
+
+func (config LimiterConfig) validate() error {
+	if config.Min < 1 || config.Initial < config.Min || config.Max < config.Initial {
+		return errors.New("concurrency limits must satisfy 1 <= min <= initial <= max")
+	}
+	if config.MaxQueued < 0 {
+		return errors.New("maximum queued requests must not be negative")
+	}
+	return nil
+}
+
+// requestOutcome is how a request to qtumd went, it drives the limiter and the circuit breaker
+type requestOutcome int
+
+const (
+	// qtumd answered, even with an error
+	outcomeAnswered requestOutcome = iota
+	// qtumd answered its work queue is full
+	outcomeOverloaded
+	// qtumd couldn't be reached or didn't answer in time
+	outcomeFailed
+	// the request was cancelled by the caller, it tells nothing about qtumd
+	outcomeCancelled
+)
+
+func classifyOutcome(ctx context.Context, err error) requestOutcome {
+	switch {
+	case err == nil:
+		return outcomeAnswered
+	case ctx != nil && ctx.Err() != nil:
+		return outcomeCancelled
+	case errors.Cause(err) == ErrQtumWorkQueueDepth:
+		return outcomeOverloaded
+	case isBackendError(err):
+		return outcomeFailed
+	default:
+		return outcomeAnswered
+	}
+}
+
+// observeRequest records a request sent to qtumd in the metrics
+func observeRequest(ctx context.Context, method string, start time.Time, err error) {
+	result := "ok"
+	switch classifyOutcome(ctx, err) {
+	case outcomeAnswered:
+		if err != nil {
+			result = "error"
+		}
+	case outcomeOverloaded:
+		result = "overloaded"
+	case outcomeFailed:
+		result = "failed"
+	case outcomeCancelled:
+		result = "cancelled"
+	}
+	metrics.ObserveQtumRequest(method, time.Since(start), result)
+}
+
+// backendError is an error reaching qtumd, as opposed to an error answered by qtumd
+type backendError struct {
+	err error
+}
+
+func (e *backendError) Error() string {
+	return e.err.Error()
+}
+
+func (e *backendError) Cause() error {
+	return e.err
+}
+
+func isBackendError(err error) bool {
+	for err != nil {
+		if _, ok := err.(*backendError); ok {
+			return true
+		}
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			return false
+		}
+		err = cause.Cause()
+	}
+	return false
+}
+
+// concurrencyLimiter is an AIMD limiter of the requests in flight to qtumd
+type concurrencyLimiter struct {
+	mutex  sync.Mutex
+	config LimiterConfig
+
+	limit    float64
+	inFlight int
+	waiters  []chan struct{}
+	// generation changes on each decrease, so that requests sent before it don't decrease the limit again
+	generation uint64
+}
+
+func newConcurrencyLimiter(config LimiterConfig) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		config: config,
+		limit:  float64(config.Initial),
+	}
+}
+
+// acquire waits for a slot, the returned func releases it with the outcome of the request
+func (l *concurrencyLimiter) acquire(ctx context.Context) (func(requestOutcome), error) {
+	l.mutex.Lock()
+	generation := l.generation
+	if l.inFlight < int(l.limit) && len(l.waiters) == 0 {
+		l.inFlight++
+		l.mutex.Unlock()
+		return l.releaser(generation), nil
+	}
+	if len(l.waiters) >= l.config.MaxQueued {
+		l.mutex.Unlock()
+		return nil, ErrOverloaded
+	}
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mutex.Unlock()
+
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	select {
+	case <-ready:
+		return l.releaser(generation), nil
+	case <-done:
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		for i, waiter := range l.waiters {
+			if waiter == ready {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				return nil, ctx.Err()
+			}
+		}
+		// the slot was granted meanwhile, pass it on
+		l.inFlight--
+		l.wake()
+		return nil, ctx.Err()
+	}
+}
+
+func (l *concurrencyLimiter) releaser(generation uint64) func(requestOutcome) {
+	var once sync.Once
+	return func(outcome requestOutcome) {
+		once.Do(func() {
+			l.release(generation, outcome)
+		})
+	}
+}
+
+func (l *concurrencyLimiter) release(generation uint64, outcome requestOutcome) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	switch outcome {
+	case outcomeAnswered:
+		// grow by about one per limit requests answered, only while the limit is actually reached
+		if l.inFlight >= int(l.limit) && l.limit < float64(l.config.Max) {
+			l.limit += 1 / l.limit
+			if l.limit > float64(l.config.Max) {
+				l.limit = float64(l.config.Max)
+			}
+		}
+	case outcomeOverloaded:
+		if generation == l.generation {
+			l.limit *= limiterBackoffRatio
+			if l.limit < float64(l.config.Min) {
+				l.limit = float64(l.config.Min)
+			}
+			l.generation++
+		}
+	}
+
+	l.inFlight--
+	l.wake()
+}
+
+// wake hands the free slots to the waiters in order, assumes the mutex is locked
+func (l *concurrencyLimiter) wake() {
+	for len(l.waiters) != 0 && l.inFlight < int(l.limit) {
+		l.inFlight++
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+	}
+}
+
+func (l *concurrencyLimiter) stats() (limit int, inFlight int, queued int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return int(l.limit), l.inFlight, len(l.waiters)
+}
+
+// SetConcurrencyLimiter bounds the requests sent to qtumd concurrently
+func SetConcurrencyLimiter(config LimiterConfig) func(*Client) error {
+	return func(c *Client) error {
+		if err := config.validate(); err != nil {
+			return err
+		}
+		c.limiter = newConcurrencyLimiter(config)
+		return nil
+	}
+}
+
+// ConcurrencyStats describes the requests to qtumd and the state of the circuit breaker
+type ConcurrencyStats struct {
+	// requests allowed in flight
+	Limit int `json:"limit"`
+	// requests in flight
+	InFlight int `json:"inFlight"`
+	// requests waiting for a slot
+	Queued int `json:"queued"`
+	// closed, open or half-open
+	Breaker string `json:"breaker"`
+}
+
+func (c *Client) ConcurrencyStats() ConcurrencyStats {
+	limit, inFlight, queued := c.limiter.stats()
+	return ConcurrencyStats{
+		Limit:    limit,
+		InFlight: inFlight,
+		Queued:   queued,
+		Breaker:  c.breaker.state(),
+	}
+}
+
+// send passes a request to qtumd through the circuit breaker and the concurrency limiter,
+// long polling requests aren't limited as they wait in qtumd most of the time
+func (c *Client) send(ctx context.Context, method string, do func() error) error {
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+
+	release := func(requestOutcome) {}
+	if !longPollMethods[method] {
+		var err error
+		release, err = c.limiter.acquire(ctx)
+		if err != nil {
+			c.breaker.done(outcomeCancelled)
+			return err
+		}
+	}
+
+	err := do()
+	outcome := classifyOutcome(ctx, err)
+	release(outcome)
+	if c.breaker.done(outcome) {
+		c.GetErrorLogger().Log("msg", "qtumd keeps failing, failing requests fast until it recovers", "err", err)
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package qtum
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiterQueuesRequests(t *testing.T) {
+	limiter := newConcurrencyLimiter(LimiterConfig{Initial: 2, Min: 1, Max: 4, MaxQueued: 1})
+
+	release1, err := limiter.acquire(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limiter.acquire(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan error)
+	go func() {
+		_, err := limiter.acquire(context.Background())
+		acquired <- err
+	}()
+	for {
+		if _, _, queued := limiter.stats(); queued == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := limiter.acquire(nil); err != ErrOverloaded {
+		t.Fatalf("expected requests over the queue limit to fail fast, got %v", err)
+	}
+
+	release1(outcomeAnswered)
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued request didn't get the released slot")
+	}
+	if limit, inFlight, queued := limiter.stats(); limit != 2 || inFlight != 2 || queued != 0 {
+		t.Fatalf("unexpected stats limit %d in flight %d queued %d", limit, inFlight, queued)
+	}
+}
+
+func TestConcurrencyLimiterAdaptsToOverload(t *testing.T) {
+	limiter := newConcurrencyLimiter(LimiterConfig{Initial: 4, Min: 1, Max: 8, MaxQueued: 10})
+
+	var releases []func(requestOutcome)
+	for i := 0; i < 4; i++ {
+		release, err := limiter.acquire(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		releases = append(releases, release)
+	}
+
+	// requests sent before the decrease don't decrease the limit again
+	releases[0](outcomeOverloaded)
+	releases[1](outcomeOverloaded)
+	if limit, _, _ := limiter.stats(); limit != 2 {
+		t.Fatalf("expected the limit to be halved once, got %d", limit)
+	}
+	releases[2](outcomeAnswered)
+	releases[3](outcomeAnswered)
+
+	// the limit grows back while it is reached
+	for i := 0; i < 10; i++ {
+		release1, _ := limiter.acquire(nil)
+		release2, _ := limiter.acquire(nil)
+		release1(outcomeAnswered)
+		release2(outcomeAnswered)
+	}
+	if limit, _, _ := limiter.stats(); limit <= 2 {
+		t.Fatalf("expected the limit to grow, got %d", limit)
+	}
+}
+
+func TestConcurrencyLimiterCancelledWhileQueued(t *testing.T) {
+	limiter := newConcurrencyLimiter(LimiterConfig{Initial: 1, Min: 1, Max: 1, MaxQueued: 1})
+	release, _ := limiter.acquire(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the queued request to time out, got %v", err)
+	}
+	if _, _, queued := limiter.stats(); queued != 0 {
+		t.Fatalf("cancelled request is still queued")
+	}
+
+	release(outcomeAnswered)
+	if _, inFlight, _ := limiter.stats(); inFlight != 0 {
+		t.Fatalf("expected no request in flight, got %d", inFlight)
+	}
+}
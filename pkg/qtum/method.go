@@ -11,11 +11,26 @@ import (
 
 type Method struct {
 	*Client
+
+	// responses of a batch sent ahead, see Qtum.Prefetch
+	prefetched prefetchedResponses
+}
+
+func (m *Method) Request(method string, params interface{}, result interface{}) error {
+	return m.RequestWithContext(nil, method, params, result)
+}
+
+// RequestWithContext answers the request from the prefetched responses, or else sends it to qtumd
+func (m *Method) RequestWithContext(ctx context.Context, method string, params interface{}, result interface{}) error {
+	if ok, err := m.prefetched.answer(method, params, result); ok {
+		return err
+	}
+	return m.Client.RequestWithContext(ctx, method, params, result)
 }
 
-func (m *Method) Base58AddressToHex(addr string) (string, error) {
+func (m *Method) Base58AddressToHex(ctx context.Context, addr string) (string, error) {
 	var response GetHexAddressResponse
-	err := m.Request(MethodGetHexAddress, GetHexAddressRequest(addr), &response)
+	err := m.RequestWithContext(ctx, MethodGetHexAddress, GetHexAddressRequest(addr), &response)
 	if err != nil {
 		return "", err
 	}
@@ -33,11 +48,11 @@ func marshalToString(i interface{}) string {
 	return result
 }
 
-func (m *Method) FromHexAddress(addr string) (string, error) {
+func (m *Method) FromHexAddress(ctx context.Context, addr string) (string, error) {
 	addr = utils.RemoveHexPrefix(addr)
 
 	var response FromHexAddressResponse
-	err := m.Request(MethodFromHexAddress, FromHexAddressRequest(addr), &response)
+	err := m.RequestWithContext(ctx, MethodFromHexAddress, FromHexAddressRequest(addr), &response)
 	if err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "FromHexAddress", "Address", addr, "error", err)
@@ -48,10 +63,10 @@ func (m *Method) FromHexAddress(addr string) (string, error) {
 	return string(response), nil
 }
 
-func (m *Method) SignMessage(addr string, msg string) (string, error) {
+func (m *Method) SignMessage(ctx context.Context, addr string, msg string) (string, error) {
 	// returns a base64 string
 	var signature string
-	err := m.Request("signmessage", []string{addr, msg}, &signature)
+	err := m.RequestWithContext(ctx, MethodSignMessage, []string{addr, msg}, &signature)
 	if err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "SignMessage", "error", err)
@@ -66,14 +81,14 @@ func (m *Method) SignMessage(addr string, msg string) (string, error) {
 	return signature, nil
 }
 
-func (m *Method) GetTransaction(txID string) (*GetTransactionResponse, error) {
+func (m *Method) GetTransaction(ctx context.Context, txID string) (*GetTransactionResponse, error) {
 	var (
 		req = GetTransactionRequest{
 			TxID: txID,
 		}
 		resp = new(GetTransactionResponse)
 	)
-	err := m.Request(MethodGetTransaction, &req, resp)
+	err := m.RequestWithContext(ctx, MethodGetTransaction, &req, resp)
 	if err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "GetTransaction", "Transaction ID", txID, "error", err)
@@ -86,7 +101,7 @@ func (m *Method) GetTransaction(txID string) (*GetTransactionResponse, error) {
 	return resp, nil
 }
 
-func (m *Method) GetRawTransaction(txID string, hexEncoded bool) (*GetRawTransactionResponse, error) {
+func (m *Method) GetRawTransaction(ctx context.Context, txID string, hexEncoded bool) (*GetRawTransactionResponse, error) {
 	var (
 		req = GetRawTransactionRequest{
 			TxID:    txID,
@@ -94,7 +109,7 @@ func (m *Method) GetRawTransaction(txID string, hexEncoded bool) (*GetRawTransac
 		}
 		resp = new(GetRawTransactionResponse)
 	)
-	err := m.Request(MethodGetRawTransaction, &req, resp)
+	err := m.RequestWithContext(ctx, MethodGetRawTransaction, &req, resp)
 	if err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "GetRawTransaction", "Transaction ID", txID, "Hex Encoded", hexEncoded, "error", err)
@@ -107,9 +122,9 @@ func (m *Method) GetRawTransaction(txID string, hexEncoded bool) (*GetRawTransac
 	return resp, nil
 }
 
-func (m *Method) GetTransactionReceipt(txHash string) (*GetTransactionReceiptResponse, error) {
+func (m *Method) GetTransactionReceipt(ctx context.Context, txHash string) (*GetTransactionReceiptResponse, error) {
 	resp := new(GetTransactionReceiptResponse)
-	err := m.Request(MethodGetTransactionReceipt, GetTransactionReceiptRequest(txHash), resp)
+	err := m.RequestWithContext(ctx, MethodGetTransactionReceipt, GetTransactionReceiptRequest(txHash), resp)
 	if err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "GetTransactionReceipt", "Transaction Hash", txHash, "error", err)
@@ -122,9 +137,22 @@ func (m *Method) GetTransactionReceipt(txHash string) (*GetTransactionReceiptRes
 	return resp, nil
 }
 
-func (m *Method) DecodeRawTransaction(hex string) (*DecodedRawTransactionResponse, error) {
+// GetTransactionReceipts returns the receipts of each of the contract outputs of the transaction, none for transactions
+// without contract outputs
+func (m *Method) GetTransactionReceipts(ctx context.Context, txHash string) ([]TransactionReceipt, error) {
+	var receipts []TransactionReceipt
+	if err := m.RequestWithContext(ctx, MethodGetTransactionReceipt, GetTransactionReceiptRequest(txHash), &receipts); err != nil {
+		if m.IsDebugEnabled() {
+			m.GetDebugLogger().Log("function", "GetTransactionReceipts", "Transaction Hash", txHash, "error", err)
+		}
+		return nil, err
+	}
+	return receipts, nil
+}
+
+func (m *Method) DecodeRawTransaction(ctx context.Context, hex string) (*DecodedRawTransactionResponse, error) {
 	var resp *DecodedRawTransactionResponse
-	err := m.Request(MethodDecodeRawTransaction, DecodeRawTransactionRequest(hex), &resp)
+	err := m.RequestWithContext(ctx, MethodDecodeRawTransaction, DecodeRawTransactionRequest(hex), &resp)
 	if err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "DecodeRawTransaction", "Hex", hex, "error", err)
@@ -137,7 +165,7 @@ func (m *Method) DecodeRawTransaction(hex string) (*DecodedRawTransactionRespons
 	return resp, nil
 }
 
-func (m *Method) GetTransactionOut(hash string, voutNumber int, mempoolIncluded bool) (*GetTransactionOutResponse, error) {
+func (m *Method) GetTransactionOut(ctx context.Context, hash string, voutNumber int, mempoolIncluded bool) (*GetTransactionOutResponse, error) {
 	var (
 		req = GetTransactionOutRequest{
 			Hash:            hash,
@@ -146,7 +174,7 @@ func (m *Method) GetTransactionOut(hash string, voutNumber int, mempoolIncluded
 		}
 		resp = new(GetTransactionOutResponse)
 	)
-	err := m.Request(MethodGetTransactionOut, req, resp)
+	err := m.RequestWithContext(ctx, MethodGetTransactionOut, req, resp)
 	if err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "GetTransactionOut", "Hash", hash, "Vout number", voutNumber, "mempool included", mempoolIncluded, "error", err)
@@ -159,8 +187,8 @@ func (m *Method) GetTransactionOut(hash string, voutNumber int, mempoolIncluded
 	return resp, nil
 }
 
-func (m *Method) GetBlockCount() (resp *GetBlockCountResponse, err error) {
-	err = m.Request(MethodGetBlockCount, nil, &resp)
+func (m *Method) GetBlockCount(ctx context.Context) (resp *GetBlockCountResponse, err error) {
+	err = m.RequestWithContext(ctx, MethodGetBlockCount, nil, &resp)
 	if m.IsDebugEnabled() {
 		if err != nil {
 			m.GetDebugLogger().Log("function", "GetBlockCount", "error", err)
@@ -171,16 +199,16 @@ func (m *Method) GetBlockCount() (resp *GetBlockCountResponse, err error) {
 	return
 }
 
-func (m *Method) GetHashrate() (resp *GetHashrateResponse, err error) {
-	err = m.Request(MethodGetStakingInfo, nil, &resp)
+func (m *Method) GetHashrate(ctx context.Context) (resp *GetHashrateResponse, err error) {
+	err = m.RequestWithContext(ctx, MethodGetStakingInfo, nil, &resp)
 	if err != nil && m.IsDebugEnabled() {
 		m.GetDebugLogger().Log("function", "GetHashrate", "error", err)
 	}
 	return
 }
 
-func (m *Method) GetMining() (resp *GetMiningResponse, err error) {
-	err = m.Request(MethodGetStakingInfo, nil, &resp)
+func (m *Method) GetMining(ctx context.Context) (resp *GetMiningResponse, err error) {
+	err = m.RequestWithContext(ctx, MethodGetStakingInfo, nil, &resp)
 	if err != nil && m.IsDebugEnabled() {
 		m.GetDebugLogger().Log("function", "GetMining", "error", err)
 	}
@@ -188,73 +216,66 @@ func (m *Method) GetMining() (resp *GetMiningResponse, err error) {
 }
 
 // hard coded for now as there is only the minimum gas price
-func (m *Method) GetGasPrice() (*big.Int, error) {
+func (m *Method) GetGasPrice(ctx context.Context) (*big.Int, error) {
 	// 40 satoshi
 	minimumGas := big.NewInt(0x28)
 	m.GetDebugLogger().Log("Message", "GetGasPrice is hardcoded to "+minimumGas.String())
 	return minimumGas, nil
 }
 
-// hard coded 0x1 due to the unique nature of Qtums UTXO system, might
-func (m *Method) GetTransactionCount(address string, status string) (*big.Int, error) {
-	// eventually might work this out to see if there's any transactions pending for an address in the mempool
-	// for now just always return 1
-	m.GetDebugLogger().Log("Message", "GetTransactionCount is hardcoded to one")
-	return big.NewInt(0x1), nil
-}
-
-func (m *Method) GetBlockHash(b *big.Int) (resp GetBlockHashResponse, err error) {
+func (m *Method) GetBlockHash(ctx context.Context, b *big.Int) (resp GetBlockHashResponse, err error) {
 	req := GetBlockHashRequest{
 		Int: b,
 	}
-	err = m.Request(MethodGetBlockHash, &req, &resp)
+	err = m.RequestWithContext(ctx, MethodGetBlockHash, &req, &resp)
 	if err != nil && m.IsDebugEnabled() {
 		m.GetDebugLogger().Log("function", "GetBlockHash", "Block", b.String(), "error", err)
 	}
 	return resp, err
 }
 
-func (m *Method) GetBlockChainInfo() (resp GetBlockChainInfoResponse, err error) {
-	err = m.Request(MethodGetBlockChainInfo, nil, &resp)
+func (m *Method) GetBlockChainInfo(ctx context.Context) (resp GetBlockChainInfoResponse, err error) {
+	err = m.RequestWithContext(ctx, MethodGetBlockChainInfo, nil, &resp)
 	if err != nil && m.IsDebugEnabled() {
 		m.GetDebugLogger().Log("function", "GetBlockChainInfo", "error", err)
 	}
 	return resp, err
 }
 
-func (m *Method) GetBlockHeader(hash string) (resp *GetBlockHeaderResponse, err error) {
+func (m *Method) GetBlockHeader(ctx context.Context, hash string) (resp *GetBlockHeaderResponse, err error) {
 	req := GetBlockHeaderRequest{
 		Hash: hash,
 	}
-	err = m.Request(MethodGetBlockHeader, &req, &resp)
+	err = m.RequestWithContext(ctx, MethodGetBlockHeader, &req, &resp)
 	if err != nil && m.IsDebugEnabled() {
 		m.GetDebugLogger().Log("function", "GetBlockHash", "Hash", hash, "error", err)
 	}
 	return
 }
 
-func (m *Method) GetBlock(hash string) (resp *GetBlockResponse, err error) {
+func (m *Method) GetBlock(ctx context.Context, hash string) (resp *GetBlockResponse, err error) {
 	req := GetBlockRequest{
 		Hash: hash,
 	}
-	err = m.Request(MethodGetBlock, &req, &resp)
+	err = m.RequestWithContext(ctx, MethodGetBlock, &req, &resp)
 	if err != nil && m.IsDebugEnabled() {
 		m.GetDebugLogger().Log("function", "GetBlock", "Hash", hash, "error", err)
 	}
 	return
 }
 
-func (m *Method) Generate(blockNum int, maxTries *int) (resp GenerateResponse, err error) {
+func (m *Method) Generate(ctx context.Context, blockNum int, maxTries *int) (resp GenerateResponse, err error) {
 	generateToAccount := m.GetFlagString(FLAG_GENERATE_ADDRESS_TO)
 
-	if len(m.Accounts) == 0 && generateToAccount == nil {
+	accounts := m.GetAccounts()
+	if len(accounts) == 0 && generateToAccount == nil {
 		return nil, errors.New("you must specify QTUM accounts")
 	}
 
 	var qAddress string
 
 	if generateToAccount == nil {
-		acc := Account{m.Accounts[0]}
+		acc := Account{WIF: accounts[0]}
 
 		qAddress, err = acc.ToBase58Address(m.isMain)
 		if err != nil {
@@ -278,7 +299,7 @@ func (m *Method) Generate(blockNum int, maxTries *int) (resp GenerateResponse, e
 	// bytes, _ := req.MarshalJSON()
 	// log.Println("generatetoaddres req:", bytes)
 
-	err = m.Request(MethodGenerateToAddress, &req, &resp)
+	err = m.RequestWithContext(ctx, MethodGenerateToAddress, &req, &resp)
 	if m.IsDebugEnabled() {
 		if err != nil {
 			m.GetDebugLogger().Log("function", "Generate", "msg", "Failed to generate block", "error", err)
@@ -293,8 +314,8 @@ func (m *Method) Generate(blockNum int, maxTries *int) (resp GenerateResponse, e
  * Note that QTUM searchlogs api returns all logs in a transaction receipt if any log matches a topic
  * While Ethereum behaves differently and will only return logs where topics match
  */
-func (m *Method) SearchLogs(req *SearchLogsRequest) (receipts SearchLogsResponse, err error) {
-	if err := m.Request(MethodSearchLogs, req, &receipts); err != nil {
+func (m *Method) SearchLogs(ctx context.Context, req *SearchLogsRequest) (receipts SearchLogsResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodSearchLogs, req, &receipts); err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "SearchLogs", "erorr", err)
 		}
@@ -306,8 +327,8 @@ func (m *Method) SearchLogs(req *SearchLogsRequest) (receipts SearchLogsResponse
 	return
 }
 
-func (m *Method) CallContract(req *CallContractRequest) (resp *CallContractResponse, err error) {
-	if err := m.Request(MethodCallContract, req, &resp); err != nil {
+func (m *Method) CallContract(ctx context.Context, req *CallContractRequest) (resp *CallContractResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodCallContract, req, &resp); err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "CallContract", "error", err)
 		}
@@ -319,8 +340,8 @@ func (m *Method) CallContract(req *CallContractRequest) (resp *CallContractRespo
 	return
 }
 
-func (m *Method) GetAccountInfo(req *GetAccountInfoRequest) (resp *GetAccountInfoResponse, err error) {
-	if err := m.Request(MethodGetAccountInfo, req, &resp); err != nil {
+func (m *Method) GetAccountInfo(ctx context.Context, req *GetAccountInfoRequest) (resp *GetAccountInfoResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodGetAccountInfo, req, &resp); err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "GetAccountInfo", "request", req, "error", err)
 		}
@@ -332,9 +353,9 @@ func (m *Method) GetAccountInfo(req *GetAccountInfoRequest) (resp *GetAccountInf
 	return
 }
 
-func (m *Method) GetAddressUTXOs(req *GetAddressUTXOsRequest) (*GetAddressUTXOsResponse, error) {
+func (m *Method) GetAddressUTXOs(ctx context.Context, req *GetAddressUTXOsRequest) (*GetAddressUTXOsResponse, error) {
 	resp := new(GetAddressUTXOsResponse)
-	if err := m.Request(MethodGetAddressUTXOs, req, resp); err != nil {
+	if err := m.RequestWithContext(ctx, MethodGetAddressUTXOs, req, resp); err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "GetAddressUTXOs", "error", err)
 		}
@@ -346,8 +367,41 @@ func (m *Method) GetAddressUTXOs(req *GetAddressUTXOsRequest) (*GetAddressUTXOsR
 	return resp, nil
 }
 
-func (m *Method) ListUnspent(req *ListUnspentRequest) (resp *ListUnspentResponse, err error) {
-	if err := m.Request(MethodListUnspent, req, &resp); err != nil {
+func (m *Method) GetAddressMempool(ctx context.Context, req *GetAddressMempoolRequest) (*GetAddressMempoolResponse, error) {
+	resp := new(GetAddressMempoolResponse)
+	if err := m.RequestWithContext(ctx, MethodGetAddressMempool, req, resp); err != nil {
+		if m.IsDebugEnabled() {
+			m.GetDebugLogger().Log("function", "GetAddressMempool", "error", err)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (m *Method) GetAddressDeltas(ctx context.Context, req *GetAddressDeltasRequest) (*GetAddressDeltasResponse, error) {
+	resp := new(GetAddressDeltasResponse)
+	if err := m.RequestWithContext(ctx, MethodGetAddressDeltas, req, resp); err != nil {
+		if m.IsDebugEnabled() {
+			m.GetDebugLogger().Log("function", "GetAddressDeltas", "error", err)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (m *Method) GetRawMempool(ctx context.Context) (GetRawMempoolResponse, error) {
+	var resp GetRawMempoolResponse
+	if err := m.RequestWithContext(ctx, MethodGetRawMempool, nil, &resp); err != nil {
+		if m.IsDebugEnabled() {
+			m.GetDebugLogger().Log("function", "GetRawMempool", "error", err)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (m *Method) ListUnspent(ctx context.Context, req *ListUnspentRequest) (resp *ListUnspentResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodListUnspent, req, &resp); err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "ListUnspent", "error", err)
 		}
@@ -359,8 +413,8 @@ func (m *Method) ListUnspent(req *ListUnspentRequest) (resp *ListUnspentResponse
 	return
 }
 
-func (m *Method) GetStorage(req *GetStorageRequest) (resp *GetStorageResponse, err error) {
-	if err := m.Request(MethodGetStorage, req, &resp); err != nil {
+func (m *Method) GetStorage(ctx context.Context, req *GetStorageRequest) (resp *GetStorageResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodGetStorage, req, &resp); err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "GetStorage", "error", err)
 		}
@@ -372,8 +426,8 @@ func (m *Method) GetStorage(req *GetStorageRequest) (resp *GetStorageResponse, e
 	return
 }
 
-func (m *Method) GetAddressBalance(req *GetAddressBalanceRequest) (resp *GetAddressBalanceResponse, err error) {
-	if err := m.Request(MethodGetAddressBalance, req, &resp); err != nil {
+func (m *Method) GetAddressBalance(ctx context.Context, req *GetAddressBalanceRequest) (resp *GetAddressBalanceResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodGetAddressBalance, req, &resp); err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "GetAddressBalance", "error", err)
 		}
@@ -385,8 +439,8 @@ func (m *Method) GetAddressBalance(req *GetAddressBalanceRequest) (resp *GetAddr
 	return
 }
 
-func (m *Method) SendRawTransaction(req *SendRawTransactionRequest) (resp *SendRawTransactionResponse, err error) {
-	if err := m.Request(MethodSendRawTx, req, &resp); err != nil {
+func (m *Method) SendRawTransaction(ctx context.Context, req *SendRawTransactionRequest) (resp *SendRawTransactionResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodSendRawTx, req, &resp); err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "SendRawTransaction", "error", err)
 		}
@@ -398,8 +452,8 @@ func (m *Method) SendRawTransaction(req *SendRawTransactionRequest) (resp *SendR
 	return
 }
 
-func (m *Method) GetPeerInfo() (resp []GetPeerInfoResponse, err error) {
-	if err := m.Request(MethodGetPeerInfo, []string{}, &resp); err != nil {
+func (m *Method) GetPeerInfo(ctx context.Context) (resp []GetPeerInfoResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodGetPeerInfo, []string{}, &resp); err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "GetPeerInfo", "error", err)
 		}
@@ -411,8 +465,18 @@ func (m *Method) GetPeerInfo() (resp []GetPeerInfoResponse, err error) {
 	return
 }
 
-func (m *Method) GetNetworkInfo() (resp *NetworkInfoResponse, err error) {
-	if err := m.Request(MethodGetNetworkInfo, []string{}, &resp); err != nil {
+func (m *Method) EstimateSmartFee(ctx context.Context, confTarget int64) (resp *EstimateSmartFeeResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodEstimateSmartFee, EstimateSmartFeeRequest{ConfTarget: confTarget}, &resp); err != nil {
+		if m.IsDebugEnabled() {
+			m.GetDebugLogger().Log("function", "EstimateSmartFee", "error", err)
+		}
+		return nil, err
+	}
+	return
+}
+
+func (m *Method) GetNetworkInfo(ctx context.Context) (resp *NetworkInfoResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodGetNetworkInfo, []string{}, &resp); err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "GetPeerInfo", "error", err)
 		}
@@ -424,11 +488,7 @@ func (m *Method) GetNetworkInfo() (resp *NetworkInfoResponse, err error) {
 	return
 }
 
-func (m *Method) WaitForLogs(req *WaitForLogsRequest) (resp *WaitForLogsResponse, err error) {
-	return m.WaitForLogsWithContext(nil, req)
-}
-
-func (m *Method) WaitForLogsWithContext(ctx context.Context, req *WaitForLogsRequest) (resp *WaitForLogsResponse, err error) {
+func (m *Method) WaitForLogs(ctx context.Context, req *WaitForLogsRequest) (resp *WaitForLogsResponse, err error) {
 	if err := m.RequestWithContext(ctx, MethodWaitForLogs, req, &resp); err != nil {
 		if m.IsDebugEnabled() {
 			m.GetDebugLogger().Log("function", "WaitForLogs", "error", err)
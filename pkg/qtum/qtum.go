@@ -1,6 +1,7 @@
 package qtum
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 
@@ -34,6 +35,15 @@ func New(c *Client, chain string) (*Qtum, error) {
 	}, nil
 }
 
+// Request goes through Method, which may answer it from prefetched responses
+func (c *Qtum) Request(method string, params interface{}, result interface{}) error {
+	return c.Method.Request(method, params, result)
+}
+
+func (c *Qtum) RequestWithContext(ctx context.Context, method string, params interface{}, result interface{}) error {
+	return c.Method.RequestWithContext(ctx, method, params, result)
+}
+
 func (c *Qtum) Chain() string {
 	return c.chain
 }
@@ -42,12 +52,12 @@ func (c *Qtum) CanGenerate() bool {
 	return c.Chain() == ChainRegTest
 }
 
-func (c *Qtum) GenerateIfPossible() {
+func (c *Qtum) GenerateIfPossible(ctx context.Context) {
 	if !c.CanGenerate() {
 		return
 	}
 
-	if _, generateErr := c.Generate(1, nil); generateErr != nil {
+	if _, generateErr := c.Generate(ctx, 1, nil); generateErr != nil {
 		c.GetErrorLogger().Log("Error generating new block", generateErr)
 	}
 }
@@ -17,8 +17,9 @@ import (
 const (
 	genesisBlockHeight = 0
 
-	// Is hex representation of 21000 value, which is default value
-	DefaultBlockGasLimit = "5208"
+	// Is hex representation of 40000000 value, which is the block gas
+	// limit of qtum unless it is changed by the DGP
+	DefaultBlockGasLimit = "2625a00"
 
 	// Is a zero wallet address, which is used as a stub, when
 	// original value cannot be defined in such cases as generated
@@ -131,14 +132,15 @@ type (
 				Timeout   int64  `json:"timeout"`
 			} `json:"segwit"`
 		} `json:"bip9_softforks"`
-		Blocks     int64   `json:"blocks"`
-		Chain      string  `json:"chain"`
-		Chainwork  string  `json:"chainwork"`
-		Difficulty float64 `json:"difficulty"`
-		Headers    int64   `json:"headers"`
-		Mediantime int64   `json:"mediantime"`
-		Pruned     bool    `json:"pruned"`
-		Softforks  map[string]struct {
+		Blocks               int64   `json:"blocks"`
+		Chain                string  `json:"chain"`
+		Chainwork            string  `json:"chainwork"`
+		Difficulty           float64 `json:"difficulty"`
+		Headers              int64   `json:"headers"`
+		InitialBlockDownload bool    `json:"initialblockdownload"`
+		Mediantime           int64   `json:"mediantime"`
+		Pruned               bool    `json:"pruned"`
+		Softforks            map[string]struct {
 			Type   string `json:"type"`
 			Active bool   `json:"active"`
 			Height int64  `json:"height"`
@@ -1222,6 +1224,10 @@ func (r *GetBlockHeaderResponse) IsGenesisBlock() bool {
 	return r.Height == genesisBlockHeight
 }
 
+func (r *GetBlockHeaderResponse) IsProofOfStake() bool {
+	return r.Flags == "proof-of-stake"
+}
+
 // ========== GetBlock ============= //
 type (
 	GetBlockRequest struct {
@@ -1439,7 +1445,7 @@ type (
 		Address     string          `json:"address"`
 		TXID        string          `json:"txid"`
 		OutputIndex uint            `json:"outputIndex"`
-		Script      string          `json:"string"`
+		Script      string          `json:"script"`
 		Satoshis    decimal.Decimal `json:"satoshis"`
 		Height      *big.Int        `json:"height"`
 		IsStake     bool            `json:"isStake"`
@@ -1469,6 +1475,152 @@ func (r *GetAddressUTXOsRequest) MarshalJSON() ([]byte, error) {
 	return json.Marshal(params)
 }
 
+// ========== EstimateSmartFee ============= //
+
+type (
+	/*
+		Arguments:
+		1. conf_target      (numeric, required) Confirmation target in blocks (1 - 1008)
+
+		Result:
+		{
+		"feerate" : x.x,     (numeric, optional) estimate fee rate in QTUM/kB
+		"errors": [ str... ] (json array of strings, optional) Errors encountered during processing
+		"blocks" : n         (numeric) block number where estimate was found
+		}
+	*/
+	EstimateSmartFeeRequest struct {
+		ConfTarget int64
+	}
+
+	EstimateSmartFeeResponse struct {
+		FeeRate *decimal.Decimal `json:"feerate"`
+		Errors  []string         `json:"errors"`
+		Blocks  int64            `json:"blocks"`
+	}
+)
+
+func (r EstimateSmartFeeRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{r.ConfTarget})
+}
+
+// ========== GetAddressMempool ============= //
+
+type (
+	/*
+		Arguments:
+		1. Input params              (json object, required) Json object
+			{
+			"addresses": [        (json array, required) The qtum addresses
+				"address",          (string) The qtum address
+				...
+			]
+			}
+
+		Result:
+		[                         (json array)
+		{                       (json object)
+		"address" : "str",    (string) The address base58check encoded
+		"txid" : "hex",       (string) The related txid
+		"index" : n,          (numeric) The related input or output index
+		"satoshis" : n,       (numeric) The difference of satoshis
+		"timestamp" : n,      (numeric) The time the transaction entered the mempool (seconds)
+		"prevtxid" : "hex",   (string) The previous txid (if spending)
+		"prevout" : n         (numeric) The previous transaction output index (if spending)
+		}
+	*/
+	GetAddressMempoolRequest struct {
+		Addresses []string `json:"addresses"`
+	}
+
+	MempoolAddressDelta struct {
+		Address   string          `json:"address"`
+		TXID      string          `json:"txid"`
+		Index     uint            `json:"index"`
+		Satoshis  decimal.Decimal `json:"satoshis"`
+		Timestamp int64           `json:"timestamp"`
+		PrevTxID  string          `json:"prevtxid"`
+		PrevOut   uint            `json:"prevout"`
+	}
+
+	GetAddressMempoolResponse []MempoolAddressDelta
+)
+
+func (r *GetAddressMempoolRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]map[string]interface{}{
+		{"addresses": r.Addresses},
+	})
+}
+
+// ========== GetAddressDeltas ============= //
+
+type (
+	/*
+		Arguments:
+		1. Input params              (json object, required) Json object
+			{
+			"addresses": [        (json array, required) The qtum addresses
+				"address",          (string) The qtum address
+				...
+			],
+			"start": n,           (numeric, optional) The start block height
+			"end": n,             (numeric, optional) The end block height
+			}
+
+		Result:
+		[                         (json array)
+		{                       (json object)
+		"satoshis" : n,       (numeric) The difference of satoshis
+		"txid" : "hex",       (string) The related txid
+		"index" : n,          (numeric) The related input or output index
+		"blockindex" : n,     (numeric) The related block index
+		"height" : n,         (numeric) The block height
+		"address" : "str"     (string) The base58check encoded address
+		}
+	*/
+	GetAddressDeltasRequest struct {
+		Addresses []string
+		// the whole history is returned unless both Start and End are set
+		Start int64
+		End   int64
+	}
+
+	AddressDelta struct {
+		Satoshis   decimal.Decimal `json:"satoshis"`
+		TXID       string          `json:"txid"`
+		Index      uint            `json:"index"`
+		BlockIndex uint            `json:"blockindex"`
+		Height     int64           `json:"height"`
+		Address    string          `json:"address"`
+	}
+
+	GetAddressDeltasResponse []AddressDelta
+)
+
+func (r *GetAddressDeltasRequest) MarshalJSON() ([]byte, error) {
+	params := map[string]interface{}{
+		"addresses": r.Addresses,
+	}
+	if r.Start > 0 && r.End > 0 {
+		params["start"] = r.Start
+		params["end"] = r.End
+	}
+	return json.Marshal([]map[string]interface{}{params})
+}
+
+// ========== GetRawMempool ============= //
+
+type (
+	/*
+		Result:
+		[           (json array of string)
+		"hex",    (string) The transaction id
+		...
+		]
+	*/
+	GetRawMempoolResponse []string
+)
+
 // ========== ListUnspent ============= //
 type (
 
@@ -1735,7 +1887,7 @@ type (
 		Subversion         string                    `json:"subversion"`
 		ProtocolVersion    int64                     `json:"protocolversion"`
 		LocalServices      string                    `json:"localservices"`
-		LocalServicesNames []string                  `json:"localservicesnames`
+		LocalServicesNames []string                  `json:"localservicesnames"`
 		LocalRelay         bool                      `json:"localrelay"`
 		TimeOffset         int64                     `json:"timeoffset"`
 		Connections        int64                     `json:"connections"`
@@ -1766,7 +1918,7 @@ type (
 type (
 	WaitForLogsRequest struct {
 		FromBlock            interface{}       `json:"fromBlock"`
-		ToBlock              interface{}       `json:"toBlock`
+		ToBlock              interface{}       `json:"toBlock"`
 		Filter               WaitForLogsFilter `json:"filter"`
 		MinimumConfirmations int64             `json:"miniconf"`
 	}
@@ -0,0 +1,240 @@
+package qtum
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/pkg/errors"
+)
+
+// Qtum specific opcodes, see https://github.com/qtumproject/qtum/blob/master/src/script/script.h
+const (
+	OpCreate = 0xc1
+	OpCall   = 0xc2
+	OpSender = 0xc4
+)
+
+// SenderAddressTypePubKeyHash is the OP_SENDER address type of a P2PKH sender
+const SenderAddressTypePubKeyHash = 1
+
+// ContractVMVersion is the serialized default EVM version pushed into contract outputs
+const ContractVMVersion = 4
+
+// PayToPubKeyHashScript returns the standard P2PKH script paying to a hex address
+func PayToPubKeyHashScript(pubKeyHash []byte) ([]byte, error) {
+	if len(pubKeyHash) != 20 {
+		return nil, errors.Errorf("invalid public key hash length: %d", len(pubKeyHash))
+	}
+	return txscript.NewScriptBuilder().
+		AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).
+		AddData(pubKeyHash).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+}
+
+// PayeePubKeyHash returns the public key hash a P2PKH or P2PK output script pays to
+func PayeePubKeyHash(script []byte) ([]byte, bool) {
+	switch txscript.GetScriptClass(script) {
+	case txscript.PubKeyHashTy:
+		// OP_DUP OP_HASH160 <pubKeyHash> OP_EQUALVERIFY OP_CHECKSIG
+		return script[3:23], true
+	case txscript.PubKeyTy:
+		// <pubKey> OP_CHECKSIG
+		return btcutil.Hash160(script[1 : len(script)-1]), true
+	default:
+		return nil, false
+	}
+}
+
+// SignerPubKeyHash returns the public key hash of the signer of a P2PKH input script
+//
+//	<signature> <pubKey>
+func SignerPubKeyHash(scriptSig []byte) ([]byte, bool) {
+	pushes, err := txscript.PushedData(scriptSig)
+	if err != nil || len(pushes) != 2 {
+		return nil, false
+	}
+	pubKey := pushes[1]
+	if len(pubKey) != 33 && len(pubKey) != 65 {
+		return nil, false
+	}
+	return btcutil.Hash160(pubKey), true
+}
+
+// ContractCallScript returns an OP_CALL output script:
+//
+//	<version> <gasLimit> <gasPrice> <data> <contract address> OP_CALL
+//
+// The sender of the call is the owner of the transaction's first input
+func ContractCallScript(contract []byte, data []byte, gasLimit, gasPrice int64) ([]byte, error) {
+	if len(contract) != 20 {
+		return nil, errors.Errorf("invalid contract address length: %d", len(contract))
+	}
+	script := contractScriptPrefix(gasLimit, gasPrice)
+	script = appendPushData(script, data)
+	script = appendPushData(script, contract)
+	return append(script, OpCall), nil
+}
+
+// ContractCreateScript returns an OP_CREATE output script:
+//
+//	<version> <gasLimit> <gasPrice> <byte code> OP_CREATE
+func ContractCreateScript(byteCode []byte, gasLimit, gasPrice int64) ([]byte, error) {
+	if len(byteCode) == 0 {
+		return nil, errors.New("empty contract byte code")
+	}
+	script := contractScriptPrefix(gasLimit, gasPrice)
+	script = appendPushData(script, byteCode)
+	return append(script, OpCreate), nil
+}
+
+// ContractSenderScript prefixes an OP_CALL or OP_CREATE script with the P2PKH sender of the contract call:
+//
+//	<address type> <pubKeyHash> <scriptSig> OP_SENDER <contract script>
+//
+// scriptSig is the input script signing the output, as qtumd the output is left unsigned with an empty one
+func ContractSenderScript(pubKeyHash []byte, scriptSig []byte, contractScript []byte) ([]byte, error) {
+	if len(pubKeyHash) != 20 {
+		return nil, errors.Errorf("invalid public key hash length: %d", len(pubKeyHash))
+	}
+	var serializedScriptSig []byte
+	if len(scriptSig) > 0 {
+		// the script is serialized with its length
+		var buf bytes.Buffer
+		if err := wire.WriteVarBytes(&buf, 0, scriptSig); err != nil {
+			return nil, errors.Wrap(err, "couldn't serialize script signature")
+		}
+		serializedScriptSig = buf.Bytes()
+	}
+	script := appendPushData(nil, scriptNum(SenderAddressTypePubKeyHash))
+	script = appendPushData(script, pubKeyHash)
+	script = appendPushData(script, serializedScriptSig)
+	script = append(script, OpSender)
+	return append(script, contractScript...), nil
+}
+
+// withoutSenderSig returns an OP_SENDER output script with an empty signature, which is how it is signed
+func withoutSenderSig(pkScript []byte) ([]byte, bool) {
+	ops, err := parseScriptOps(pkScript)
+	if err != nil || len(ops) < 4 || ops[3].opcode != OpSender {
+		return nil, false
+	}
+	start := ops[0].size + ops[1].size
+	end := start + ops[2].size
+	script := append(append([]byte{}, pkScript[:start]...), txscript.OP_0)
+	return append(script, pkScript[end:]...), true
+}
+
+func contractScriptPrefix(gasLimit, gasPrice int64) []byte {
+	// qtumd serializes every parameter as a data push, even the small ones which
+	// standard scripts would encode as OP_1..OP_16
+	script := appendPushData(nil, scriptNum(ContractVMVersion))
+	script = appendPushData(script, scriptNum(gasLimit))
+	return appendPushData(script, scriptNum(gasPrice))
+}
+
+// appendPushData appends a push of data without converting small values to OP_N opcodes
+// and without the standard 520 bytes limit, which doesn't apply to contract byte code
+func appendPushData(script []byte, data []byte) []byte {
+	length := len(data)
+	switch {
+	case length < txscript.OP_PUSHDATA1:
+		script = append(script, byte(length))
+	case length <= 0xff:
+		script = append(script, txscript.OP_PUSHDATA1, byte(length))
+	case length <= 0xffff:
+		buf := make([]byte, 2)
+		binary.LittleEndian.PutUint16(buf, uint16(length))
+		script = append(script, txscript.OP_PUSHDATA2)
+		script = append(script, buf...)
+	default:
+		buf := make([]byte, 4)
+		binary.LittleEndian.PutUint32(buf, uint32(length))
+		script = append(script, txscript.OP_PUSHDATA4)
+		script = append(script, buf...)
+	}
+	return append(script, data...)
+}
+
+// scriptOp is an opcode of a script along with the data it pushes
+type scriptOp struct {
+	opcode byte
+	data   []byte
+	// size is the serialized size of the opcode and its push
+	size int
+}
+
+// parseScriptOps splits a script into its opcodes, it is the inverse of appendPushData for the pushes
+func parseScriptOps(script []byte) ([]scriptOp, error) {
+	var ops []scriptOp
+	for len(script) > 0 {
+		size := len(script)
+		opcode := script[0]
+		script = script[1:]
+
+		var length, lengthSize int
+		switch {
+		case opcode >= txscript.OP_DATA_1 && opcode <= txscript.OP_DATA_75:
+			length = int(opcode)
+		case opcode == txscript.OP_PUSHDATA1:
+			lengthSize = 1
+		case opcode == txscript.OP_PUSHDATA2:
+			lengthSize = 2
+		case opcode == txscript.OP_PUSHDATA4:
+			lengthSize = 4
+		}
+		if lengthSize > 0 {
+			if len(script) < lengthSize {
+				return nil, errors.Errorf("truncated length of opcode %#x", opcode)
+			}
+			buf := make([]byte, 4)
+			copy(buf, script[:lengthSize])
+			length = int(binary.LittleEndian.Uint32(buf))
+			script = script[lengthSize:]
+		}
+		if len(script) < length {
+			return nil, errors.Errorf("opcode %#x pushes %d bytes, only %d left", opcode, length, len(script))
+		}
+
+		data := script[:length]
+		script = script[length:]
+		ops = append(ops, scriptOp{opcode: opcode, data: data, size: size - len(script)})
+	}
+	return ops, nil
+}
+
+// scriptNum serializes a number the way CScriptNum does: minimal little endian with a sign bit
+func scriptNum(n int64) []byte {
+	if n == 0 {
+		return []byte{}
+	}
+
+	negative := n < 0
+	abs := uint64(n)
+	if negative {
+		abs = uint64(-n)
+	}
+
+	var result []byte
+	for abs > 0 {
+		result = append(result, byte(abs&0xff))
+		abs >>= 8
+	}
+
+	if result[len(result)-1]&0x80 != 0 {
+		extra := byte(0x00)
+		if negative {
+			extra = 0x80
+		}
+		result = append(result, extra)
+	} else if negative {
+		result[len(result)-1] |= 0x80
+	}
+
+	return result
+}
@@ -0,0 +1,113 @@
+package qtum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestPayeePubKeyHash(t *testing.T) {
+	tests := []struct {
+		script string
+		want   string
+		ok     bool
+	}{
+		{
+			// P2PKH
+			script: "76a9146b22910b1e302cf74803ffd1691c2ecb858d371288ac",
+			want:   "6b22910b1e302cf74803ffd1691c2ecb858d3712",
+			ok:     true,
+		},
+		{
+			// P2PK
+			script: "2103520b1500a400483f19b93c4cb277a2f29693ea9d6739daaf6ae6e971d29e3140ac",
+			want:   "6b22910b1e302cf74803ffd1691c2ecb858d3712",
+			ok:     true,
+		},
+		{
+			// OP_CALL
+			script: "010403400d0301644440c10f190000000000000000000000006b22910b1e302cf74803ffd1691c2ecb858d3712000000000000000000000000000000000000000000000000000000000000000a14be528c8378ff082e4ba43cb1baa363dbf3f577bfc2",
+			ok:     false,
+		},
+		{
+			script: "",
+			ok:     false,
+		},
+	}
+	for _, test := range tests {
+		script, err := hex.DecodeString(test.script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := PayeePubKeyHash(script)
+		if ok != test.ok || hex.EncodeToString(got) != test.want {
+			t.Errorf("script %s: want %s %v, got %x %v", test.script, test.want, test.ok, got, ok)
+		}
+	}
+}
+
+func TestSignerPubKeyHash(t *testing.T) {
+	tests := []struct {
+		scriptSig string
+		want      string
+		ok        bool
+	}{
+		{
+			scriptSig: "473044022072d64a1f4ea2d54b7b05050fc853ab192c91cc5ca17e23007867f92f2ab59d9202202b8c9ab9348c8edbb3b98b1788382c8f37642ec9bd6a4429817ab79927319200012103520b1500a400483f19b93c4cb277a2f29693ea9d6739daaf6ae6e971d29e3140",
+			want:      "6b22910b1e302cf74803ffd1691c2ecb858d3712",
+			ok:        true,
+		},
+		{
+			// signature only, as spending a P2PK output
+			scriptSig: "473044022072d64a1f4ea2d54b7b05050fc853ab192c91cc5ca17e23007867f92f2ab59d9202202b8c9ab9348c8edbb3b98b1788382c8f37642ec9bd6a4429817ab7992731920001",
+			ok:        false,
+		},
+		{
+			scriptSig: "",
+			ok:        false,
+		},
+	}
+	for _, test := range tests {
+		scriptSig, err := hex.DecodeString(test.scriptSig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := SignerPubKeyHash(scriptSig)
+		if ok != test.ok || hex.EncodeToString(got) != test.want {
+			t.Errorf("script signature %s: want %s %v, got %x %v", test.scriptSig, test.want, test.ok, got, ok)
+		}
+	}
+}
+
+func TestParseScriptOps(t *testing.T) {
+	pushes := [][]byte{{0x01}, make([]byte, 75), make([]byte, 76), make([]byte, 0x100), make([]byte, 0x10000)}
+	var script []byte
+	for _, data := range pushes {
+		script = appendPushData(script, data)
+	}
+	script = append(script, OpSender)
+
+	ops, err := parseScriptOps(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != len(pushes)+1 || ops[len(pushes)].opcode != OpSender {
+		t.Fatalf("expected %d pushes followed by OP_SENDER, got %d opcodes", len(pushes), len(ops))
+	}
+	for i, data := range pushes {
+		if !bytes.Equal(ops[i].data, data) {
+			t.Errorf("push %d: want %d bytes, got %d", i, len(data), len(ops[i].data))
+		}
+	}
+	size := 0
+	for _, op := range ops {
+		size += op.size
+	}
+	if size != len(script) {
+		t.Errorf("expected the opcodes to span %d bytes, got %d", len(script), size)
+	}
+
+	if _, err := parseScriptOps(script[:len(script)-2]); err == nil {
+		t.Error("expected a truncated push to be rejected")
+	}
+}
@@ -0,0 +1,170 @@
+package qtum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/pkg/errors"
+)
+
+// TransactionSignature is the ECDSA signature authorizing a transaction, in the low S form Ethereum expects
+type TransactionSignature struct {
+	R, S *big.Int
+	// RecoveryID is the y parity of the signature's R point, only known when Recovered
+	RecoveryID byte
+	// Recovered is false when the signer couldn't be recovered from the signed hash
+	Recovered bool
+}
+
+// PrevOutScriptFunc returns the script of output index of transaction txID
+type PrevOutScriptFunc func(txID string, index uint32) ([]byte, error)
+
+// ExtractTransactionSignature returns the signature of the sender of a serialized transaction: the OP_SENDER
+// signature of its contract output, or else the signature of its first input. Transactions without one, like
+// coinbases and segwit spends, return nil. prevOutScript looks up the output spent by a P2PK input, whose
+// public key isn't in the input script, it may be nil
+func ExtractTransactionSignature(txHex string, prevOutScript PrevOutScriptFunc) (*TransactionSignature, error) {
+	raw, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid transaction hex")
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, errors.Wrap(err, "couldn't deserialize transaction")
+	}
+
+	for i, out := range tx.TxOut {
+		if pubKeyHash, scriptSig, ok := senderScriptSig(out.PkScript); ok {
+			sig, hashType, _, ok := parseSignatureScript(scriptSig)
+			if !ok {
+				return nil, errors.New("invalid OP_SENDER signature script")
+			}
+			hash, err := SenderSignatureHash(&tx, i, hashType)
+			if err != nil {
+				return nil, err
+			}
+			return newTransactionSignature(sig, pubKeyHash, hash), nil
+		}
+	}
+
+	if len(tx.TxIn) == 0 || isCoinbaseInput(tx.TxIn[0]) {
+		return nil, nil
+	}
+	in := tx.TxIn[0]
+	sig, hashType, pubKey, ok := parseSignatureScript(in.SignatureScript)
+	if !ok {
+		return nil, nil
+	}
+
+	var pubKeyHash, prevPkScript []byte
+	if pubKey != nil {
+		pubKeyHash, _ = SignerPubKeyHash(in.SignatureScript)
+		prevPkScript, err = PayToPubKeyHashScript(pubKeyHash)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// spending a P2PK output, whose public key is in the spent output
+		if prevOutScript == nil {
+			return newTransactionSignature(sig, nil, nil), nil
+		}
+		prevPkScript, err = prevOutScript(in.PreviousOutPoint.Hash.String(), in.PreviousOutPoint.Index)
+		if err != nil {
+			return nil, errors.WithMessage(err, "couldn't get the spent output")
+		}
+		if txscript.GetScriptClass(prevPkScript) != txscript.PubKeyTy {
+			return newTransactionSignature(sig, nil, nil), nil
+		}
+		pubKeyHash, _ = PayeePubKeyHash(prevPkScript)
+	}
+	hash, err := txscript.CalcSignatureHash(prevPkScript, hashType, &tx, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't compute signature hash")
+	}
+	return newTransactionSignature(sig, pubKeyHash, hash), nil
+}
+
+// newTransactionSignature normalizes S and, given the hash of the signer's public key and the signed hash,
+// finds the recovery id
+func newTransactionSignature(sig *btcec.Signature, pubKeyHash []byte, hash []byte) *TransactionSignature {
+	curve := btcec.S256()
+	s := new(big.Int).Set(sig.S)
+	if s.Cmp(new(big.Int).Rsh(curve.N, 1)) > 0 {
+		s.Sub(curve.N, s)
+	}
+	txSig := &TransactionSignature{R: new(big.Int).Set(sig.R), S: s}
+	if pubKeyHash == nil {
+		return txSig
+	}
+
+	// btcec expects a compact signature: header byte followed by 32 byte r and s
+	compact := make([]byte, 65)
+	copy(compact[33-len(txSig.R.Bytes()):33], txSig.R.Bytes())
+	copy(compact[65-len(txSig.S.Bytes()):], txSig.S.Bytes())
+	for recoveryID := byte(0); recoveryID < 2; recoveryID++ {
+		compact[0] = 27 + recoveryID
+		recovered, _, err := btcec.RecoverCompact(curve, compact, hash)
+		if err != nil {
+			continue
+		}
+		// the signer may have a compressed or uncompressed public key
+		if bytes.Equal(btcutil.Hash160(recovered.SerializeCompressed()), pubKeyHash) ||
+			bytes.Equal(btcutil.Hash160(recovered.SerializeUncompressed()), pubKeyHash) {
+			txSig.RecoveryID = recoveryID
+			txSig.Recovered = true
+			break
+		}
+	}
+	return txSig
+}
+
+// parseSignatureScript parses a P2PKH input script, or a P2PK one which has no public key
+//
+//	<signature> [<pubKey>]
+func parseSignatureScript(scriptSig []byte) (*btcec.Signature, txscript.SigHashType, *btcec.PublicKey, bool) {
+	pushes, err := txscript.PushedData(scriptSig)
+	if err != nil || len(pushes) == 0 || len(pushes) > 2 || len(pushes[0]) == 0 {
+		return nil, 0, nil, false
+	}
+	sigBytes := pushes[0]
+	sig, err := btcec.ParseDERSignature(sigBytes[:len(sigBytes)-1], btcec.S256())
+	if err != nil {
+		return nil, 0, nil, false
+	}
+	hashType := txscript.SigHashType(sigBytes[len(sigBytes)-1])
+	if len(pushes) == 1 {
+		return sig, hashType, nil, true
+	}
+	pubKey, err := btcec.ParsePubKey(pushes[1], btcec.S256())
+	if err != nil {
+		return nil, 0, nil, false
+	}
+	return sig, hashType, pubKey, true
+}
+
+// senderScriptSig returns the public key hash of the sender of an OP_SENDER contract output and its input script
+//
+//	<address type> <pubKeyHash> <scriptSig> OP_SENDER ...
+func senderScriptSig(pkScript []byte) ([]byte, []byte, bool) {
+	ops, err := parseScriptOps(pkScript)
+	if err != nil || len(ops) < 4 || ops[3].opcode != OpSender || len(ops[1].data) != 20 {
+		return nil, nil, false
+	}
+	script := ops[2].data
+	// the script is serialized with its length
+	r := bytes.NewReader(script)
+	length, err := wire.ReadVarInt(r, 0)
+	if err != nil || length != uint64(r.Len()) {
+		return nil, nil, false
+	}
+	return ops[1].data, script[len(script)-r.Len():], true
+}
+
+func isCoinbaseInput(in *wire.TxIn) bool {
+	return in.PreviousOutPoint.Index == wire.MaxPrevOutIndex && in.PreviousOutPoint.Hash == [32]byte{}
+}
@@ -0,0 +1,197 @@
+package qtum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+func TestExtractTransactionSignatureRecoversSigner(t *testing.T) {
+	key, err := btcutil.DecodeWIF("5JK4Gu9nxCvsCxiq9Zf3KdmA9ACza6dUn5BRLVWAYEtQabdnJ89")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevPkScript, err := PayToPubKeyHashScript(btcutil.Hash160(key.SerializePubKey()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// deterministic signatures of different amounts have either parity
+	parities := make(map[byte]bool)
+	for amount := int64(99000000); amount < 99000020; amount++ {
+		builder := NewTransactionBuilder(false)
+		if err := builder.AddInput("7e1f6e0a2a0ad7e9c0bd0dff3e0a3a1a0a6a2a1f7b4f0c0e2b9a6b1d7e2c3a4b", 1, prevPkScript); err != nil {
+			t.Fatal(err)
+		}
+		builder.AddOutput(amount, prevPkScript)
+		if err := builder.Sign(key); err != nil {
+			t.Fatal(err)
+		}
+		rawTx, err := builder.Hex()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sig, err := ExtractTransactionSignature(rawTx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sig == nil || !sig.Recovered {
+			t.Fatalf("expected a recovered signature, got %+v", sig)
+		}
+		if sig.S.Cmp(new(big.Int).Rsh(btcec.S256().N, 1)) > 0 {
+			t.Fatalf("expected a low S value, got %x", sig.S)
+		}
+
+		hash, err := txscript.CalcSignatureHash(prevPkScript, txscript.SigHashAll, builder.tx, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKey := recoverPubKey(t, sig, hash)
+		if !pubKey.IsEqual(key.PrivKey.PubKey()) {
+			t.Fatalf("recovered %x, want %x", pubKey.SerializeCompressed(), key.SerializePubKey())
+		}
+		parities[sig.RecoveryID] = true
+	}
+	if len(parities) != 2 {
+		t.Fatalf("expected signatures of both parities, got %v", parities)
+	}
+}
+
+func TestExtractTransactionSignatureFromSender(t *testing.T) {
+	key, err := btcutil.DecodeWIF("5JK4Gu9nxCvsCxiq9Zf3KdmA9ACza6dUn5BRLVWAYEtQabdnJ89")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract, _ := hex.DecodeString("1286d4ca8ad4c3d3a9b3c1d5e3c4b5f5a6d7e8f9")
+	callScript, err := ContractCallScript(contract, []byte{0xa9, 0x05, 0x9c, 0xbb}, 2500000, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the inputs are paid by another key than the sender's
+	payer, err := btcutil.DecodeWIF("cMbgxCJrTYUqgcmiC1berh5DFrtY1KeU4PXZ6NZxgenniF1mXCRk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payerPkScript, err := PayToPubKeyHashScript(btcutil.Hash160(payer.SerializePubKey()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := NewTransactionBuilder(false)
+	builder.tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, payerPkScript, nil))
+	builder.AddSenderOutput(0, callScript)
+	if err := builder.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	rawTx, err := builder.Hex()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := ExtractTransactionSignature(rawTx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig == nil || !sig.Recovered {
+		t.Fatalf("expected a recovered OP_SENDER signature, got %+v", sig)
+	}
+
+	hash, err := SenderSignatureHash(builder.tx, 0, txscript.SigHashAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pubKey := recoverPubKey(t, sig, hash); !pubKey.IsEqual(key.PrivKey.PubKey()) {
+		t.Fatalf("recovered %x, want the sender %x", pubKey.SerializeCompressed(), key.SerializePubKey())
+	}
+}
+
+func TestExtractTransactionSignatureOfP2PKSpend(t *testing.T) {
+	key, err := btcutil.DecodeWIF("cMbgxCJrTYUqgcmiC1berh5DFrtY1KeU4PXZ6NZxgenniF1mXCRk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevPkScript, err := txscript.NewScriptBuilder().AddData(key.SerializePubKey()).AddOp(txscript.OP_CHECKSIG).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevTxID := "7e1f6e0a2a0ad7e9c0bd0dff3e0a3a1a0a6a2a1f7b4f0c0e2b9a6b1d7e2c3a4b"
+
+	builder := NewTransactionBuilder(false)
+	if err := builder.AddInput(prevTxID, 2, prevPkScript); err != nil {
+		t.Fatal(err)
+	}
+	builder.AddOutput(99000000, prevPkScript)
+	if err := builder.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	rawTx, err := builder.Hex()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the public key is only in the spent output
+	sig, err := ExtractTransactionSignature(rawTx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig == nil || sig.Recovered {
+		t.Fatalf("expected a signature without recovery id, got %+v", sig)
+	}
+
+	sig, err = ExtractTransactionSignature(rawTx, func(txID string, index uint32) ([]byte, error) {
+		if txID != prevTxID || index != 2 {
+			t.Fatalf("unexpected spent output %s:%d", txID, index)
+		}
+		return prevPkScript, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig == nil || !sig.Recovered {
+		t.Fatalf("expected a recovered signature, got %+v", sig)
+	}
+	hash, err := txscript.CalcSignatureHash(prevPkScript, txscript.SigHashAll, builder.tx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pubKey := recoverPubKey(t, sig, hash); !pubKey.IsEqual(key.PrivKey.PubKey()) {
+		t.Fatalf("recovered %x, want %x", pubKey.SerializeCompressed(), key.SerializePubKey())
+	}
+}
+
+func TestExtractTransactionSignatureOfCoinbase(t *testing.T) {
+	tx := wire.NewMsgTx(TransactionVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: wire.MaxPrevOutIndex}, []byte{0x51}, nil))
+	tx.AddTxOut(wire.NewTxOut(0, nil))
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := ExtractTransactionSignature(hex.EncodeToString(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig != nil {
+		t.Fatalf("expected no signature, got %+v", sig)
+	}
+}
+
+func recoverPubKey(t *testing.T, sig *TransactionSignature, hash []byte) *btcec.PublicKey {
+	compact := make([]byte, 65)
+	compact[0] = 27 + sig.RecoveryID
+	copy(compact[33-len(sig.R.Bytes()):33], sig.R.Bytes())
+	copy(compact[65-len(sig.S.Bytes()):], sig.S.Bytes())
+	pubKey, _, err := btcec.RecoverCompact(btcec.S256(), compact, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pubKey
+}
@@ -0,0 +1,54 @@
+package qtum
+
+import (
+	"math"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/qtumproject/janus/pkg/eth"
+)
+
+// syncingHeadersThreshold is how many headers the node may be ahead of its blocks without being considered syncing,
+// a synced node briefly knows the header of the block it is downloading
+const syncingHeadersThreshold = 1
+
+// SyncStatus converts getblockchaininfo into eth_syncing results.
+// Qtum doesn't report the block a sync started from, so the first block seen while syncing is remembered.
+// Its zero value is ready to use
+type SyncStatus struct {
+	mutex         sync.Mutex
+	startingBlock int64
+}
+
+// Update returns the sync status of the node, nil when it has caught up with the network
+func (s *SyncStatus) Update(info *GetBlockChainInfoResponse) *eth.SyncingStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !info.InitialBlockDownload && info.Headers-info.Blocks <= syncingHeadersThreshold {
+		s.startingBlock = 0
+		return nil
+	}
+
+	if s.startingBlock == 0 {
+		s.startingBlock = info.Blocks
+	}
+
+	highestBlock := info.Headers
+	if highestBlock < info.Blocks {
+		highestBlock = info.Blocks
+	}
+	// during the initial block download the headers may still be far behind the network,
+	// the verification progress estimates the share of the chain already downloaded
+	if info.InitialBlockDownload && info.Verificationprogress > 0 && info.Verificationprogress < 1 {
+		if estimated := int64(math.Ceil(float64(info.Blocks) / info.Verificationprogress)); estimated > highestBlock {
+			highestBlock = estimated
+		}
+	}
+
+	return &eth.SyncingStatus{
+		StartingBlock: hexutil.EncodeUint64(uint64(s.startingBlock)),
+		CurrentBlock:  hexutil.EncodeUint64(uint64(info.Blocks)),
+		HighestBlock:  hexutil.EncodeUint64(uint64(highestBlock)),
+	}
+}
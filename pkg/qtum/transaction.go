@@ -0,0 +1,168 @@
+package qtum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/pkg/errors"
+)
+
+// TransactionVersion is the version of the transactions built by Janus
+const TransactionVersion = 2
+
+// TransactionBuilder assembles a transaction spending UTXOs of a single account
+// and signs it locally, without relying on qtumd's wallet
+type TransactionBuilder struct {
+	tx            *wire.MsgTx
+	prevPkScripts [][]byte
+	senderOutputs []senderOutput
+	params        *chaincfg.Params
+}
+
+// senderOutput is a contract output whose OP_SENDER is the signing key
+type senderOutput struct {
+	index          int
+	contractScript []byte
+}
+
+func NewTransactionBuilder(isMain bool) *TransactionBuilder {
+	params := &qtumMainNetParams
+	if !isMain {
+		params = &qtumTestNetParams
+	}
+	return &TransactionBuilder{
+		tx:     wire.NewMsgTx(TransactionVersion),
+		params: params,
+	}
+}
+
+// AddInput spends the output vout of txID, which is locked by prevPkScript
+func (b *TransactionBuilder) AddInput(txID string, vout uint32, prevPkScript []byte) error {
+	hash, err := chainhash.NewHashFromStr(txID)
+	if err != nil {
+		return errors.Wrap(err, "invalid input transaction id")
+	}
+	b.tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, vout), nil, nil))
+	b.prevPkScripts = append(b.prevPkScripts, prevPkScript)
+	return nil
+}
+
+func (b *TransactionBuilder) AddOutput(satoshis int64, pkScript []byte) {
+	b.tx.AddTxOut(wire.NewTxOut(satoshis, pkScript))
+}
+
+// AddSenderOutput adds an OP_CALL or OP_CREATE output sent by the key the transaction is signed with,
+// which doesn't have to own the inputs
+func (b *TransactionBuilder) AddSenderOutput(satoshis int64, contractScript []byte) {
+	b.senderOutputs = append(b.senderOutputs, senderOutput{index: len(b.tx.TxOut), contractScript: contractScript})
+	b.tx.AddTxOut(wire.NewTxOut(satoshis, contractScript))
+}
+
+// Sign signs the OP_SENDER outputs and every input with key, supporting P2PKH and P2PK previous outputs
+func (b *TransactionBuilder) Sign(key *btcutil.WIF) error {
+	// the inputs sign the outputs, so the OP_SENDER signatures come first
+	if err := b.signSenderOutputs(key); err != nil {
+		return err
+	}
+
+	lookupKey := txscript.KeyClosure(func(btcutil.Address) (*btcec.PrivateKey, bool, error) {
+		return key.PrivKey, key.CompressPubKey, nil
+	})
+
+	for i, prevPkScript := range b.prevPkScripts {
+		sigScript, err := txscript.SignTxOutput(b.params, b.tx, i, prevPkScript, txscript.SigHashAll, lookupKey, nil, nil)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't sign input %d", i)
+		}
+		b.tx.TxIn[i].SignatureScript = sigScript
+	}
+
+	return nil
+}
+
+func (b *TransactionBuilder) signSenderOutputs(key *btcutil.WIF) error {
+	pubKey := key.SerializePubKey()
+	pubKeyHash := btcutil.Hash160(pubKey)
+	for _, output := range b.senderOutputs {
+		unsigned, err := ContractSenderScript(pubKeyHash, nil, output.contractScript)
+		if err != nil {
+			return err
+		}
+		b.tx.TxOut[output.index].PkScript = unsigned
+	}
+
+	// every OP_SENDER signature is stripped from the signed hash, so the outputs are signed in any order
+	for _, output := range b.senderOutputs {
+		hash, err := SenderSignatureHash(b.tx, output.index, txscript.SigHashAll)
+		if err != nil {
+			return err
+		}
+		sig, err := key.PrivKey.Sign(hash)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't sign output %d", output.index)
+		}
+		scriptSig, err := txscript.NewScriptBuilder().
+			AddData(append(sig.Serialize(), byte(txscript.SigHashAll))).
+			AddData(pubKey).
+			Script()
+		if err != nil {
+			return err
+		}
+		signed, err := ContractSenderScript(pubKeyHash, scriptSig, output.contractScript)
+		if err != nil {
+			return err
+		}
+		b.tx.TxOut[output.index].PkScript = signed
+	}
+	return nil
+}
+
+// SenderSignatureHash returns the hash signed by the OP_SENDER of output outIndex. As qtumd's SignatureHashOutput,
+// it hashes the transaction with empty input scripts and the OP_SENDER signatures of its outputs stripped, followed
+// by the hash type. Only SIGHASH_ALL is supported
+func SenderSignatureHash(tx *wire.MsgTx, outIndex int, hashType txscript.SigHashType) ([]byte, error) {
+	if outIndex < 0 || outIndex >= len(tx.TxOut) {
+		return nil, errors.Errorf("transaction has no output %d", outIndex)
+	}
+	if _, ok := withoutSenderSig(tx.TxOut[outIndex].PkScript); !ok {
+		return nil, errors.Errorf("output %d has no OP_SENDER", outIndex)
+	}
+	if hashType != txscript.SigHashAll {
+		return nil, errors.Errorf("unsupported OP_SENDER signature hash type %#x", hashType)
+	}
+
+	txCopy := tx.Copy()
+	for _, in := range txCopy.TxIn {
+		in.SignatureScript = nil
+		in.Witness = nil
+	}
+	for _, out := range txCopy.TxOut {
+		if script, ok := withoutSenderSig(out.PkScript); ok {
+			out.PkScript = script
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := txCopy.SerializeNoWitness(&buf); err != nil {
+		return nil, errors.Wrap(err, "couldn't serialize transaction")
+	}
+	hashTypeBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(hashTypeBytes, uint32(hashType))
+	buf.Write(hashTypeBytes)
+	return chainhash.DoubleHashB(buf.Bytes()), nil
+}
+
+func (b *TransactionBuilder) Hex() (string, error) {
+	var buf bytes.Buffer
+	if err := b.tx.Serialize(&buf); err != nil {
+		return "", errors.Wrap(err, "couldn't serialize transaction")
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
@@ -0,0 +1,154 @@
+package qtum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+func TestContractScripts(t *testing.T) {
+	contract, _ := hex.DecodeString("1286d4ca8ad4c3d3a9b3c1d5e3c4b5f5a6d7e8f9")
+
+	script, err := ContractCallScript(contract, []byte{0xa9, 0x05, 0x9c, 0xbb}, 2500000, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "010403a02526012804a9059cbb141286d4ca8ad4c3d3a9b3c1d5e3c4b5f5a6d7e8f9c2"
+	if hex.EncodeToString(script) != want {
+		t.Fatalf("call script %x, want %s", script, want)
+	}
+
+	byteCode := bytes.Repeat([]byte{0x60}, 0x151)
+	script, err = ContractCreateScript(byteCode, 2500000, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the prefix matches the OP_CREATE outputs built by qtumd
+	wantPrefix := "010403a0252601284d5101"
+	if hex.EncodeToString(script[:len(wantPrefix)/2]) != wantPrefix || script[len(script)-1] != OpCreate {
+		t.Fatalf("unexpected create script %x", script)
+	}
+}
+
+func TestTransactionBuilderSignsP2PKHInputs(t *testing.T) {
+	key, err := btcutil.DecodeWIF("5JK4Gu9nxCvsCxiq9Zf3KdmA9ACza6dUn5BRLVWAYEtQabdnJ89")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevPkScript, err := PayToPubKeyHashScript(btcutil.Hash160(key.SerializePubKey()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := NewTransactionBuilder(false)
+	if err := builder.AddInput("7e1f6e0a2a0ad7e9c0bd0dff3e0a3a1a0a6a2a1f7b4f0c0e2b9a6b1d7e2c3a4b", 1, prevPkScript); err != nil {
+		t.Fatal(err)
+	}
+	builder.AddOutput(99000000, prevPkScript)
+	if err := builder.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+
+	rawTx, err := builder.Hex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serialized, _ := hex.DecodeString(rawTx)
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(serialized)); err != nil {
+		t.Fatal(err)
+	}
+
+	if estimated := EstimateTransactionSize(1, false, prevPkScript); estimated < len(serialized) || estimated > len(serialized)+2 {
+		t.Fatalf("estimated size %d, signed transaction is %d bytes", estimated, len(serialized))
+	}
+
+	vm, err := txscript.NewEngine(prevPkScript, &tx, 0, txscript.StandardVerifyFlags, nil, nil, 100000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Fatalf("signature doesn't verify: %s", err)
+	}
+}
+
+func TestTransactionBuilderSignsSenderOutputs(t *testing.T) {
+	key, err := btcutil.DecodeWIF("5JK4Gu9nxCvsCxiq9Zf3KdmA9ACza6dUn5BRLVWAYEtQabdnJ89")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKeyHash := btcutil.Hash160(key.SerializePubKey())
+	prevPkScript, err := PayToPubKeyHashScript(pubKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract, _ := hex.DecodeString("1286d4ca8ad4c3d3a9b3c1d5e3c4b5f5a6d7e8f9")
+	callScript, err := ContractCallScript(contract, []byte{0xa9, 0x05, 0x9c, 0xbb}, 2500000, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := NewTransactionBuilder(false)
+	if err := builder.AddInput("7e1f6e0a2a0ad7e9c0bd0dff3e0a3a1a0a6a2a1f7b4f0c0e2b9a6b1d7e2c3a4b", 1, prevPkScript); err != nil {
+		t.Fatal(err)
+	}
+	builder.AddSenderOutput(0, callScript)
+	builder.AddOutput(89000000, prevPkScript)
+	if err := builder.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+
+	rawTx, err := builder.Hex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serialized, _ := hex.DecodeString(rawTx)
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(serialized)); err != nil {
+		t.Fatal(err)
+	}
+
+	// <address type> <pubKeyHash> <scriptSig> OP_SENDER <call script>
+	pkScript := tx.TxOut[0].PkScript
+	unsigned, err := ContractSenderScript(pubKeyHash, nil, callScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stripped, ok := withoutSenderSig(pkScript); !ok || !bytes.Equal(stripped, unsigned) {
+		t.Fatalf("expected %x once the signature is stripped, got %x", unsigned, stripped)
+	}
+	// the address type is pushed as data, as qtumd does
+	wantPrefix := "010114" + hex.EncodeToString(pubKeyHash)
+	if hex.EncodeToString(unsigned[:len(wantPrefix)/2]) != wantPrefix || !bytes.HasSuffix(pkScript, append([]byte{OpSender}, callScript...)) {
+		t.Fatalf("unexpected sender script %x", pkScript)
+	}
+
+	senderPubKeyHash, scriptSig, ok := senderScriptSig(pkScript)
+	if !ok || !bytes.Equal(senderPubKeyHash, pubKeyHash) {
+		t.Fatal("expected an OP_SENDER script signature")
+	}
+	sig, hashType, pubKey, ok := parseSignatureScript(scriptSig)
+	if !ok || !pubKey.IsEqual(key.PrivKey.PubKey()) {
+		t.Fatal("expected the script signature of the signing key")
+	}
+	hash, err := SenderSignatureHash(&tx, 0, hashType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sig.Verify(hash, pubKey) {
+		t.Fatal("OP_SENDER signature doesn't verify")
+	}
+
+	// the input signs the signed OP_SENDER output
+	vm, err := txscript.NewEngine(prevPkScript, &tx, 0, txscript.StandardVerifyFlags, nil, nil, 100000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Fatalf("input signature doesn't verify: %s", err)
+	}
+}
@@ -0,0 +1,171 @@
+package qtum
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// longPollMethods block in qtumd until something happens, the request timeout doesn't apply to them
+var longPollMethods = map[string]bool{
+	MethodWaitForLogs: true,
+}
+
+// TransportConfig tunes the HTTP connections to qtumd
+type TransportConfig struct {
+	// MaxIdleConns limits the idle connections kept open to all backends, 0 means no limit
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits the idle connections kept open to a backend,
+	// it should be at least the number of requests sent concurrently to avoid reconnecting
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits the connections open to a backend, 0 means no limit
+	MaxConnsPerHost int
+	// IdleConnTimeout closes connections idle for that long
+	IdleConnTimeout time.Duration
+	// DialTimeout limits the time to establish a TCP connection
+	DialTimeout time.Duration
+	// KeepAlive is the period of TCP keep-alive probes
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout limits the time of the TLS handshake to https backends
+	TLSHandshakeTimeout time.Duration
+	// RequestTimeout limits the time of a request to a backend, long polling methods excepted. 0 means no limit
+	RequestTimeout time.Duration
+}
+
+// DefaultTransportConfig keeps enough connections open for a block with many transactions to be fetched without reconnecting
+var DefaultTransportConfig = TransportConfig{
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 64,
+	MaxConnsPerHost:     0,
+	IdleConnTimeout:     90 * time.Second,
+	DialTimeout:         5 * time.Second,
+	KeepAlive:           30 * time.Second,
+	TLSHandshakeTimeout: 5 * time.Second,
+	RequestTimeout:      60 * time.Second,
+}
+
+func (config TransportConfig) validate() error {
+	if config.MaxIdleConns < 0 || config.MaxIdleConnsPerHost < 0 || config.MaxConnsPerHost < 0 {
+		return errors.New("connection limits must not be negative")
+	}
+	if config.IdleConnTimeout < 0 || config.DialTimeout < 0 || config.KeepAlive < 0 || config.TLSHandshakeTimeout < 0 || config.RequestTimeout < 0 {
+		return errors.New("connection timeouts must not be negative")
+	}
+	return nil
+}
+
+// PoolStats describes the connections to qtumd
+type PoolStats struct {
+	// connections currently open
+	Open int64 `json:"open"`
+	// requests currently waiting for a response
+	Active int64 `json:"active"`
+	// requests sent since start
+	Requests uint64 `json:"requests"`
+	// connections opened since start
+	Dialed uint64 `json:"dialed"`
+	// requests sent on a connection kept alive since start
+	Reused uint64 `json:"reused"`
+}
+
+// poolStats is updated atomically, keep the 64 bit fields first for alignment
+type poolStats struct {
+	open     int64
+	active   int64
+	requests uint64
+	dialed   uint64
+	reused   uint64
+}
+
+func (s *poolStats) snapshot() PoolStats {
+	return PoolStats{
+		Open:     atomic.LoadInt64(&s.open),
+		Active:   atomic.LoadInt64(&s.active),
+		Requests: atomic.LoadUint64(&s.requests),
+		Dialed:   atomic.LoadUint64(&s.dialed),
+		Reused:   atomic.LoadUint64(&s.reused),
+	}
+}
+
+// trace counts the requests sent on kept alive connections
+func (s *poolStats) trace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				atomic.AddUint64(&s.reused, 1)
+			}
+		},
+	})
+}
+
+// countedConn decrements the open connections once closed
+type countedConn struct {
+	net.Conn
+	once  sync.Once
+	stats *poolStats
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(func() {
+		atomic.AddInt64(&c.stats.open, -1)
+	})
+	return c.Conn.Close()
+}
+
+func newTransport(config TransportConfig, stats *poolStats) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   config.DialTimeout,
+		KeepAlive: config.KeepAlive,
+	}
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, address)
+			if err != nil {
+				return nil, err
+			}
+			atomic.AddUint64(&stats.dialed, 1)
+			atomic.AddInt64(&stats.open, 1)
+			return &countedConn{Conn: conn, stats: stats}, nil
+		},
+		MaxIdleConns:        config.MaxIdleConns,
+		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     config.MaxConnsPerHost,
+		IdleConnTimeout:     config.IdleConnTimeout,
+		TLSHandshakeTimeout: config.TLSHandshakeTimeout,
+	}
+}
+
+// SetTransport tunes the connections to qtumd, they are kept alive and shared by the requests
+func SetTransport(config TransportConfig) func(*Client) error {
+	return func(c *Client) error {
+		if err := config.validate(); err != nil {
+			return err
+		}
+		c.doer = &http.Client{Transport: newTransport(config, c.pool)}
+		c.requestTimeout = config.RequestTimeout
+		return nil
+	}
+}
+
+// PoolStats returns the state of the connections to qtumd
+func (c *Client) PoolStats() PoolStats {
+	return c.pool.snapshot()
+}
+
+// requestContext bounds a request to a backend by the request timeout
+func (c *Client) requestContext(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.requestTimeout <= 0 || longPollMethods[method] {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.requestTimeout)
+}
@@ -0,0 +1,272 @@
+package qtum
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/pkg/errors"
+)
+
+// Topics published by qtumd with the -zmqpub<topic>=<endpoint> options
+const (
+	ZMQTopicHashBlock = "hashblock"
+	ZMQTopicHashTx    = "hashtx"
+	ZMQTopicRawTx     = "rawtx"
+)
+
+var zmqTopicEvents = map[string]string{
+	ZMQTopicHashBlock: EventBlock,
+	ZMQTopicHashTx:    EventTransaction,
+	ZMQTopicRawTx:     EventTransaction,
+}
+
+const (
+	zmqDialTimeout = 10 * time.Second
+	// zmqMaxFrameSize bounds the memory used by a frame, a raw transaction can't exceed the block size
+	zmqMaxFrameSize = 64 * 1024 * 1024
+
+	zmtpFlagMore    = 0x01
+	zmtpFlagLong    = 0x02
+	zmtpFlagCommand = 0x04
+)
+
+// ZMQSubscriber receives the notifications of qtumd's ZMQ publisher and publishes them as events.
+//
+// It speaks the subset of ZMTP 3.0 (https://rfc.zeromq.org/spec/23/) needed by a SUB socket
+// without security, which is what qtumd offers
+type ZMQSubscriber struct {
+	client   *Client
+	endpoint string
+	topics   []string
+}
+
+// NewZMQSubscriber returns a subscriber to the topics published on a tcp://host:port endpoint
+func NewZMQSubscriber(client *Client, endpoint string, topics ...string) (*ZMQSubscriber, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "tcp" || u.Host == "" {
+		return nil, errors.Errorf("invalid ZMQ endpoint, expected tcp://host:port: %s", endpoint)
+	}
+	for _, topic := range topics {
+		if _, ok := zmqTopicEvents[topic]; !ok {
+			return nil, errors.Errorf("unsupported ZMQ topic: %s", topic)
+		}
+	}
+
+	return &ZMQSubscriber{
+		client:   client,
+		endpoint: u.Host,
+		topics:   topics,
+	}, nil
+}
+
+// Run keeps receiving notifications until ctx is done, reconnecting to qtumd when the connection is lost
+func (s *ZMQSubscriber) Run(ctx context.Context) {
+	for failures := 0; ; failures++ {
+		err := s.receive(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		backoffTime := computeBackoff(failures, s.client.backoff.MaxBackoff, true)
+		s.client.GetErrorLogger().Log("component", "ZMQSubscriber", "endpoint", s.endpoint, "msg", "ZMQ connection lost, polling qtumd until it is back", "err", err, "retry", backoffTime)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoffTime):
+		}
+	}
+}
+
+func (s *ZMQSubscriber) receive(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: zmqDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.endpoint)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	reader := bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(zmqDialTimeout))
+	if err := zmtpHandshake(conn, reader, "SUB"); err != nil {
+		return errors.Wrap(err, "ZMTP handshake")
+	}
+	for _, topic := range s.topics {
+		if err := zmtpWriteFrame(conn, 0, append([]byte{0x01}, topic...)); err != nil {
+			return err
+		}
+	}
+	conn.SetDeadline(time.Time{})
+
+	for _, topic := range s.topics {
+		s.client.events.SetPushed(zmqTopicEvents[topic], true)
+		defer s.client.events.SetPushed(zmqTopicEvents[topic], false)
+	}
+	s.client.GetDebugLogger().Log("component", "ZMQSubscriber", "endpoint", s.endpoint, "msg", "subscribed to qtumd notifications", "topics", len(s.topics))
+
+	for {
+		message, err := zmtpReadMessage(reader)
+		if err != nil {
+			return err
+		}
+		// notifications are made of the topic, the body and a sequence number
+		if len(message) < 2 {
+			continue
+		}
+
+		event, err := zmqEvent(string(message[0]), message[1])
+		if err != nil {
+			s.client.GetErrorLogger().Log("component", "ZMQSubscriber", "msg", "invalid ZMQ notification", "topic", string(message[0]), "err", err)
+			continue
+		}
+		if event != nil {
+			s.client.events.Publish(*event)
+		}
+	}
+}
+
+func zmqEvent(topic string, body []byte) (*Event, error) {
+	switch topic {
+	case ZMQTopicHashBlock, ZMQTopicHashTx:
+		// hashes are published in the byte order displayed by the RPC interface
+		return &Event{Type: zmqTopicEvents[topic], Hash: hex.EncodeToString(body)}, nil
+	case ZMQTopicRawTx:
+		var tx wire.MsgTx
+		if err := tx.Deserialize(bytes.NewReader(body)); err != nil {
+			return nil, err
+		}
+		return &Event{Type: EventTransaction, Hash: tx.TxHash().String()}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// zmtpHandshake exchanges the greetings and READY commands of the NULL security mechanism
+func zmtpHandshake(conn io.Writer, reader io.Reader, socketType string) error {
+	greeting := make([]byte, 64)
+	greeting[0] = 0xff
+	greeting[9] = 0x7f
+	// version 3.0
+	greeting[10] = 3
+	greeting[11] = 0
+	copy(greeting[12:32], "NULL")
+	if _, err := conn.Write(greeting); err != nil {
+		return err
+	}
+
+	peerGreeting := make([]byte, 64)
+	if _, err := io.ReadFull(reader, peerGreeting); err != nil {
+		return err
+	}
+	if peerGreeting[0] != 0xff || peerGreeting[9] != 0x7f || peerGreeting[10] < 3 {
+		return errors.New("peer doesn't speak ZMTP 3")
+	}
+	if mechanism := string(bytes.TrimRight(peerGreeting[12:32], "\x00")); mechanism != "NULL" {
+		return errors.Errorf("unsupported security mechanism: %s", mechanism)
+	}
+
+	if err := zmtpWriteFrame(conn, zmtpFlagCommand, zmtpReadyCommand(socketType)); err != nil {
+		return err
+	}
+
+	flags, body, err := zmtpReadFrame(reader)
+	if err != nil {
+		return err
+	}
+	if flags&zmtpFlagCommand == 0 || len(body) < 6 || string(body[1:6]) != "READY" {
+		return errors.New("expected READY command")
+	}
+	return nil
+}
+
+func zmtpReadyCommand(socketType string) []byte {
+	var command bytes.Buffer
+	command.WriteByte(5)
+	command.WriteString("READY")
+	command.WriteByte(byte(len("Socket-Type")))
+	command.WriteString("Socket-Type")
+	binary.Write(&command, binary.BigEndian, uint32(len(socketType)))
+	command.WriteString(socketType)
+	return command.Bytes()
+}
+
+func zmtpWriteFrame(w io.Writer, flags byte, body []byte) error {
+	var header []byte
+	if len(body) > 255 {
+		header = make([]byte, 9)
+		header[0] = flags | zmtpFlagLong
+		binary.BigEndian.PutUint64(header[1:], uint64(len(body)))
+	} else {
+		header = []byte{flags, byte(len(body))}
+	}
+	_, err := w.Write(append(header, body...))
+	return err
+}
+
+func zmtpReadFrame(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	flags := header[0]
+
+	var size uint64
+	if flags&zmtpFlagLong != 0 {
+		sizeBytes := make([]byte, 8)
+		if _, err := io.ReadFull(r, sizeBytes); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(sizeBytes)
+	} else {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return 0, nil, err
+		}
+		size = uint64(header[0])
+	}
+	if size > zmqMaxFrameSize {
+		return 0, nil, errors.Errorf("ZMTP frame of %d bytes is too large", size)
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
+
+// zmtpReadMessage returns the frames of the next message, skipping commands
+func zmtpReadMessage(r io.Reader) ([][]byte, error) {
+	var message [][]byte
+	for {
+		flags, body, err := zmtpReadFrame(r)
+		if err != nil {
+			return nil, err
+		}
+		if flags&zmtpFlagCommand != 0 {
+			continue
+		}
+		message = append(message, body)
+		if flags&zmtpFlagMore == 0 {
+			return message, nil
+		}
+	}
+}
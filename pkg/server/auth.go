@@ -0,0 +1,192 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/eth"
+)
+
+const headerAPIKey = "X-API-Key"
+
+// AuthConfig lists the credentials accepted by janus, requests are only authenticated when some are set
+type AuthConfig struct {
+	// APIKeys map the keys to the methods they may call, a key without methods may call any method
+	APIKeys map[string][]string
+	// JWTSecret verifies HS256 tokens
+	JWTSecret []byte
+	// JWTPublicKey verifies ES256 tokens
+	JWTPublicKey *ecdsa.PublicKey
+}
+
+func (config AuthConfig) enabled() bool {
+	return len(config.APIKeys) != 0 || len(config.JWTSecret) != 0 || config.JWTPublicKey != nil
+}
+
+// SetAuth requires the eth RPC requests and websocket connections to carry one of the configured credentials
+func SetAuth(config AuthConfig) Option {
+	return func(p *Server) error {
+		for key := range config.APIKeys {
+			if key == "" {
+				return errors.New("API keys must not be empty")
+			}
+		}
+		p.auth = config
+		return nil
+	}
+}
+
+// authClaims are the claims of the JWTs, Methods restricts the methods the bearer may call
+type authClaims struct {
+	jwt.RegisteredClaims
+	Methods []string `json:"methods,omitempty"`
+}
+
+// Valid requires tokens to expire and to have a subject, which identifies the client to the rate limits
+func (c authClaims) Valid() error {
+	if c.ExpiresAt == nil {
+		return errors.New("token has no expiry")
+	}
+	if c.Subject == "" {
+		return errors.New("token has no subject")
+	}
+	return c.RegisteredClaims.Valid()
+}
+
+// permissions restrict the methods a client may call, nil permissions allow every method
+type permissions struct {
+	methods []string
+}
+
+func newPermissions(methods []string) *permissions {
+	if len(methods) == 0 {
+		return nil
+	}
+	return &permissions{methods: methods}
+}
+
+// allows the method if it is listed, or starts with the prefix of an entry ending with *
+func (p *permissions) allows(method string) bool {
+	if p == nil {
+		return true
+	}
+	for _, allowed := range p.methods {
+		if allowed == method || (strings.HasSuffix(allowed, "*") && strings.HasPrefix(method, strings.TrimSuffix(allowed, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func errMethodNotAllowed(method string) *eth.JSONRPCError {
+	return &eth.JSONRPCError{
+		Code:    -32601,
+		Message: fmt.Sprintf("the method %s is not allowed", method),
+	}
+}
+
+var errUnauthorized = &eth.JSONRPCError{
+	Code:    -32000,
+	Message: "unauthorized",
+}
+
+// authMiddleware authenticates the eth RPC requests, including the websocket upgrades and batches, with an API key
+// or a JWT sent as a bearer token, in the X-API-Key header or as the path of the URL (e.g. https://janus/KEY)
+func (s *Server) authMiddleware(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// monitoring endpoints are left open for load balancers and scrapers
+		if !s.auth.enabled() || c.Path() != "/*" {
+			return h(c)
+		}
+
+		cc, ok := c.Get("myctx").(*myCtx)
+		if !ok {
+			return errors.New("Could not find myctx")
+		}
+
+		perms, clientID, err := s.authenticate(credentials(c))
+		if err != nil {
+			cc.GetDebugLogger().Log("msg", "request not authenticated", "err", err)
+			return c.JSON(http.StatusUnauthorized, cc.GetJSONRPCError(errUnauthorized))
+		}
+		cc.permissions = perms
+		cc.clientID = clientID
+
+		return h(c)
+	}
+}
+
+func credentials(c echo.Context) []string {
+	var credentials []string
+	if bearer := c.Request().Header.Get(echo.HeaderAuthorization); strings.HasPrefix(bearer, "Bearer ") {
+		credentials = append(credentials, strings.TrimPrefix(bearer, "Bearer "))
+	}
+	if key := c.Request().Header.Get(headerAPIKey); key != "" {
+		credentials = append(credentials, key)
+	}
+	if path := strings.Trim(c.Param("*"), "/"); path != "" {
+		credentials = append(credentials, path)
+	}
+	return credentials
+}
+
+// authenticate returns the permissions of the first valid credential, along with the identity of the client,
+// that is the API key or the subject of the JWT
+func (s *Server) authenticate(credentials []string) (*permissions, string, error) {
+	if len(credentials) == 0 {
+		return nil, "", errors.New("missing credentials")
+	}
+
+	var err error
+	for _, credential := range credentials {
+		if methods, ok := s.apiKeyMethods(credential); ok {
+			return newPermissions(methods), "key:" + credential, nil
+		}
+
+		var claims authClaims
+		if _, err = s.jwtParser().ParseWithClaims(credential, &claims, s.jwtKey); err == nil {
+			return newPermissions(claims.Methods), "sub:" + claims.Subject, nil
+		}
+	}
+	return nil, "", errors.Wrap(err, "invalid credentials")
+}
+
+func (s *Server) apiKeyMethods(credential string) ([]string, bool) {
+	for key, methods := range s.auth.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(credential)) == 1 {
+			return methods, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) jwtParser() *jwt.Parser {
+	var methods []string
+	if len(s.auth.JWTSecret) != 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if s.auth.JWTPublicKey != nil {
+		methods = append(methods, jwt.SigningMethodES256.Alg())
+	}
+	return jwt.NewParser(jwt.WithValidMethods(methods))
+}
+
+func (s *Server) jwtKey(token *jwt.Token) (interface{}, error) {
+	switch token.Method {
+	case jwt.SigningMethodHS256:
+		if len(s.auth.JWTSecret) != 0 {
+			return s.auth.JWTSecret, nil
+		}
+	case jwt.SigningMethodES256:
+		if s.auth.JWTPublicKey != nil {
+			return s.auth.JWTPublicKey, nil
+		}
+	}
+	return nil, errors.Errorf("unexpected signing method %s", token.Header["alg"])
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/transformer"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	secret := []byte("secret")
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims authClaims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	registered := jwt.RegisteredClaims{Subject: "client", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+	valid := authClaims{RegisteredClaims: registered}
+	readOnly := authClaims{RegisteredClaims: registered, Methods: []string{"eth_get*"}}
+	expired := authClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "client", ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))}}
+	noExpiry := authClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "client"}}
+	noSubject := authClaims{RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: registered.ExpiresAt}}
+
+	qtumClient, err := internal.CreateMockedClient(internal.NewDoerMappedMock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	transformer, err := transformer.New(qtumClient, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New(qtumClient, transformer, "", SetAuth(AuthConfig{
+		APIKeys:      map[string][]string{"admin": nil, "public": {"eth_blockNumber", "eth_call"}},
+		JWTSecret:    secret,
+		JWTPublicKey: &ecdsaKey.PublicKey,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		header  string
+		value   string
+		status  int
+		allowed []string
+		denied  []string
+	}{
+		{name: "no credentials", status: http.StatusUnauthorized},
+		{name: "unknown key", header: headerAPIKey, value: "unknown", status: http.StatusUnauthorized},
+		{name: "key in header", header: headerAPIKey, value: "admin", status: http.StatusOK, allowed: []string{"eth_sign"}},
+		{name: "key in path", path: "public", status: http.StatusOK, allowed: []string{"eth_call"}, denied: []string{"eth_sign"}},
+		{name: "key as bearer", header: echo.HeaderAuthorization, value: "Bearer public", status: http.StatusOK, allowed: []string{"eth_blockNumber"}},
+		{
+			name:    "HS256 token",
+			header:  echo.HeaderAuthorization,
+			value:   "Bearer " + sign(jwt.SigningMethodHS256, secret, readOnly),
+			status:  http.StatusOK,
+			allowed: []string{"eth_getBalance", "eth_getLogs"},
+			denied:  []string{"eth_sendTransaction"},
+		},
+		{name: "ES256 token in path", path: sign(jwt.SigningMethodES256, ecdsaKey, valid), status: http.StatusOK, allowed: []string{"eth_sign"}},
+		{name: "expired token", header: echo.HeaderAuthorization, value: "Bearer " + sign(jwt.SigningMethodHS256, secret, expired), status: http.StatusUnauthorized},
+		{name: "token without expiry", header: echo.HeaderAuthorization, value: "Bearer " + sign(jwt.SigningMethodHS256, secret, noExpiry), status: http.StatusUnauthorized},
+		{name: "token without subject", header: echo.HeaderAuthorization, value: "Bearer " + sign(jwt.SigningMethodHS256, secret, noSubject), status: http.StatusUnauthorized},
+		{name: "wrong secret", header: echo.HeaderAuthorization, value: "Bearer " + sign(jwt.SigningMethodHS256, []byte("other"), valid), status: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(echo.POST, "/"+test.path, nil)
+			if test.header != "" {
+				req.Header.Set(test.header, test.value)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/*")
+			c.SetParamNames("*")
+			c.SetParamValues(test.path)
+			cc := &myCtx{Context: c, logger: log.NewNopLogger(), transformer: transformer}
+			c.Set("myctx", cc)
+
+			err := s.authMiddleware(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != test.status {
+				t.Fatalf("expected status %d, got %d", test.status, rec.Code)
+			}
+			for _, method := range test.allowed {
+				if !cc.permissions.allows(method) {
+					t.Errorf("expected %s to be allowed", method)
+				}
+			}
+			for _, method := range test.denied {
+				if cc.permissions.allows(method) {
+					t.Errorf("expected %s to be denied", method)
+				}
+			}
+		})
+	}
+}
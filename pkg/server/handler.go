@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	stdLog "log"
@@ -11,6 +12,7 @@ import (
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/metrics"
 	"github.com/qtumproject/janus/pkg/notifier"
 	"github.com/qtumproject/janus/pkg/qtum"
 
@@ -33,23 +35,30 @@ func httpHandler(c echo.Context) error {
 
 	cc.rpcReq = rpcReq
 
+	if wait, ok := cc.rateLimiter.allow(cc.rateLimitClient(), rpcReq.Method); !ok {
+		throttled := errLimitExceeded("rate limit exceeded")
+		observeRequest(cc.transformer, rpcReq.Method, start, throttled, nil)
+		c.Response().Header().Set("Retry-After", retryAfter(wait))
+		return c.JSON(http.StatusTooManyRequests, cc.GetJSONRPCError(throttled))
+	}
+
 	// level.Debug(cc.logger).Log("msg", "before call transformer#Transform")
-	result, err := cc.transformer.Transform(rpcReq, c)
+	var result interface{}
+	var err error
+	if cc.permissions.allows(rpcReq.Method) {
+		// the request context is cancelled when the client goes away
+		result, err = cc.transformer.Transform(c.Request().Context(), rpcReq, c)
+	} else {
+		result = errMethodNotAllowed(rpcReq.Method)
+	}
 	// level.Debug(cc.logger).Log("msg", "after call transformer#Transform")
 
 	cc.GetLogger().Log("msg", "proxy RPC", "method", rpcReq.Method, "time", time.Since(start).String())
+	observeRequest(cc.transformer, rpcReq.Method, start, result, err)
 
 	if err != nil {
-		err1 := errors.Cause(err)
-		if err != err1 {
-			cc.GetErrorLogger().Log("err", err.Error())
-			return cc.JSONRPCError(&eth.JSONRPCError{
-				Code:    100,
-				Message: err1.Error(),
-			})
-		}
-
-		return err
+		cc.GetErrorLogger().Log("err", err.Error())
+		return cc.JSONRPCError(causeToJSONRPCError(errors.Cause(err)))
 	}
 
 	// Allow transformer to return an explicit JSON error
@@ -191,11 +200,9 @@ func httpHandler(c echo.Context) error {
    "result": {
       "syncing": true,
       "status": {
-         "startingBlock": 674427,
-         "currentBlock": 67400,
-         "highestBlock": 674432,
-         "pulledStates": 0,
-         "knownStates": 0
+         "startingBlock": "0xa4a7b",
+         "currentBlock": "0xa4a7c",
+         "highestBlock": "0xa4a80"
       }
    }
 }
@@ -221,6 +228,17 @@ func websocketHandler(c echo.Context) error {
 		return errors.New("Could not find myctx")
 	}
 
+	client := cc.rateLimitClient()
+	if !cc.rateLimiter.openConnection(client) {
+		return c.JSON(http.StatusTooManyRequests, cc.GetJSONRPCError(errLimitExceeded("websocket connection limit exceeded")))
+	}
+	defer cc.rateLimiter.closeConnection(client)
+	// subscriptions end along with the connection
+	subscriptions := 0
+	defer func() {
+		cc.rateLimiter.removeSubscriptions(client, subscriptions)
+	}()
+
 	h := http.Header{}
 	for _, sub := range websocket.Subprotocols(c.Request()) {
 		// pick first websocket protocol client asks for if they ask
@@ -233,14 +251,16 @@ func websocketHandler(c echo.Context) error {
 	} else {
 		cc.GetDebugLogger().Log("msg", "Got websocket request")
 	}
+	// the request context isn't cancelled once the connection is hijacked, requests of the connection are cancelled on close
+	ctx, cancel := context.WithCancel(c.Request().Context())
 	closeOnce := sync.Once{}
 	close := func() {
 		closeOnce.Do(func() {
+			cancel()
 			ws.Close()
 		})
 	}
 
-	ctx := c.Request().Context()
 	var writeMutex sync.Mutex
 	stopPingPong := pingPong(ctx, ws, &writeMutex)
 	send := func(value []byte) error {
@@ -254,9 +274,11 @@ func websocketHandler(c echo.Context) error {
 	defer func() {
 		stopPingPong()
 		close()
+		metrics.WebsocketClosed()
 		cc.GetDebugLogger().Log("msg", "Websocket connection closed")
 	}()
 
+	metrics.WebsocketOpened()
 	cc.GetDebugLogger().Log("msg", "Websocket connection opened")
 
 	notifier := notifier.NewNotifier(
@@ -281,19 +303,22 @@ func websocketHandler(c echo.Context) error {
 
 		cc.rpcReq = &rpcReq
 
-		result, err := cc.transformer.Transform(&rpcReq, c)
+		start := time.Now()
+		var result interface{}
+		if cc.permissions.allows(rpcReq.Method) {
+			result, err = limitWebsocketRequest(cc, rpcReq.Method, &subscriptions, func() (interface{}, error) {
+				return cc.transformer.Transform(ctx, &rpcReq, c)
+			})
+		} else {
+			result = errMethodNotAllowed(rpcReq.Method)
+		}
+		observeRequest(cc.transformer, rpcReq.Method, start, result, err)
 
 		response := result
 
 		if err != nil {
-			err1 := errors.Cause(err)
-			if err != err1 {
-				cc.GetErrorLogger().Log("err", err.Error())
-				response = cc.GetJSONRPCError(&eth.JSONRPCError{
-					Code:    100,
-					Message: err1.Error(),
-				})
-			}
+			cc.GetErrorLogger().Log("err", err.Error())
+			response = causeToJSONRPCError(errors.Cause(err))
 		}
 
 		// Allow transformer to return an explicit JSON error
@@ -336,6 +361,17 @@ func websocketHandler(c echo.Context) error {
 	}
 }
 
+// causeToJSONRPCError keeps the code of errors that already are JSON-RPC errors, like those of an unavailable qtumd
+func causeToJSONRPCError(cause error) *eth.JSONRPCError {
+	if jerr, ok := cause.(*eth.JSONRPCError); ok {
+		return jerr
+	}
+	return &eth.JSONRPCError{
+		Code:    100,
+		Message: cause.Error(),
+	}
+}
+
 func errorHandler(err error, c echo.Context) {
 	myctx := c.Get("myctx")
 	cc, ok := myctx.(*myCtx)
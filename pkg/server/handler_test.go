@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/transformer"
+)
+
+type failingProxy struct {
+	method string
+	err    error
+}
+
+func (p *failingProxy) Method() string {
+	return p.method
+}
+
+func (p *failingProxy) Request(context.Context, *eth.JSONRPCRequest, echo.Context) (interface{}, error) {
+	return nil, p.err
+}
+
+func TestHttpHandlerErrorCodes(t *testing.T) {
+	qtumClient, err := internal.CreateMockedClient(internal.NewDoerMappedMock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	transformer, err := transformer.New(qtumClient, []transformer.ETHProxy{
+		&failingProxy{method: "eth_open", err: qtum.ErrCircuitOpen},
+		&failingProxy{method: "eth_overloaded", err: errors.WithMessage(qtum.ErrOverloaded, "couldn't get block count")},
+		&failingProxy{method: "eth_failed", err: errors.New("failed")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for method, code := range map[string]int{"eth_open": -32002, "eth_overloaded": -32010, "eth_failed": 100} {
+		e := echo.New()
+		req := httptest.NewRequest(echo.POST, "/", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"`+method+`"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("myctx", &myCtx{Context: c, logger: log.NewNopLogger(), transformer: transformer})
+		if err := httpHandler(c); err != nil {
+			t.Fatal(err)
+		}
+
+		var result eth.JSONRPCResult
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Error == nil || result.Error.Code != code {
+			t.Errorf("%s: expected error code %d, got %s", method, code, rec.Body.String())
+		}
+	}
+}
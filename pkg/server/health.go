@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+// ReadinessConfig decides when the qtumd node behind janus is ready to serve requests
+type ReadinessConfig struct {
+	// MinimumPeers connected to qtumd
+	MinimumPeers int64
+	// MaximumBlockAge of the best block, measured from its header time. 0 disables the check
+	MaximumBlockAge time.Duration
+	// Timeout of the requests checking qtumd
+	Timeout time.Duration
+}
+
+var DefaultReadinessConfig = ReadinessConfig{
+	MinimumPeers:    1,
+	MaximumBlockAge: time.Hour,
+	Timeout:         5 * time.Second,
+}
+
+func (config ReadinessConfig) validate() error {
+	if config.MinimumPeers < 0 || config.MaximumBlockAge < 0 || config.Timeout < 0 {
+		return errors.New("readiness settings must not be negative")
+	}
+	return nil
+}
+
+// SetReadiness configures when /ready reports janus ready
+func SetReadiness(config ReadinessConfig) Option {
+	return func(p *Server) error {
+		if err := config.validate(); err != nil {
+			return err
+		}
+		p.readiness = config
+		return nil
+	}
+}
+
+type healthResponse struct {
+	Status string `json:"status"`
+}
+
+type readinessResponse struct {
+	Ready bool `json:"ready"`
+	// Errors are the reasons janus isn't ready
+	Errors []string `json:"errors,omitempty"`
+	Blocks int64    `json:"blocks,omitempty"`
+	Peers  int64    `json:"peers,omitempty"`
+}
+
+// healthHandler tells the process is alive, without checking qtumd
+func healthHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, healthResponse{Status: "ok"})
+}
+
+// readyHandler tells whether qtumd can be reached and is synced with the network,
+// load balancers should only route requests to janus while it answers 200
+func (s *Server) readyHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	if s.readiness.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.readiness.Timeout)
+		defer cancel()
+	}
+
+	response := checkReadiness(ctx, s.qtumRPCClient, s.readiness, time.Now())
+	if !response.Ready {
+		return c.JSON(http.StatusServiceUnavailable, response)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func checkReadiness(ctx context.Context, client *qtum.Qtum, config ReadinessConfig, now time.Time) readinessResponse {
+	var response readinessResponse
+
+	blockchainInfo, err := client.GetBlockChainInfo(ctx)
+	if err != nil {
+		response.Errors = append(response.Errors, fmt.Sprintf("qtumd is unreachable: %s", err))
+		return response
+	}
+	response.Blocks = blockchainInfo.Blocks
+	if blockchainInfo.InitialBlockDownload {
+		response.Errors = append(response.Errors, fmt.Sprintf("qtumd is in initial block download at block %d of %d", blockchainInfo.Blocks, blockchainInfo.Headers))
+	}
+	if config.MaximumBlockAge > 0 {
+		header, err := client.GetBlockHeader(ctx, blockchainInfo.Bestblockhash)
+		if err != nil {
+			response.Errors = append(response.Errors, fmt.Sprintf("qtumd is unreachable: %s", err))
+			return response
+		}
+		age := now.Sub(time.Unix(int64(header.Time), 0))
+		if age > config.MaximumBlockAge {
+			response.Errors = append(response.Errors, fmt.Sprintf("best block is %s old, more than %s", age.Truncate(time.Second), config.MaximumBlockAge))
+		}
+	}
+
+	networkInfo, err := client.GetNetworkInfo(ctx)
+	if err != nil {
+		response.Errors = append(response.Errors, fmt.Sprintf("qtumd is unreachable: %s", err))
+		return response
+	}
+	response.Peers = networkInfo.Connections
+	if networkInfo.Connections < config.MinimumPeers {
+		response.Errors = append(response.Errors, fmt.Sprintf("qtumd has %d peers, less than %d", networkInfo.Connections, config.MinimumPeers))
+	}
+
+	response.Ready = len(response.Errors) == 0
+	return response
+}
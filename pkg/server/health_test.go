@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestCheckReadiness(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	config := ReadinessConfig{MinimumPeers: 2, MaximumBlockAge: time.Hour}
+
+	tests := []struct {
+		name       string
+		blockchain *qtum.GetBlockChainInfoResponse
+		blockTime  time.Time
+		peers      int64
+		errors     []string
+	}{
+		{
+			name:       "synced",
+			blockchain: &qtum.GetBlockChainInfoResponse{Blocks: 100, Headers: 100, Bestblockhash: "a1", Mediantime: now.Add(-2 * time.Hour).Unix()},
+			blockTime:  now.Add(-time.Minute),
+			peers:      3,
+		},
+		{
+			name:       "unreachable",
+			blockchain: nil,
+			errors:     []string{"qtumd is unreachable"},
+		},
+		{
+			name:       "initial block download",
+			blockchain: &qtum.GetBlockChainInfoResponse{Blocks: 10, Headers: 100, InitialBlockDownload: true, Bestblockhash: "0a"},
+			blockTime:  now,
+			peers:      3,
+			errors:     []string{"initial block download at block 10 of 100"},
+		},
+		{
+			name:       "stale and isolated",
+			blockchain: &qtum.GetBlockChainInfoResponse{Blocks: 100, Headers: 100, Bestblockhash: "b2", Mediantime: now.Unix()},
+			blockTime:  now.Add(-2 * time.Hour),
+			peers:      1,
+			errors:     []string{"best block is 2h0m0s old", "qtumd has 1 peers, less than 2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doer := internal.NewDoerMappedMock()
+			if test.blockchain != nil {
+				if err := doer.AddResponse(qtum.MethodGetBlockChainInfo, test.blockchain); err != nil {
+					t.Fatal(err)
+				}
+				header := qtum.GetBlockHeaderResponse{Hash: test.blockchain.Bestblockhash, Time: uint64(test.blockTime.Unix())}
+				if err := doer.AddResponse(qtum.MethodGetBlockHeader, header); err != nil {
+					t.Fatal(err)
+				}
+				if err := doer.AddResponse(qtum.MethodGetNetworkInfo, qtum.NetworkInfoResponse{Connections: test.peers}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			client, err := internal.CreateMockedClient(doer)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			response := checkReadiness(context.Background(), client, config, now)
+			if response.Ready != (len(test.errors) == 0) {
+				t.Fatalf("expected ready to be %v, got %+v", len(test.errors) == 0, response)
+			}
+			if len(response.Errors) != len(test.errors) {
+				t.Fatalf("expected errors %v, got %v", test.errors, response.Errors)
+			}
+			for i, expected := range test.errors {
+				if !strings.Contains(response.Errors[i], expected) {
+					t.Errorf("expected error %q to contain %q", response.Errors[i], expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/metrics"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/transformer"
+)
+
+// observeRequest records an eth RPC request with the error code it is answered with,
+// methods without a proxy are recorded as unknown so that clients can't grow the metrics
+func observeRequest(t *transformer.Transformer, method string, start time.Time, result interface{}, err error) {
+	if !t.HasMethod(method) {
+		method = "unknown"
+	}
+
+	code := ""
+	if err != nil {
+		code = strconv.Itoa(causeToJSONRPCError(errors.Cause(err)).Code)
+	} else if jerr, ok := result.(*eth.JSONRPCError); ok {
+		code = strconv.Itoa(jerr.Code)
+	}
+
+	metrics.ObserveETHRequest(method, time.Since(start), code)
+}
+
+// registerClientMetrics exports the connections and requests to qtumd
+func registerClientMetrics(client *qtum.Qtum) {
+	metrics.RegisterGaugeFunc("qtum", "connections_open", "connections open to qtumd", func() float64 {
+		return float64(client.PoolStats().Open)
+	})
+	metrics.RegisterGaugeFunc("qtum", "connections_active", "requests waiting for a response from qtumd", func() float64 {
+		return float64(client.PoolStats().Active)
+	})
+	metrics.RegisterCounterFunc("qtum", "connections_dialed_total", "connections dialed to qtumd", func() float64 {
+		return float64(client.PoolStats().Dialed)
+	})
+	metrics.RegisterCounterFunc("qtum", "connections_reused_total", "requests sent to qtumd over a connection kept alive", func() float64 {
+		return float64(client.PoolStats().Reused)
+	})
+	metrics.RegisterGaugeFunc("qtum", "concurrency_limit", "requests allowed in flight to qtumd", func() float64 {
+		return float64(client.ConcurrencyStats().Limit)
+	})
+	metrics.RegisterGaugeFunc("qtum", "requests_in_flight", "requests in flight to qtumd", func() float64 {
+		return float64(client.ConcurrencyStats().InFlight)
+	})
+	metrics.RegisterGaugeFunc("qtum", "requests_queued", "requests waiting to be sent to qtumd", func() float64 {
+		return float64(client.ConcurrencyStats().Queued)
+	})
+	metrics.RegisterGaugeFunc("qtum", "circuit_breaker_open", "1 while requests to qtumd fail fast, 0.5 while probing qtumd", func() float64 {
+		switch client.ConcurrencyStats().Breaker {
+		case qtum.BreakerOpen:
+			return 1
+		case qtum.BreakerHalfOpen:
+			return 0.5
+		}
+		return 0
+	})
+}
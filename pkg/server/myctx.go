@@ -18,6 +18,11 @@ type myCtx struct {
 	logWriter   io.Writer
 	logger      log.Logger
 	transformer *transformer.Transformer
+	// permissions of the credentials the client authenticated with
+	permissions *permissions
+	// clientID identifies the credentials the client authenticated with, if any
+	clientID    string
+	rateLimiter *rateLimiter
 }
 
 func (c *myCtx) GetJSONRPCResult(result interface{}) (*eth.JSONRPCResult, error) {
@@ -84,6 +89,14 @@ func (c *myCtx) GetErrorLogger() log.Logger {
 	return log.With(level.Error(c.logger))
 }
 
+// rateLimitClient is the client the limits apply to, authenticated clients are limited by credentials and others by IP
+func (c *myCtx) rateLimitClient() rateLimitClient {
+	if c.clientID != "" {
+		return rateLimitClient{id: c.clientID, authenticated: true}
+	}
+	return rateLimitClient{id: c.rateLimiter.clientIP(c.Request())}
+}
+
 func (c *myCtx) IsDebugEnabled() bool {
 	return c.transformer.IsDebugEnabled()
 }
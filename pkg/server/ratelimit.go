@@ -0,0 +1,350 @@
+package server
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/eth"
+)
+
+// rateLimitSweepInterval is how often clients whose buckets refilled are forgotten
+const rateLimitSweepInterval = time.Minute
+
+// RateLimitConfig limits the requests of each client with a token bucket, a request takes the cost of its method
+// from the bucket of its client. Clients are identified by their API key or JWT when authenticated, by their IP otherwise
+type RateLimitConfig struct {
+	// TrustedProxies are the IPs or CIDRs of the proxies whose X-Forwarded-For and X-Real-IP headers are honoured,
+	// the IP of other peers is their remote address
+	TrustedProxies []string
+	// IPRate is the cost of the requests a client IP may send per second, 0 for no limit
+	IPRate  float64
+	IPBurst float64
+	// KeyRate is the cost of the requests an authenticated client may send per second, 0 for no limit
+	KeyRate  float64
+	KeyBurst float64
+	// MethodCosts weigh expensive methods, other methods cost 1. A cost above the burst is capped to the burst
+	MethodCosts map[string]float64
+	// MaxConnections are the websocket connections a client may keep open, 0 for no limit
+	MaxConnections int
+	// MaxSubscriptions are the eth_subscribe subscriptions a client may hold across its connections, 0 for no limit
+	MaxSubscriptions int
+}
+
+// DefaultMethodCosts weigh the methods scanning blocks or logs against a single lookup
+var DefaultMethodCosts = map[string]float64{
+	"eth_getLogs":          20,
+	"eth_getFilterLogs":    20,
+	"eth_getFilterChanges": 5,
+	"eth_getBlockByNumber": 5,
+	"eth_getBlockByHash":   5,
+	"eth_call":             2,
+	"eth_estimateGas":      2,
+}
+
+func (config RateLimitConfig) validate() error {
+	if config.IPRate < 0 || config.KeyRate < 0 || config.MaxConnections < 0 || config.MaxSubscriptions < 0 {
+		return errors.New("rate limits must not be negative")
+	}
+	if (config.IPRate > 0 && config.IPBurst < 1) || (config.KeyRate > 0 && config.KeyBurst < 1) {
+		return errors.New("rate limit bursts must be at least 1")
+	}
+	for method, cost := range config.MethodCosts {
+		if cost < 0 {
+			return errors.Errorf("cost of %s must not be negative", method)
+		}
+	}
+	_, err := parseTrustedProxies(config.TrustedProxies)
+	return err
+}
+
+// parseTrustedProxies parses IPs and CIDRs into networks
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, errors.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trusted proxy %q", proxy)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// SetRateLimits limits the requests, websocket connections and subscriptions of each client
+func SetRateLimits(config RateLimitConfig) Option {
+	return func(p *Server) error {
+		return p.ReloadRateLimits(config)
+	}
+}
+
+// ReloadRateLimits replaces the limits of the clients, keeping their buckets, connections and subscriptions
+func (s *Server) ReloadRateLimits(config RateLimitConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+	trusted, _ := parseTrustedProxies(config.TrustedProxies)
+	s.rateLimiter.mutex.Lock()
+	s.rateLimiter.config = config
+	s.rateLimiter.trusted = trusted
+	s.rateLimiter.mutex.Unlock()
+	return nil
+}
+
+// errLimitExceeded is the EIP-1474 error of requests exceeding a limit
+func errLimitExceeded(message string) *eth.JSONRPCError {
+	return &eth.JSONRPCError{
+		Code:    -32005,
+		Message: message,
+	}
+}
+
+type rateLimitClient struct {
+	id            string
+	authenticated bool
+}
+
+type clientLimits struct {
+	tokens        float64
+	updated       time.Time
+	connections   int
+	subscriptions int
+}
+
+// rateLimiter keeps the limits of the clients, its config can be replaced while janus runs
+type rateLimiter struct {
+	mutex   sync.Mutex
+	config  RateLimitConfig
+	trusted []*net.IPNet
+	clients map[rateLimitClient]*clientLimits
+	swept   time.Time
+	now     func() time.Time
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		config:  config,
+		clients: make(map[rateLimitClient]*clientLimits),
+		now:     time.Now,
+	}
+}
+
+// clientIP is the remote address of the request, or the client a trusted proxy forwards it for. X-Forwarded-For is
+// read from the right, skipping the trusted proxies, since clients may prepend any address to it
+func (l *rateLimiter) clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if l == nil {
+		return ip
+	}
+	l.mutex.Lock()
+	trusted := l.trusted
+	l.mutex.Unlock()
+	if !isTrustedProxy(trusted, ip) {
+		return ip
+	}
+
+	if forwarded := r.Header.Get(echo.HeaderXForwardedFor); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				// a malformed hop can't be trusted further
+				return ip
+			}
+			ip = hop
+			if !isTrustedProxy(trusted, hop) {
+				return hop
+			}
+		}
+		return ip
+	}
+	if realIP := strings.TrimSpace(r.Header.Get(echo.HeaderXRealIP)); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return ip
+}
+
+func isTrustedProxy(trusted []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *rateLimiter) bucket(client rateLimitClient) (rate float64, burst float64) {
+	if client.authenticated {
+		return l.config.KeyRate, l.config.KeyBurst
+	}
+	return l.config.IPRate, l.config.IPBurst
+}
+
+// limits of the client with its bucket refilled up to now, must be called with the mutex held
+func (l *rateLimiter) limits(client rateLimitClient, now time.Time) *clientLimits {
+	if now.Sub(l.swept) > rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	rate, burst := l.bucket(client)
+	limits, ok := l.clients[client]
+	if !ok {
+		limits = &clientLimits{tokens: burst, updated: now}
+		l.clients[client] = limits
+		return limits
+	}
+	limits.tokens = math.Min(burst, limits.tokens+now.Sub(limits.updated).Seconds()*rate)
+	limits.updated = now
+	return limits
+}
+
+// sweep forgets the clients back to a full bucket without connections, so that their limits don't pile up
+func (l *rateLimiter) sweep(now time.Time) {
+	for client, limits := range l.clients {
+		rate, burst := l.bucket(client)
+		if limits.connections == 0 && limits.subscriptions == 0 && limits.tokens+now.Sub(limits.updated).Seconds()*rate >= burst {
+			delete(l.clients, client)
+		}
+	}
+	l.swept = now
+}
+
+func (l *rateLimiter) cost(method string) float64 {
+	if cost, ok := l.config.MethodCosts[method]; ok {
+		return cost
+	}
+	return 1
+}
+
+// allow takes the cost of the method from the bucket of the client, or returns how long to wait before retrying
+func (l *rateLimiter) allow(client rateLimitClient, method string) (time.Duration, bool) {
+	if l == nil {
+		return 0, true
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	rate, burst := l.bucket(client)
+	if rate == 0 {
+		return 0, true
+	}
+	limits := l.limits(client, l.now())
+	cost := math.Min(l.cost(method), burst)
+	if limits.tokens < cost {
+		return time.Duration((cost - limits.tokens) / rate * float64(time.Second)), false
+	}
+	limits.tokens -= cost
+	return 0, true
+}
+
+func (l *rateLimiter) openConnection(client rateLimitClient) bool {
+	if l == nil {
+		return true
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limits := l.limits(client, l.now())
+	if l.config.MaxConnections > 0 && limits.connections >= l.config.MaxConnections {
+		return false
+	}
+	limits.connections++
+	return true
+}
+
+func (l *rateLimiter) closeConnection(client rateLimitClient) {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.limits(client, l.now()).connections--
+}
+
+func (l *rateLimiter) addSubscription(client rateLimitClient) bool {
+	if l == nil {
+		return true
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limits := l.limits(client, l.now())
+	if l.config.MaxSubscriptions > 0 && limits.subscriptions >= l.config.MaxSubscriptions {
+		return false
+	}
+	limits.subscriptions++
+	return true
+}
+
+func (l *rateLimiter) removeSubscriptions(client rateLimitClient, count int) {
+	if l == nil || count == 0 {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.limits(client, l.now()).subscriptions -= count
+}
+
+// retryAfter is the Retry-After header value of a wait, in whole seconds
+func retryAfter(wait time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(wait.Seconds())))
+}
+
+// limitWebsocketRequest applies the limits of the client to a request sent over its websocket connection,
+// subscriptions counts the subscriptions of the connection to release them once it closes
+func limitWebsocketRequest(cc *myCtx, method string, subscriptions *int, transform func() (interface{}, error)) (interface{}, error) {
+	client := cc.rateLimitClient()
+	if _, ok := cc.rateLimiter.allow(client, method); !ok {
+		return errLimitExceeded("rate limit exceeded"), nil
+	}
+
+	switch method {
+	case "eth_subscribe":
+		if !cc.rateLimiter.addSubscription(client) {
+			return errLimitExceeded("subscription limit exceeded"), nil
+		}
+		result, err := transform()
+		if _, failed := result.(*eth.JSONRPCError); err != nil || failed {
+			cc.rateLimiter.removeSubscriptions(client, 1)
+		} else {
+			*subscriptions++
+		}
+		return result, err
+	case "eth_unsubscribe":
+		result, err := transform()
+		if unsubscribed, ok := result.(eth.EthUnsubscribeResponse); ok && bool(unsubscribed) && *subscriptions > 0 {
+			*subscriptions--
+			cc.rateLimiter.removeSubscriptions(client, 1)
+		}
+		return result, err
+	default:
+		return transform()
+	}
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/transformer"
+)
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	limiter := newRateLimiter(RateLimitConfig{
+		IPRate:      1,
+		IPBurst:     10,
+		KeyRate:     100,
+		KeyBurst:    100,
+		MethodCosts: map[string]float64{"eth_getLogs": 5, "eth_expensive": 50},
+	})
+	limiter.now = func() time.Time { return now }
+
+	ip := rateLimitClient{id: "127.0.0.1"}
+	key := rateLimitClient{id: "key:secret", authenticated: true}
+
+	for i := 0; i < 2; i++ {
+		if _, ok := limiter.allow(ip, "eth_getLogs"); !ok {
+			t.Fatalf("expected eth_getLogs %d to be allowed within the burst", i)
+		}
+	}
+	wait, ok := limiter.allow(ip, "eth_blockNumber")
+	if ok {
+		t.Fatal("expected the bucket to be empty")
+	}
+	if wait != time.Second {
+		t.Fatalf("expected to wait a second for a token, got %s", wait)
+	}
+	// other clients have their own buckets
+	if _, ok := limiter.allow(key, "eth_getLogs"); !ok {
+		t.Fatal("expected the API key to be allowed")
+	}
+
+	now = now.Add(3 * time.Second)
+	if _, ok := limiter.allow(ip, "eth_getLogs"); ok {
+		t.Fatal("expected 3 tokens not to be enough for eth_getLogs")
+	}
+	if _, ok := limiter.allow(ip, "eth_blockNumber"); !ok {
+		t.Fatal("expected the bucket to refill")
+	}
+
+	// costs above the burst are capped so that the method can still be called
+	now = now.Add(time.Minute)
+	if _, ok := limiter.allow(ip, "eth_expensive"); !ok {
+		t.Fatal("expected a method costing more than the burst to be allowed with a full bucket")
+	}
+
+	// idle clients are forgotten
+	now = now.Add(2 * rateLimitSweepInterval)
+	limiter.allow(key, "eth_blockNumber")
+	if _, ok := limiter.clients[ip]; ok {
+		t.Fatal("expected the idle client to be swept")
+	}
+}
+
+func TestLimitWebsocketRequestSubscriptions(t *testing.T) {
+	limiter := newRateLimiter(RateLimitConfig{MaxConnections: 1, MaxSubscriptions: 2})
+	client := rateLimitClient{id: "127.0.0.1"}
+
+	if !limiter.openConnection(client) {
+		t.Fatal("expected the first connection to be allowed")
+	}
+	if limiter.openConnection(client) {
+		t.Fatal("expected the second connection to be rejected")
+	}
+
+	// the client identity is taken from clientID, avoiding the request IP
+	cc := &myCtx{rateLimiter: limiter, clientID: "127.0.0.1"}
+	client = cc.rateLimitClient()
+	subscriptions := 0
+	subscribe := func() (interface{}, error) { return "0x1", nil }
+	unsubscribe := func() (interface{}, error) { return eth.EthUnsubscribeResponse(true), nil }
+
+	for i := 0; i < 2; i++ {
+		if result, _ := limitWebsocketRequest(cc, "eth_subscribe", &subscriptions, subscribe); result != "0x1" {
+			t.Fatalf("expected subscription %d to be allowed, got %v", i, result)
+		}
+	}
+	result, _ := limitWebsocketRequest(cc, "eth_subscribe", &subscriptions, subscribe)
+	if jerr, ok := result.(*eth.JSONRPCError); !ok || jerr.Code != -32005 {
+		t.Fatalf("expected the third subscription to exceed the limit, got %v", result)
+	}
+
+	limitWebsocketRequest(cc, "eth_unsubscribe", &subscriptions, unsubscribe)
+	if subscriptions != 1 || limiter.clients[client].subscriptions != 1 {
+		t.Fatalf("expected one subscription left, got %d", subscriptions)
+	}
+
+	// failed subscriptions don't count
+	limitWebsocketRequest(cc, "eth_subscribe", &subscriptions, func() (interface{}, error) {
+		return &eth.JSONRPCError{Code: -32602}, nil
+	})
+	if limiter.clients[client].subscriptions != 1 {
+		t.Fatalf("expected the failed subscription to be released, got %d", limiter.clients[client].subscriptions)
+	}
+
+	limiter.removeSubscriptions(client, subscriptions)
+	if limiter.clients[client].subscriptions != 0 {
+		t.Fatal("expected the subscriptions of the closed connection to be released")
+	}
+}
+
+func TestRateLimiterClientIP(t *testing.T) {
+	s := &Server{rateLimiter: newRateLimiter(RateLimitConfig{})}
+	if err := s.ReloadRateLimits(RateLimitConfig{TrustedProxies: []string{"10.0.0.1", "192.168.0.0/16"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		// untrusted peers can't pick their IP
+		{"203.0.113.7:5000", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"10.0.0.1:5000", "198.51.100.1", "", "198.51.100.1"},
+		// addresses prepended by the client are ignored
+		{"10.0.0.1:5000", "198.51.100.1, 203.0.113.7, 192.168.1.1", "", "203.0.113.7"},
+		{"10.0.0.1:5000", "", "198.51.100.2", "198.51.100.2"},
+		{"10.0.0.1:5000", "not-an-ip", "", "10.0.0.1"},
+		{"192.168.1.1:5000", "", "", "192.168.1.1"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("POST", "/", nil)
+		r.RemoteAddr = c.remoteAddr
+		if c.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", c.forwarded)
+		}
+		if c.realIP != "" {
+			r.Header.Set("X-Real-IP", c.realIP)
+		}
+		if got := s.rateLimiter.clientIP(r); got != c.want {
+			t.Errorf("%s forwarding %q: want client %s, got %s", c.remoteAddr, c.forwarded, c.want, got)
+		}
+	}
+
+	if err := s.ReloadRateLimits(RateLimitConfig{TrustedProxies: []string{"10.0.0.300"}}); err == nil {
+		t.Error("expected an invalid trusted proxy to be rejected")
+	}
+}
+
+func TestBatchRequestsRateLimitedPerClient(t *testing.T) {
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mockedClientDoer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(100)}); err != nil {
+		t.Fatal(err)
+	}
+	transformer, err := transformer.New(qtumClient, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter := newRateLimiter(RateLimitConfig{IPRate: 1, IPBurst: 2})
+	limiter.now = func() time.Time { return time.Unix(1600000000, 0) }
+
+	batch := `[{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber"},{"jsonrpc":"2.0","id":2,"method":"eth_blockNumber"}]`
+	send := func(remoteAddr string) []*eth.JSONRPCResult {
+		e := echo.New()
+		req := httptest.NewRequest(echo.POST, "/", strings.NewReader(batch))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("myctx", &myCtx{Context: c, logger: log.NewNopLogger(), transformer: transformer, rateLimiter: limiter})
+		if err := batchRequestsMiddleware(httpHandler)(c); err != nil {
+			t.Fatal(err)
+		}
+		var results []*eth.JSONRPCResult
+		if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+	limited := func(results []*eth.JSONRPCResult) int {
+		count := 0
+		for _, result := range results {
+			if result.Error != nil && result.Error.Code == -32005 {
+				count++
+			}
+		}
+		return count
+	}
+
+	if count := limited(send("203.0.113.7:5000")); count != 0 {
+		t.Fatalf("expected the first batch to fit the burst, %d requests were limited", count)
+	}
+	if count := limited(send("203.0.113.7:5001")); count != 2 {
+		t.Fatalf("expected the second batch of the same IP to be limited, %d requests were limited", count)
+	}
+	// another client has its own bucket
+	if count := limited(send("198.51.100.1:5000")); count != 0 {
+		t.Fatalf("expected the batch of another IP to be allowed, %d requests were limited", count)
+	}
+	for _, ip := range []string{"203.0.113.7", "198.51.100.1"} {
+		if _, ok := limiter.clients[rateLimitClient{id: ip}]; !ok {
+			t.Errorf("expected a bucket for %s", ip)
+		}
+	}
+}
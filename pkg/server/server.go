@@ -16,6 +16,7 @@ import (
 	"github.com/labstack/echo/middleware"
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/metrics"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/qtumproject/janus/pkg/transformer"
 )
@@ -31,6 +32,9 @@ type Server struct {
 	debug         bool
 	mutex         *sync.Mutex
 	echo          *echo.Echo
+	readiness     ReadinessConfig
+	auth          AuthConfig
+	rateLimiter   *rateLimiter
 }
 
 func New(
@@ -45,6 +49,8 @@ func New(
 		address:       addr,
 		qtumRPCClient: qtumRPCClient,
 		transformer:   transformer,
+		readiness:     DefaultReadinessConfig,
+		rateLimiter:   newRateLimiter(RateLimitConfig{}),
 	}
 
 	var err error
@@ -86,6 +92,7 @@ func (s *Server) Start() error {
 				logWriter:   logWriter,
 				logger:      s.logger,
 				transformer: s.transformer,
+				rateLimiter: s.rateLimiter,
 			}
 
 			c.Set("myctx", cc)
@@ -94,11 +101,23 @@ func (s *Server) Start() error {
 		}
 	})
 
+	// batches are authenticated as a whole before being split
+	e.Use(s.authMiddleware)
+
 	// support batch requests
 	e.Use(batchRequestsMiddleware)
 
 	e.HTTPErrorHandler = errorHandler
 	e.HideBanner = true
+
+	// connections and requests to qtumd are exposed for monitoring
+	registerClientMetrics(s.qtumRPCClient)
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+
+	// static routes take precedence over the websocket route below
+	e.GET("/health", healthHandler)
+	e.GET("/ready", s.readyHandler)
+
 	if s.mutex == nil {
 		e.POST("/*", httpHandler)
 		e.GET("/*", websocketHandler)
@@ -218,7 +237,13 @@ func callHttpHandler(cc *myCtx, req *eth.JSONRPCRequest) (*eth.JSONRPCResult, er
 		return nil, err
 	}
 
-	httpreq := httptest.NewRequest(echo.POST, "/", ioutil.NopCloser(bytes.NewReader(reqBytes)))
+	// requests of a batch are cancelled along with the batch
+	httpreq := httptest.NewRequest(echo.POST, "/", ioutil.NopCloser(bytes.NewReader(reqBytes))).WithContext(cc.Request().Context())
+	// the rate limits of the batch's client apply to each of its requests
+	httpreq.RemoteAddr = cc.Request().RemoteAddr
+	for name, values := range cc.Request().Header {
+		httpreq.Header[name] = append([]string(nil), values...)
+	}
 	httpreq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 
@@ -228,6 +253,9 @@ func callHttpHandler(cc *myCtx, req *eth.JSONRPCRequest) (*eth.JSONRPCResult, er
 		logWriter:   cc.GetLogWriter(),
 		logger:      cc.logger,
 		transformer: cc.transformer,
+		permissions: cc.permissions,
+		clientID:    cc.clientID,
+		rateLimiter: cc.rateLimiter,
 	}
 	newCtx.Set("myctx", myCtx)
 	if err = httpHandler(myCtx); err != nil {
@@ -0,0 +1,84 @@
+package transformer
+
+import "sync"
+
+// blockCacheSize is the number of blocks kept, the oldest ones are evicted first
+const blockCacheSize = 10000
+
+// blockInfo holds what was resolved about a block, which never changes for a given block hash
+type blockInfo struct {
+	// cumulativeGas is the gas used by the transactions of the block up to each of them, included
+	cumulativeGas []uint64
+	// miner is the hex address of the staker or miner of the block, empty until resolved
+	miner string
+}
+
+// blockCache holds the blockInfo by block hash. Its methods may be called on a nil cache, which keeps nothing
+type blockCache struct {
+	mutex  sync.Mutex
+	blocks map[string]*blockInfo
+	hashes []string
+}
+
+func newBlockCache() *blockCache {
+	return &blockCache{blocks: make(map[string]*blockInfo)}
+}
+
+func (c *blockCache) cumulativeGas(blockHash string) ([]uint64, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	info, ok := c.blocks[blockHash]
+	if !ok || info.cumulativeGas == nil {
+		return nil, false
+	}
+	return info.cumulativeGas, true
+}
+
+func (c *blockCache) setCumulativeGas(blockHash string, gas []uint64) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.info(blockHash).cumulativeGas = gas
+}
+
+func (c *blockCache) miner(blockHash string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	info, ok := c.blocks[blockHash]
+	if !ok || info.miner == "" {
+		return "", false
+	}
+	return info.miner, true
+}
+
+func (c *blockCache) setMiner(blockHash string, miner string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.info(blockHash).miner = miner
+}
+
+// info returns the entry of a block, adding it when missing. The mutex must be held
+func (c *blockCache) info(blockHash string) *blockInfo {
+	if info, ok := c.blocks[blockHash]; ok {
+		return info
+	}
+	if len(c.hashes) >= blockCacheSize {
+		delete(c.blocks, c.hashes[0])
+		c.hashes = c.hashes[1:]
+	}
+	info := &blockInfo{}
+	c.blocks[blockHash] = info
+	c.hashes = append(c.hashes, blockHash)
+	return info
+}
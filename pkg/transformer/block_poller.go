@@ -2,6 +2,7 @@ package transformer
 
 import (
 	"container/list"
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -11,21 +12,22 @@ import (
 	"time"
 
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/metrics"
 	"github.com/qtumproject/janus/pkg/qtum"
 )
 
 type BlockPoller interface {
-	Pull(blockNumber *big.Int) (*eth.GetBlockByHashResponse, error)
+	Pull(ctx context.Context, blockNumber *big.Int) (*eth.GetBlockByHashResponse, error)
 }
 
 type DefaultBlockPoller struct {
 	Qtum *qtum.Qtum
 }
 
-func (p *DefaultBlockPoller) Pull(blockNumber *big.Int) (*eth.GetBlockByHashResponse, error) {
+func (p *DefaultBlockPoller) Pull(ctx context.Context, blockNumber *big.Int) (*eth.GetBlockByHashResponse, error) {
 	proxy := ProxyETHGetBlockByNumber{Qtum: p.Qtum}
 
-	blockHash, err := proxyETHGetBlockByHash(&proxy, p.Qtum, blockNumber)
+	blockHash, err := proxyETHGetBlockByHash(ctx, &proxy, p.Qtum, blockNumber)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +39,7 @@ func (p *DefaultBlockPoller) Pull(blockNumber *big.Int) (*eth.GetBlockByHashResp
 		}
 		proxyETHGetBlockByHash = &ProxyETHGetBlockByHash{Qtum: p.Qtum}
 	)
-	return proxyETHGetBlockByHash.request(getBlockByHashReq)
+	return proxyETHGetBlockByHash.request(ctx, getBlockByHashReq)
 }
 
 type BlockSyncer struct {
@@ -64,13 +66,40 @@ func (s *BlockSyncer) clearBlocks() {
 	}
 }
 
-func (s *BlockSyncer) loopSync() error {
+// wait sleeps until the next poll, a new block pushed by qtumd ends it early. It fails once ctx is done
+func (s *BlockSyncer) wait(ctx context.Context, blockEvents <-chan qtum.Event) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-blockEvents:
+	case <-time.After(s.Qtum.Events().PollInterval(qtum.EventBlock, s.interval)):
+	}
+	return nil
+}
+
+// backOff sleeps before retrying a failed request, so that qtumd isn't hammered while it is unavailable.
+// It fails once ctx is done
+func (s *BlockSyncer) backOff(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(s.interval):
+	}
+	return nil
+}
+
+func (s *BlockSyncer) loopSync(ctx context.Context) error {
+	blockEvents, unsubscribe := s.Qtum.Events().Subscribe(qtum.EventBlock)
+	defer unsubscribe()
 
 	for {
 		// Query block count
-		blockCountResp, err := s.Qtum.GetBlockCount()
+		blockCountResp, err := s.Qtum.GetBlockCount(ctx)
 		if err != nil {
 			s.Qtum.GetLogger().Log("function", "loopSync", "message", "fail to query blockcount", "error", err)
+			if err := s.backOff(ctx); err != nil {
+				return err
+			}
 			continue
 		}
 		upstreamBlock := blockCountResp.Int
@@ -91,9 +120,12 @@ func (s *BlockSyncer) loopSync() error {
 		}
 
 		if localBlock.Cmp(upstreamBlock) < 0 {
-			newBlock, err := s.poller.Pull(big.NewInt(0).Add(localBlock, big.NewInt(1)))
+			newBlock, err := s.poller.Pull(ctx, big.NewInt(0).Add(localBlock, big.NewInt(1)))
 			if err != nil {
 				s.Qtum.GetLogger().Log("function", "loopSync", "message", "fail to query block", "error", err)
+				if err := s.backOff(ctx); err != nil {
+					return err
+				}
 				continue
 			}
 
@@ -115,9 +147,12 @@ func (s *BlockSyncer) loopSync() error {
 			s.lock.Unlock()
 			continue
 		} else {
-			upstreamHash, err := s.Qtum.GetBlockHash(localBlock)
+			upstreamHash, err := s.Qtum.GetBlockHash(ctx, localBlock)
 			if err != nil {
 				s.Qtum.GetLogger().Log("function", "loopSync", "message", "Fail to get block hash of local height", "error", err)
+				if err := s.backOff(ctx); err != nil {
+					return err
+				}
 				continue
 			}
 
@@ -130,7 +165,9 @@ func (s *BlockSyncer) loopSync() error {
 			}
 
 			// If last block is corrent just sleep
-			time.Sleep(s.interval)
+			if err := s.wait(ctx, blockEvents); err != nil {
+				return err
+			}
 		}
 
 		// Cleanup old
@@ -142,7 +179,14 @@ func (s *BlockSyncer) loopSync() error {
 	}
 }
 
+// GetLatestBlock returns the latest cached block, the lookup is recorded in the block cache metrics
 func (s *BlockSyncer) GetLatestBlock() (*eth.GetBlockByHashResponse, bool) {
+	block, ok := s.getLatestBlock()
+	metrics.BlockCacheLookup(ok)
+	return block, ok
+}
+
+func (s *BlockSyncer) getLatestBlock() (*eth.GetBlockByHashResponse, bool) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -159,7 +203,14 @@ func (s *BlockSyncer) GetLatestBlock() (*eth.GetBlockByHashResponse, bool) {
 	return nil, false
 }
 
+// GetBlock returns a cached block, the lookup is recorded in the block cache metrics
 func (s *BlockSyncer) GetBlock(blockNumber json.RawMessage) (*eth.GetBlockByHashResponse, bool) {
+	block, ok := s.getBlock(blockNumber)
+	metrics.BlockCacheLookup(ok)
+	return block, ok
+}
+
+func (s *BlockSyncer) getBlock(blockNumber json.RawMessage) (*eth.GetBlockByHashResponse, bool) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -226,8 +277,8 @@ func (s *BlockSyncer) GetBlock(blockNumber json.RawMessage) (*eth.GetBlockByHash
 	return nil, false
 }
 
-func (s *BlockSyncer) Start() {
-	go s.loopSync()
+func (s *BlockSyncer) Start(ctx context.Context) {
+	go s.loopSync(ctx)
 }
 
 func NewBlockSyncer(client *qtum.Qtum) (*BlockSyncer, error) {
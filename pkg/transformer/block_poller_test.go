@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
@@ -28,7 +29,7 @@ type MockBlockPoller struct {
 	blocks []*eth.GetBlockByHashResponse
 }
 
-func (p *MockBlockPoller) Pull(block *big.Int) (*eth.GetBlockByHashResponse, error) {
+func (p *MockBlockPoller) Pull(ctx context.Context, block *big.Int) (*eth.GetBlockByHashResponse, error) {
 	for _, b := range p.blocks {
 		bNumber := new(big.Int)
 		bNumber.SetString(b.Number[2:], 16)
@@ -99,7 +100,7 @@ func TestBlockPollerNormalSync(t *testing.T) {
 	syncer, doer, poller := initializeBlockPollerAndClient()
 	setBlock(doer, poller, 0, 10)
 
-	syncer.Start()
+	syncer.Start(context.Background())
 	time.Sleep(1 * time.Millisecond)
 
 	latestBlock, _ := json.Marshal("latest")
@@ -121,7 +122,7 @@ func TestBlockPollerSyncUp(t *testing.T) {
 	syncer, doer, poller := initializeBlockPollerAndClient()
 	setBlock(doer, poller, 0, 10)
 
-	syncer.Start()
+	syncer.Start(context.Background())
 	time.Sleep(1 * time.Millisecond)
 
 	latestBlock, _ := json.Marshal("latest")
@@ -147,7 +148,7 @@ func TestBlockPollerForkWithSameHigh(t *testing.T) {
 	syncer, doer, poller := initializeBlockPollerAndClient()
 	setBlock(doer, poller, 0, 10)
 
-	syncer.Start()
+	syncer.Start(context.Background())
 	time.Sleep(1 * time.Millisecond)
 
 	latestBlock, _ := json.Marshal("latest")
@@ -175,7 +176,7 @@ func TestBlockPollerForkWithLowerHight(t *testing.T) {
 	syncer, doer, poller := initializeBlockPollerAndClient()
 	setBlock(doer, poller, 0, 10)
 
-	syncer.Start()
+	syncer.Start(context.Background())
 	time.Sleep(1 * time.Millisecond)
 
 	setBlock(doer, poller, 8, 9)
@@ -205,7 +206,7 @@ func TestBlockPollerForkWithHigherHight(t *testing.T) {
 	syncer, doer, poller := initializeBlockPollerAndClient()
 	setBlock(doer, poller, 0, 10)
 
-	syncer.Start()
+	syncer.Start(context.Background())
 	time.Sleep(1 * time.Millisecond)
 
 	setBlock(doer, poller, 8, 16)
@@ -230,3 +231,32 @@ func TestBlockPollerForkWithHigherHight(t *testing.T) {
 	assert.False(t, found)
 	assert.Nil(t, prev)
 }
+
+func TestBlockPollerStopsRetryingOnceCancelled(t *testing.T) {
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the poller has no block, so the sync keeps backing off
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	syncer, _ := NewBlockSyncerWithBlockPollerAndInterval(qtumClient, &MockBlockPoller{}, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- syncer.loopSync(ctx)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second):
+		t.Fatal("expected the sync to stop once cancelled")
+	}
+}
@@ -0,0 +1,78 @@
+package transformer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+// receiptsBatchRequests ask for the receipts of each of the transactions
+func receiptsBatchRequests(txs []string) []*qtum.BatchRequest {
+	requests := make([]*qtum.BatchRequest, 0, len(txs))
+	for _, tx := range txs {
+		requests = append(requests, qtum.NewBatchRequest(qtum.MethodGetTransactionReceipt, qtum.GetTransactionReceiptRequest(tx), nil))
+	}
+	return requests
+}
+
+// getReceipts returns the receipts of each of the transactions in their order, transactions without contract outputs
+// have none. The requests are sent in a single batch, unless they were prefetched
+func getReceipts(ctx context.Context, q *qtum.Qtum, txs []string) ([][]qtum.TransactionReceipt, error) {
+	view, err := q.Prefetch(ctx, receiptsBatchRequests(txs))
+	if err != nil {
+		q.GetDebugLogger().Log("function", "getReceipts", "msg", "couldn't prefetch receipts", "error", err)
+		view = q
+	}
+
+	receipts := make([][]qtum.TransactionReceipt, len(txs))
+	for i, tx := range txs {
+		if receipts[i], err = view.GetTransactionReceipts(ctx, tx); err != nil {
+			return nil, errors.WithMessagef(err, "couldn't get receipts of %s", tx)
+		}
+	}
+	return receipts, nil
+}
+
+// getCumulativeGas returns the gas used by the transactions of a block up to each of them, included. The receipts of
+// a block are only fetched the first time
+func getCumulativeGas(ctx context.Context, q *qtum.Qtum, blocks *blockCache, blockHash string, txs []string) ([]uint64, error) {
+	if gas, ok := blocks.cumulativeGas(blockHash); ok {
+		return gas, nil
+	}
+	receipts, err := getReceipts(ctx, q, txs)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		gas   = make([]uint64, len(txs))
+		total uint64
+	)
+	for i, txReceipts := range receipts {
+		for _, receipt := range txReceipts {
+			total += receipt.GasUsed
+		}
+		gas[i] = total
+	}
+	blocks.setCumulativeGas(blockHash, gas)
+	return gas, nil
+}
+
+// cumulativeGasUsed is the gas used by the transactions of the block up to the one at index, included
+func cumulativeGasUsed(ctx context.Context, q *qtum.Qtum, blocks *blockCache, blockHash string, index uint64) (uint64, error) {
+	gas, ok := blocks.cumulativeGas(blockHash)
+	if !ok {
+		block, err := q.GetBlock(ctx, blockHash)
+		if err != nil {
+			return 0, errors.WithMessage(err, "couldn't get block")
+		}
+		if gas, err = getCumulativeGas(ctx, q, blocks, blockHash, block.Txs); err != nil {
+			return 0, err
+		}
+	}
+	if index >= uint64(len(gas)) {
+		return 0, errors.Errorf("transaction index %d out of the %d transactions of block %s", index, len(gas), blockHash)
+	}
+	return gas[index], nil
+}
@@ -1,6 +1,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/labstack/echo"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
@@ -16,15 +18,15 @@ func (p *ProxyETHAccounts) Method() string {
 	return "eth_accounts"
 }
 
-func (p *ProxyETHAccounts) Request(_ *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHAccounts) Request(ctx context.Context, _ *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	return p.request()
 }
 
 func (p *ProxyETHAccounts) request() (eth.AccountsResponse, error) {
 	var accounts eth.AccountsResponse
 
-	for _, acc := range p.Accounts {
-		acc := qtum.Account{acc}
+	for _, acc := range p.GetAccounts() {
+		acc := qtum.Account{WIF: acc}
 		addr := acc.ToHexAddress()
 
 		accounts = append(accounts, utils.AddHexPrefix(addr))
@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -33,11 +34,11 @@ func TestAccountRequest(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	qtumClient.Accounts = append(qtumClient.Accounts, exampleAcc1, exampleAcc2)
+	qtumClient.ReloadAccounts(append(qtumClient.GetAccounts(), exampleAcc1, exampleAcc2))
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHAccounts{qtumClient}
-	got, err := proxyEth.Request(request, nil)
+	got, err := proxyEth.Request(context.Background(), request, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
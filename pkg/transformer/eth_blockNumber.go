@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -25,11 +26,11 @@ func (p *ProxyETHBlockNumber) WithBlockCacher(cacher *BlockSyncer) *ProxyETHBloc
 	return p
 }
 
-func (p *ProxyETHBlockNumber) Request(_ *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
-	return p.request(c, 5)
+func (p *ProxyETHBlockNumber) Request(ctx context.Context, _ *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+	return p.request(ctx, c, 5)
 }
 
-func (p *ProxyETHBlockNumber) request(c echo.Context, retries int) (*eth.BlockNumberResponse, error) {
+func (p *ProxyETHBlockNumber) request(ctx context.Context, c echo.Context, retries int) (*eth.BlockNumberResponse, error) {
 
 	if p.cacher != nil {
 		block, ok := p.cacher.GetLatestBlock()
@@ -38,10 +39,9 @@ func (p *ProxyETHBlockNumber) request(c echo.Context, retries int) (*eth.BlockNu
 		}
 	}
 
-	qtumresp, err := p.Qtum.GetBlockCount()
+	qtumresp, err := p.Qtum.GetBlockCount(ctx)
 	if err != nil {
 		if retries > 0 && strings.Contains(err.Error(), qtum.ErrTryAgain.Error()) {
-			ctx := c.Request().Context()
 			t := time.NewTimer(500 * time.Millisecond)
 			select {
 			case <-ctx.Done():
@@ -49,7 +49,7 @@ func (p *ProxyETHBlockNumber) request(c echo.Context, retries int) (*eth.BlockNu
 			case <-t.C:
 				// fallthrough
 			}
-			return p.request(c, retries-1)
+			return p.request(ctx, c, retries-1)
 		}
 		return nil, err
 	}
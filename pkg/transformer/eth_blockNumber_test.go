@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"math/big"
 	"reflect"
@@ -34,7 +35,7 @@ func TestBlockNumberRequest(t *testing.T) {
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHBlockNumber{Qtum: qtumClient}
-	got, err := proxyEth.Request(request, nil)
+	got, err := proxyEth.Request(context.Background(), request, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
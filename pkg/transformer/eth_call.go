@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/labstack/echo"
@@ -18,28 +19,28 @@ func (p *ProxyETHCall) Method() string {
 	return "eth_call"
 }
 
-func (p *ProxyETHCall) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHCall) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var req eth.CallRequest
 	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
 		return nil, err
 	}
 
-	return p.request(&req)
+	return p.request(ctx, &req)
 }
 
-func (p *ProxyETHCall) request(ethreq *eth.CallRequest) (interface{}, error) {
+func (p *ProxyETHCall) request(ctx context.Context, ethreq *eth.CallRequest) (interface{}, error) {
 	// eth req -> qtum req
-	qtumreq, err := p.ToRequest(ethreq)
+	qtumreq, err := p.ToRequest(ctx, ethreq)
 	if err != nil {
 		return nil, err
 	}
-	if qtumreq.GasLimit != nil && qtumreq.GasLimit.Cmp(big.NewInt(40000000)) > 0 {
+	if qtumreq.GasLimit != nil && qtumreq.GasLimit.Cmp(big.NewInt(MaximumGasLimit)) > 0 {
 		qtumresp := eth.CallResponse("0x")
-		p.Qtum.GetLogger().Log("msg", "Caller gas above allowance, capping", "requested", qtumreq.GasLimit.Int64(), "cap", "40,000,000")
+		p.Qtum.GetLogger().Log("msg", "Caller gas above allowance, capping", "requested", qtumreq.GasLimit.Int64(), "cap", MaximumGasLimit)
 		return &qtumresp, nil
 	}
 
-	qtumresp, err := p.CallContract(qtumreq)
+	qtumresp, err := p.CallContract(ctx, qtumreq)
 	if err != nil {
 		if err == qtum.ErrInvalidAddress {
 			qtumresp := eth.CallResponse("0x")
@@ -53,11 +54,11 @@ func (p *ProxyETHCall) request(ethreq *eth.CallRequest) (interface{}, error) {
 	return p.ToResponse(qtumresp), nil
 }
 
-func (p *ProxyETHCall) ToRequest(ethreq *eth.CallRequest) (*qtum.CallContractRequest, error) {
+func (p *ProxyETHCall) ToRequest(ctx context.Context, ethreq *eth.CallRequest) (*qtum.CallContractRequest, error) {
 	from := ethreq.From
 	var err error
 	if utils.IsEthHexAddress(from) {
-		from, err = p.FromHexAddress(from)
+		from, err = p.FromHexAddress(ctx, from)
 		if err != nil {
 			return nil, err
 		}
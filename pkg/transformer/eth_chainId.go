@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"math/big"
 	"strings"
 
@@ -18,24 +19,30 @@ func (p *ProxyETHChainId) Method() string {
 	return "eth_chainId"
 }
 
-func (p *ProxyETHChainId) Request(req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHChainId) Request(ctx context.Context, req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var qtumresp *qtum.GetBlockChainInfoResponse
-	if err := p.Qtum.Request(qtum.MethodGetBlockChainInfo, nil, &qtumresp); err != nil {
+	if err := p.Qtum.RequestWithContext(ctx, qtum.MethodGetBlockChainInfo, nil, &qtumresp); err != nil {
 		return nil, err
 	}
 
-	var chainId *big.Int
-	switch strings.ToLower(qtumresp.Chain) {
-	case "main":
-		chainId = big.NewInt(8888)
-	case "test":
-		chainId = big.NewInt(8889)
-	case "regtest":
-		chainId = big.NewInt(8890)
-	default:
-		chainId = big.NewInt(8890)
+	chainId, ok := getChainId(qtumresp.Chain)
+	if !ok {
 		p.GetDebugLogger().Log("method", p.Method(), "msg", "Unknown chain "+qtumresp.Chain)
 	}
 
 	return eth.ChainIdResponse(hexutil.EncodeBig(chainId)), nil
 }
+
+// getChainId maps a Qtum chain name to its Ethereum chain id, unknown chains are treated as regtest
+func getChainId(chain string) (*big.Int, bool) {
+	switch strings.ToLower(chain) {
+	case qtum.ChainMain:
+		return big.NewInt(8888), true
+	case qtum.ChainTest:
+		return big.NewInt(8889), true
+	case qtum.ChainRegTest:
+		return big.NewInt(8890), true
+	default:
+		return big.NewInt(8890), false
+	}
+}
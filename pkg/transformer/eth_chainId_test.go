@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -49,7 +50,7 @@ func testChainIdsImpl(t *testing.T, chain string, expected string) {
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHChainId{qtumClient}
-	got, err := proxyEth.Request(request, nil)
+	got, err := proxyEth.Request(context.Background(), request, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1,6 +1,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
@@ -21,7 +23,7 @@ func (p *ProxyETHEstimateGas) Method() string {
 	return "eth_estimateGas"
 }
 
-func (p *ProxyETHEstimateGas) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHEstimateGas) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var ethreq eth.CallRequest
 	if err := unmarshalRequest(rawreq.Params, &ethreq); err != nil {
 		return nil, err
@@ -42,13 +44,13 @@ func (p *ProxyETHEstimateGas) Request(rawreq *eth.JSONRPCRequest, c echo.Context
 	ethreq.Gas = nil
 
 	// eth req -> qtum req
-	qtumreq, err := p.ToRequest(&ethreq)
+	qtumreq, err := p.ToRequest(ctx, &ethreq)
 	if err != nil {
 		return nil, err
 	}
 
 	// qtum [code: -5] Incorrect address occurs here
-	qtumresp, err := p.CallContract(qtumreq)
+	qtumresp, err := p.CallContract(ctx, qtumreq)
 	if err != nil {
 		return nil, err
 	}
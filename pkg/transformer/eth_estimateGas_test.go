@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -65,7 +66,7 @@ func TestEstimateGasRequest(t *testing.T) {
 	//preparing proxy & executing request
 	proxyEth := ProxyETHCall{qtumClient}
 	proxyEthEstimateGas := ProxyETHEstimateGas{&proxyEth}
-	got, err := proxyEthEstimateGas.Request(requestRPC, nil)
+	got, err := proxyEthEstimateGas.Request(context.Background(), requestRPC, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -136,7 +137,7 @@ func TestEstimateGasRequestExecutionReverted(t *testing.T) {
 	//preparing proxy & executing request
 	proxyEth := ProxyETHCall{qtumClient}
 	proxyEthEstimateGas := ProxyETHEstimateGas{&proxyEth}
-	_, got := proxyEthEstimateGas.Request(requestRPC, nil)
+	_, got := proxyEthEstimateGas.Request(context.Background(), requestRPC, nil)
 	if got == nil {
 		t.Fatal("Expected error")
 	}
@@ -206,7 +207,7 @@ func TestEstimateGasNonVMRequest(t *testing.T) {
 	//preparing proxy & executing request
 	proxyEth := ProxyETHCall{qtumClient}
 	proxyEthEstimateGas := ProxyETHEstimateGas{&proxyEth}
-	got, err := proxyEthEstimateGas.Request(requestRPC, nil)
+	got, err := proxyEthEstimateGas.Request(context.Background(), requestRPC, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -24,7 +25,7 @@ func TestGasPriceRequest(t *testing.T) {
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHGasPrice{qtumClient}
-	got, err := proxyEth.Request(request, nil)
+	got, err := proxyEth.Request(context.Background(), request, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
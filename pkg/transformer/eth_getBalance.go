@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -19,7 +20,7 @@ func (p *ProxyETHGetBalance) Method() string {
 	return "eth_getBalance"
 }
 
-func (p *ProxyETHGetBalance) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHGetBalance) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var req eth.GetBalanceRequest
 	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
 		return nil, err
@@ -29,7 +30,7 @@ func (p *ProxyETHGetBalance) Request(rawreq *eth.JSONRPCRequest, c echo.Context)
 	{
 		// is address a contract or an account?
 		qtumreq := qtum.GetAccountInfoRequest(addr)
-		qtumresp, err := p.GetAccountInfo(&qtumreq)
+		qtumresp, err := p.GetAccountInfo(ctx, &qtumreq)
 
 		// the address is a contract
 		if err == nil {
@@ -41,14 +42,14 @@ func (p *ProxyETHGetBalance) Request(rawreq *eth.JSONRPCRequest, c echo.Context)
 
 	{
 		// try account
-		base58Addr, err := p.FromHexAddress(addr)
+		base58Addr, err := p.FromHexAddress(ctx, addr)
 		if err != nil {
 			p.GetDebugLogger().Log("method", p.Method(), "address", req.Address, "msg", "error parsing address", "error", err)
 			return nil, err
 		}
 
 		qtumreq := qtum.GetAddressBalanceRequest{Address: base58Addr}
-		qtumresp, err := p.GetAddressBalance(&qtumreq)
+		qtumresp, err := p.GetAddressBalance(ctx, &qtumreq)
 		if err != nil {
 			if err == qtum.ErrInvalidAddress {
 				// invalid address should return 0x0
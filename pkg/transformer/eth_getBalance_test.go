@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -29,7 +30,7 @@ func TestGetBalanceRequestAccount(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	qtumClient.Accounts = append(qtumClient.Accounts, account)
+	qtumClient.ReloadAccounts(append(qtumClient.GetAccounts(), account))
 
 	//prepare responses
 	fromHexAddressResponse := qtum.FromHexAddressResponse("5JK4Gu9nxCvsCxiq9Zf3KdmA9ACza6dUn5BRLVWAYEtQabdnJ89")
@@ -50,7 +51,7 @@ func TestGetBalanceRequestAccount(t *testing.T) {
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHGetBalance{qtumClient}
-	got, err := proxyEth.Request(requestRPC, nil)
+	got, err := proxyEth.Request(context.Background(), requestRPC, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -85,7 +86,7 @@ func TestGetBalanceRequestContract(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	qtumClient.Accounts = append(qtumClient.Accounts, account)
+	qtumClient.ReloadAccounts(append(qtumClient.GetAccounts(), account))
 
 	//prepare responses
 	getAccountInfoResponse := qtum.GetAccountInfoResponse{
@@ -101,7 +102,7 @@ func TestGetBalanceRequestContract(t *testing.T) {
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHGetBalance{qtumClient}
-	got, err := proxyEth.Request(requestRPC, nil)
+	got, err := proxyEth.Request(context.Background(), requestRPC, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1,11 +1,14 @@
 package transformer
 
 import (
+	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/conversion"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/qtumproject/janus/pkg/utils"
@@ -14,24 +17,34 @@ import (
 // ProxyETHGetBlockByHash implements ETHProxy
 type ProxyETHGetBlockByHash struct {
 	*qtum.Qtum
+	blocks *blockCache
 }
 
 func (p *ProxyETHGetBlockByHash) Method() string {
 	return "eth_getBlockByHash"
 }
 
-func (p *ProxyETHGetBlockByHash) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHGetBlockByHash) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	req := new(eth.GetBlockByHashRequest)
 	if err := unmarshalRequest(rawreq.Params, req); err != nil {
 		return nil, err
 	}
 	req.BlockHash = utils.RemoveHexPrefix(req.BlockHash)
 
-	return p.request(req)
+	return p.request(ctx, req)
 }
 
-func (p *ProxyETHGetBlockByHash) request(req *eth.GetBlockByHashRequest) (*eth.GetBlockByHashResponse, error) {
-	blockHeader, err := p.GetBlockHeader(req.BlockHash)
+func (p *ProxyETHGetBlockByHash) request(ctx context.Context, req *eth.GetBlockByHashRequest) (*eth.GetBlockByHashResponse, error) {
+	q, err := p.Prefetch(ctx, []*qtum.BatchRequest{
+		qtum.NewBatchRequest(qtum.MethodGetBlockHeader, &qtum.GetBlockHeaderRequest{Hash: req.BlockHash}, nil),
+		getBlockBatchRequest(req.BlockHash),
+	})
+	if err != nil {
+		p.GetDebugLogger().Log("msg", "couldn't prefetch block", "blockHash", req.BlockHash, "error", err)
+		q = p.Qtum
+	}
+
+	blockHeader, err := q.GetBlockHeader(ctx, req.BlockHash)
 	if err != nil {
 		if err == qtum.ErrInvalidAddress {
 			// unknown block hash should return {result: null}
@@ -41,7 +54,7 @@ func (p *ProxyETHGetBlockByHash) request(req *eth.GetBlockByHashRequest) (*eth.G
 		p.GetDebugLogger().Log("msg", "couldn't get block header", "blockHash", req.BlockHash)
 		return nil, errors.WithMessage(err, "couldn't get block header")
 	}
-	block, err := p.GetBlock(req.BlockHash)
+	block, err := q.GetBlock(ctx, req.BlockHash)
 	if err != nil {
 		return nil, errors.WithMessage(err, "couldn't get block")
 	}
@@ -73,11 +86,6 @@ func (p *ProxyETHGetBlockByHash) request(req *eth.GetBlockByHashRequest) (*eth.G
 		// TODO: check value correctness
 		Sha3Uncles: eth.DefaultSha3Uncles,
 
-		// TODO: backlog
-		// ! Not found
-		// - Temporary expect this value to be always zero, as Etherium logs are usually zeros
-		LogsBloom: eth.EmptyLogsBloom,
-
 		// TODO: researching
 		// ? What value to put
 		// - Temporary set this value to be always zero
@@ -98,28 +106,28 @@ func (p *ProxyETHGetBlockByHash) request(req *eth.GetBlockByHashRequest) (*eth.G
 		resp.Miner = utils.AddHexPrefix(qtum.ZeroAddress)
 	} else {
 		resp.ParentHash = utils.AddHexPrefix(blockHeader.Previousblockhash)
-		// ! Not found
-		//
-		// NOTE:
-		// 	In order to find a miner it seems, that we have to check
-		// 	address field of the txout method response. Current
-		// 	suggestion is to fill this field with zeros, not to
-		// 	spend much time on requests execution
-		//
-		// TODO: check if it's value is acquirable via logs
-		resp.Miner = "0x0000000000000000000000000000000000000000"
 	}
 
-	// TODO: rethink later
-	// ! Found only for contracts transactions
-	// As there is no gas values presented at common block info, we set
-	// gas limit value equalling to default gas limit of a block
+	// NOTE:
+	// 	The DGP only holds the current gas limit, not the one of older
+	// 	blocks, so every block has the default gas limit
 	resp.GasLimit = utils.AddHexPrefix(qtum.DefaultBlockGasLimit)
-	resp.GasUsed = "0x0"
+
+	logsRequest := blockLogsRequest(block.Height)
+
+	logEvents := !p.GetFlagBool(qtum.FLAG_LOG_EVENTS_DISABLED)
 
 	if req.FullTransaction {
+		var extra []*qtum.BatchRequest
+		if logEvents {
+			extra = append(extra, qtum.NewBatchRequest(qtum.MethodSearchLogs, logsRequest, nil))
+			if _, ok := p.blocks.cumulativeGas(block.Hash); !ok {
+				extra = append(receiptsBatchRequests(block.Txs), extra...)
+			}
+		}
+		q = prefetchTransactions(ctx, q, block.Txs, extra...)
 		for _, txHash := range block.Txs {
-			tx, err := getTransactionByHash(p.Qtum, txHash)
+			tx, err := getTransactionByHash(ctx, q, txHash)
 			if err != nil {
 				return nil, errors.WithMessage(err, "couldn't get transaction by hash")
 			}
@@ -137,8 +145,6 @@ func (p *ProxyETHGetBlockByHash) request(req *eth.GetBlockByHashRequest) (*eth.G
 			} else {
 				resp.Transactions = append(resp.Transactions, *tx)
 			}
-			// TODO: fill gas used
-			// TODO: fill gas limit?
 		}
 	} else {
 		for _, txHash := range block.Txs {
@@ -151,5 +157,85 @@ func (p *ProxyETHGetBlockByHash) request(req *eth.GetBlockByHashRequest) (*eth.G
 		}
 	}
 
+	if !blockHeader.IsGenesisBlock() {
+		// resolved after the transactions so that it reuses them when they were prefetched
+		resp.Miner = "0x0000000000000000000000000000000000000000"
+		miner, err := getBlockMiner(ctx, q, p.blocks, blockHeader, block)
+		if err != nil {
+			p.GetDebugLogger().Log("msg", "couldn't get block miner", "blockHash", req.BlockHash, "error", err)
+		} else {
+			resp.Miner = miner
+		}
+	}
+
+	// gas is only found in the receipts of the contract transactions
+	resp.GasUsed = "0x0"
+	resp.LogsBloom = eth.EmptyLogsBloom
+	if !logEvents {
+		// qtumd only has receipts and searches logs with -logevents, the block is served without its gas and bloom
+		return resp, nil
+	}
+
+	if gas, err := getCumulativeGas(ctx, q, p.blocks, block.Hash, block.Txs); err != nil {
+		if p.logEventsDisabled(err) {
+			return resp, nil
+		}
+		p.GetErrorLogger().Log("msg", "couldn't get receipts of block", "blockHash", req.BlockHash, "error", err)
+	} else if len(gas) != 0 {
+		resp.GasUsed = hexutil.EncodeUint64(gas[len(gas)-1])
+	}
+
+	logsBloom, err := getLogsBloom(ctx, q, logsRequest, block.Hash)
+	if err != nil {
+		if !p.logEventsDisabled(err) {
+			p.GetErrorLogger().Log("msg", "couldn't compute logs bloom", "blockHash", req.BlockHash, "error", err)
+		}
+		return resp, nil
+	}
+	resp.LogsBloom = logsBloom.String()
+
 	return resp, nil
 }
+
+// logEventsDisabled tells whether err comes from qtumd running without -logevents,
+// remembering it so that the following blocks don't request receipts and logs
+func (p *ProxyETHGetBlockByHash) logEventsDisabled(err error) bool {
+	if errors.Cause(err) != qtum.ErrLogEventsDisabled {
+		return false
+	}
+	if !p.GetFlagBool(qtum.FLAG_LOG_EVENTS_DISABLED) {
+		p.GetLogger().Log("msg", "qtumd runs without -logevents, blocks are served without their gas used and logs bloom")
+		p.SetFlag(qtum.FLAG_LOG_EVENTS_DISABLED, true)
+	}
+	return true
+}
+
+// blockLogsRequest searches the receipts of the contract transactions of the block at height
+func blockLogsRequest(height int) *qtum.SearchLogsRequest {
+	return &qtum.SearchLogsRequest{
+		FromBlock: big.NewInt(int64(height)),
+		ToBlock:   big.NewInt(int64(height)),
+	}
+}
+
+// getLogsBloom merges the blooms of the receipts of the block found by req. The logs are
+// searched by height, so those of another block at the same height after a reorg are skipped
+func getLogsBloom(ctx context.Context, q *qtum.Qtum, req *qtum.SearchLogsRequest, blockHash string) (eth.Bloom, error) {
+	var bloom eth.Bloom
+	receipts, err := q.SearchLogs(ctx, req)
+	if err != nil {
+		return bloom, err
+	}
+	for i := range receipts {
+		receipt := &receipts[i]
+		if utils.RemoveHexPrefix(receipt.BlockHash) != utils.RemoveHexPrefix(blockHash) {
+			continue
+		}
+		receiptBloom, err := eth.LogsBloom(conversion.ExtractETHLogsFromTransactionReceipt(receipt, receipt.Log))
+		if err != nil {
+			return eth.Bloom{}, err
+		}
+		bloom.Or(receiptBloom)
+	}
+	return bloom, nil
+}
@@ -1,16 +1,20 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
+	"reflect"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/internal"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/qtumproject/janus/pkg/utils"
 )
 
 func initializeProxyETHGetBlockByHash(qtumClient *qtum.Qtum) ETHProxy {
-	return &ProxyETHGetBlockByHash{qtumClient}
+	return &ProxyETHGetBlockByHash{Qtum: qtumClient}
 }
 
 func TestGetBlockByHashRequestNonceLength(t *testing.T) {
@@ -36,3 +40,118 @@ func TestGetBlockByHashTransactionsRequest(t *testing.T) {
 		&internal.GetTransactionByHashResponseWithTransactions,
 	)
 }
+
+func TestGetBlockByHashReceipts(t *testing.T) {
+	request, err := internal.PrepareEthRPCRequest(1, []json.RawMessage{[]byte(`"` + internal.GetTransactionByHashBlockHexHash + `"`), []byte(`false`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer := qtum.Log{
+		Address: "db46f738bf32cdafb9a4a70eb8b44c76646bcaf0",
+		Topics: []string{
+			"ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+			"0000000000000000000000007926223070547d2d15b2ef5e7383e541c338ffe9",
+		},
+	}
+	anonymous := qtum.Log{Address: "0000000000000000000000000000000000000086"}
+	// the second transaction has two contract outputs
+	receipts := [][]qtum.TransactionReceipt{
+		{internal.QtumTransactionReceipt([]qtum.Log{transfer})},
+		{internal.QtumTransactionReceipt(nil), internal.QtumTransactionReceipt([]qtum.Log{anonymous})},
+	}
+	receipts[0][0].GasUsed = 51234
+	receipts[1][0].GasUsed = 21000
+	receipts[1][1].GasUsed = 30000
+	// logs of another block at the same height are left out of the bloom
+	reorged := internal.QtumTransactionReceipt([]qtum.Log{{Address: "00000000000000000000000000000000000000ff"}})
+	reorged.BlockHash = "6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be"
+	// answered before the empty logs of the block set up below
+	err = mockedClientDoer.AddResponse(qtum.MethodSearchLogs, qtum.SearchLogsResponse{receipts[0][0], reorged, receipts[1][1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.SetupGetBlockByHashResponses(t, mockedClientDoer)
+	for i, tx := range internal.GetBlockResponse.Txs {
+		err = mockedClientDoer.AddResponseWithParams(qtum.MethodGetTransactionReceipt, json.RawMessage(`["`+tx+`"]`), receipts[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	blocks := newBlockCache()
+	proxyEth := ProxyETHGetBlockByHash{Qtum: qtumClient, blocks: blocks}
+	got, err := proxyEth.Request(context.Background(), request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := got.(*eth.GetBlockByHashResponse)
+
+	// the receipts of the block aren't fetched again
+	gas, ok := blocks.cumulativeGas(internal.GetBlockResponse.Hash)
+	if want := []uint64{51234, 102234}; !ok || !reflect.DeepEqual(gas, want) {
+		t.Errorf("want cached cumulative gas %v, got %v", want, gas)
+	}
+
+	if want := "0x18f5a"; block.GasUsed != want {
+		t.Errorf("want gas used %s, got %s", want, block.GasUsed)
+	}
+	if want := "0x2625a00"; block.GasLimit != want {
+		t.Errorf("want gas limit %s, got %s", want, block.GasLimit)
+	}
+
+	var want eth.Bloom
+	for _, value := range []string{transfer.Address, transfer.Topics[0], transfer.Topics[1], anonymous.Address} {
+		want.Add(hexutil.MustDecode(utils.AddHexPrefix(value)))
+	}
+	if block.LogsBloom != want.String() {
+		t.Errorf("want logs bloom %s\ngot %s", want, block.LogsBloom)
+	}
+}
+
+func TestGetBlockByHashWithoutLogEvents(t *testing.T) {
+	request, err := internal.PrepareEthRPCRequest(1, []json.RawMessage{[]byte(`"` + internal.GetTransactionByHashBlockHexHash + `"`), []byte(`false`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// answered before the empty logs of the block set up below
+	err = mockedClientDoer.AddError(qtum.MethodSearchLogs, &eth.JSONRPCError{Code: -32603, Message: "Events indexing disabled"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.SetupGetBlockByHashResponses(t, mockedClientDoer)
+	err = mockedClientDoer.AddError(qtum.MethodGetTransactionReceipt, &eth.JSONRPCError{Code: -32603, Message: "Events indexing disabled"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHGetBlockByHash{Qtum: qtumClient}
+	for i := 0; i < 2; i++ {
+		got, err := proxyEth.Request(context.Background(), request, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block := got.(*eth.GetBlockByHashResponse)
+		if block.LogsBloom != eth.EmptyLogsBloom {
+			t.Errorf("want empty logs bloom, got %s", block.LogsBloom)
+		}
+		if block.GasUsed != "0x0" {
+			t.Errorf("want no gas used, got %s", block.GasUsed)
+		}
+		// the following blocks don't request receipts and logs
+		if !qtumClient.GetFlagBool(qtum.FLAG_LOG_EVENTS_DISABLED) {
+			t.Fatal("expected qtumd to be found running without -logevents")
+		}
+	}
+}
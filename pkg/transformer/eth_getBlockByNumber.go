@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -15,18 +16,19 @@ import (
 type ProxyETHGetBlockByNumber struct {
 	*qtum.Qtum
 	cacher *BlockSyncer
+	blocks *blockCache
 }
 
 func (p *ProxyETHGetBlockByNumber) Method() string {
 	return "eth_getBlockByNumber"
 }
 
-func (p *ProxyETHGetBlockByNumber) Request(rpcReq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHGetBlockByNumber) Request(ctx context.Context, rpcReq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	req := new(eth.GetBlockByNumberRequest)
 	if err := unmarshalRequest(rpcReq.Params, req); err != nil {
 		return nil, errors.WithMessage(err, "couldn't unmarhsal rpc request")
 	}
-	return p.request(req)
+	return p.request(ctx, req)
 }
 
 func (p *ProxyETHGetBlockByNumber) WithBlockCacher(cacher *BlockSyncer) *ProxyETHGetBlockByNumber {
@@ -34,7 +36,7 @@ func (p *ProxyETHGetBlockByNumber) WithBlockCacher(cacher *BlockSyncer) *ProxyET
 	return p
 }
 
-func (p *ProxyETHGetBlockByNumber) request(req *eth.GetBlockByNumberRequest) (*eth.GetBlockByNumberResponse, error) {
+func (p *ProxyETHGetBlockByNumber) request(ctx context.Context, req *eth.GetBlockByNumberRequest) (*eth.GetBlockByNumberResponse, error) {
 	if p.cacher != nil && !req.FullTransaction {
 		block, ok := p.cacher.GetBlock(req.BlockNumber)
 		if ok {
@@ -50,12 +52,12 @@ func (p *ProxyETHGetBlockByNumber) request(req *eth.GetBlockByNumberRequest) (*e
 		}
 	}
 
-	blockNum, err := getBlockNumberByRawParam(p.Qtum, req.BlockNumber, false)
+	blockNum, err := getBlockNumberByRawParam(ctx, p.Qtum, req.BlockNumber, false)
 	if err != nil {
 		return nil, errors.WithMessage(err, "couldn't get block number by parameter")
 	}
 
-	blockHash, err := proxyETHGetBlockByHash(p, p.Qtum, blockNum)
+	blockHash, err := proxyETHGetBlockByHash(ctx, p, p.Qtum, blockNum)
 	if err != nil {
 		return nil, err
 	}
@@ -68,9 +70,9 @@ func (p *ProxyETHGetBlockByNumber) request(req *eth.GetBlockByNumberRequest) (*e
 			BlockHash:       string(*blockHash),
 			FullTransaction: req.FullTransaction,
 		}
-		proxy = &ProxyETHGetBlockByHash{Qtum: p.Qtum}
+		proxy = &ProxyETHGetBlockByHash{Qtum: p.Qtum, blocks: p.blocks}
 	)
-	block, err := proxy.request(getBlockByHashReq)
+	block, err := proxy.request(ctx, getBlockByHashReq)
 	if err != nil {
 		return nil, errors.WithMessage(err, "couldn't get block by hash")
 	}
@@ -81,8 +83,8 @@ func (p *ProxyETHGetBlockByNumber) request(req *eth.GetBlockByNumberRequest) (*e
 }
 
 // Properly handle unknown blocks
-func proxyETHGetBlockByHash(p ETHProxy, q *qtum.Qtum, blockNum *big.Int) (*qtum.GetBlockHashResponse, error) {
-	resp, err := q.GetBlockHash(blockNum)
+func proxyETHGetBlockByHash(ctx context.Context, p ETHProxy, q *qtum.Qtum, blockNum *big.Int) (*qtum.GetBlockHashResponse, error) {
+	resp, err := q.GetBlockHash(ctx, blockNum)
 	if err != nil {
 		if err == qtum.ErrInvalidParameter {
 			// block doesn't exist, ETH rpc returns null
@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -49,7 +50,7 @@ func TestGetBlockByNumberUnknownBlockRequest(t *testing.T) {
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHGetBlockByNumber{Qtum: qtumClient}
-	got, err := proxyEth.Request(request, nil)
+	got, err := proxyEth.Request(context.Background(), request, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -30,7 +31,7 @@ func TestGetAccountInfoRequest(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	qtumClient.Accounts = append(qtumClient.Accounts, account)
+	qtumClient.ReloadAccounts(append(qtumClient.GetAccounts(), account))
 
 	//prepare responses
 	getAccountInfoResponse := qtum.GetAccountInfoResponse{
@@ -46,7 +47,7 @@ func TestGetAccountInfoRequest(t *testing.T) {
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHGetCode{qtumClient}
-	got, err := proxyEth.Request(requestRPC, nil)
+	got, err := proxyEth.Request(context.Background(), requestRPC, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -88,7 +89,7 @@ func TestGetCodeInvalidAddressRequest(t *testing.T) {
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHGetCode{qtumClient}
-	got, err := proxyEth.Request(requestRPC, nil)
+	got, err := proxyEth.Request(context.Background(), requestRPC, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
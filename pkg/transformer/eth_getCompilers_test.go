@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -17,7 +18,7 @@ func TestGetCompilersReturnsEmptyArray(t *testing.T) {
 	}
 
 	proxyEth := ETHGetCompilers{}
-	got, err := proxyEth.Request(request, nil)
+	got, err := proxyEth.Request(context.Background(), request, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
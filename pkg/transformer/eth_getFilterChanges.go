@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"math/big"
 
@@ -9,6 +10,7 @@ import (
 
 	"github.com/qtumproject/janus/pkg/conversion"
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/notifier"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/qtumproject/janus/pkg/utils"
 )
@@ -17,13 +19,14 @@ import (
 type ProxyETHGetFilterChanges struct {
 	*qtum.Qtum
 	filter *eth.FilterSimulator
+	agent  *notifier.Agent
 }
 
 func (p *ProxyETHGetFilterChanges) Method() string {
 	return "eth_getFilterChanges"
 }
 
-func (p *ProxyETHGetFilterChanges) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHGetFilterChanges) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 
 	filter, err := processFilter(p, rawreq)
 	if err != nil {
@@ -32,18 +35,18 @@ func (p *ProxyETHGetFilterChanges) Request(rawreq *eth.JSONRPCRequest, c echo.Co
 
 	switch filter.Type {
 	case eth.NewFilterTy:
-		return p.requestFilter(filter)
+		return p.requestFilter(ctx, filter)
 	case eth.NewBlockFilterTy:
-		return p.requestBlockFilter(filter)
+		return p.requestBlockFilter(ctx, filter)
 	case eth.NewPendingTransactionFilterTy:
-		fallthrough
+		return p.requestPendingTransactionFilter(filter)
 	default:
 
 		return nil, errors.New("Unknown filter type")
 	}
 }
 
-func (p *ProxyETHGetFilterChanges) requestBlockFilter(filter *eth.Filter) (qtumresp eth.GetFilterChangesResponse, err error) {
+func (p *ProxyETHGetFilterChanges) requestBlockFilter(ctx context.Context, filter *eth.Filter) (qtumresp eth.GetFilterChangesResponse, err error) {
 	qtumresp = make(eth.GetFilterChangesResponse, 0)
 
 	_lastBlockNumber, ok := filter.Data.Load("lastBlockNumber")
@@ -52,7 +55,7 @@ func (p *ProxyETHGetFilterChanges) requestBlockFilter(filter *eth.Filter) (qtumr
 	}
 	lastBlockNumber := _lastBlockNumber.(uint64)
 
-	blockCountBigInt, err := p.GetBlockCount()
+	blockCountBigInt, err := p.GetBlockCount(ctx)
 	if err != nil {
 		return qtumresp, err
 	}
@@ -64,7 +67,7 @@ func (p *ProxyETHGetFilterChanges) requestBlockFilter(filter *eth.Filter) (qtumr
 	for i := range hashes {
 		blockNumber := new(big.Int).SetUint64(lastBlockNumber + uint64(i) + 1)
 
-		resp, err := p.GetBlockHash(blockNumber)
+		resp, err := p.GetBlockHash(ctx, blockNumber)
 		if err != nil {
 			return qtumresp, err
 		}
@@ -76,7 +79,28 @@ func (p *ProxyETHGetFilterChanges) requestBlockFilter(filter *eth.Filter) (qtumr
 	filter.Data.Store("lastBlockNumber", blockCount)
 	return
 }
-func (p *ProxyETHGetFilterChanges) requestFilter(filter *eth.Filter) (qtumresp eth.GetFilterChangesResponse, err error) {
+
+func (p *ProxyETHGetFilterChanges) requestPendingTransactionFilter(filter *eth.Filter) (eth.GetFilterChangesResponse, error) {
+	if p.agent == nil {
+		return nil, errors.New("Pending transaction filters are not supported")
+	}
+
+	_cursor, ok := filter.Data.Load("pendingTransactionsCursor")
+	if !ok {
+		return nil, errors.New("Could not get pendingTransactionsCursor")
+	}
+
+	txids, cursor := p.agent.PendingTransactionsSince(_cursor.(uint64))
+	filter.Data.Store("pendingTransactionsCursor", cursor)
+
+	qtumresp := make(eth.GetFilterChangesResponse, len(txids))
+	for i, txid := range txids {
+		qtumresp[i] = txid
+	}
+	return qtumresp, nil
+}
+
+func (p *ProxyETHGetFilterChanges) requestFilter(ctx context.Context, filter *eth.Filter) (qtumresp eth.GetFilterChangesResponse, err error) {
 	qtumresp = make(eth.GetFilterChangesResponse, 0)
 
 	_lastBlockNumber, ok := filter.Data.Load("lastBlockNumber")
@@ -85,7 +109,7 @@ func (p *ProxyETHGetFilterChanges) requestFilter(filter *eth.Filter) (qtumresp e
 	}
 	lastBlockNumber := _lastBlockNumber.(uint64)
 
-	blockCountBigInt, err := p.GetBlockCount()
+	blockCountBigInt, err := p.GetBlockCount(ctx)
 	if err != nil {
 		return qtumresp, err
 	}
@@ -102,11 +126,11 @@ func (p *ProxyETHGetFilterChanges) requestFilter(filter *eth.Filter) (qtumresp e
 		return nil, err
 	}
 
-	return p.doSearchLogs(searchLogsReq)
+	return p.doSearchLogs(ctx, searchLogsReq)
 }
 
-func (p *ProxyETHGetFilterChanges) doSearchLogs(req *qtum.SearchLogsRequest) (eth.GetFilterChangesResponse, error) {
-	resp, err := conversion.SearchLogsAndFilterExtraTopics(p.Qtum, req)
+func (p *ProxyETHGetFilterChanges) doSearchLogs(ctx context.Context, req *qtum.SearchLogsRequest) (eth.GetFilterChangesResponse, error) {
+	resp, err := conversion.SearchLogsAndFilterExtraTopics(ctx, p.Qtum, req)
 	if err != nil {
 		return nil, err
 	}
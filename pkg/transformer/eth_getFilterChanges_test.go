@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"math/big"
 	"reflect"
@@ -49,8 +50,8 @@ func TestGetFilterChangesRequest_EmptyResult(t *testing.T) {
 	filter.Data.Store("lastBlockNumber", uint64(657655))
 
 	//preparing proxy & executing request
-	proxyEth := ProxyETHGetFilterChanges{qtumClient, filterSimulator}
-	got, err := proxyEth.Request(requestRPC, nil)
+	proxyEth := ProxyETHGetFilterChanges{Qtum: qtumClient, filter: filterSimulator}
+	got, err := proxyEth.Request(context.Background(), requestRPC, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -95,8 +96,8 @@ func TestGetFilterChangesRequest_NoNewBlocks(t *testing.T) {
 	filter.Data.Store("lastBlockNumber", uint64(657655))
 
 	//preparing proxy & executing request
-	proxyEth := ProxyETHGetFilterChanges{qtumClient, filterSimulator}
-	got, err := proxyEth.Request(requestRPC, nil)
+	proxyEth := ProxyETHGetFilterChanges{Qtum: qtumClient, filter: filterSimulator}
+	got, err := proxyEth.Request(context.Background(), requestRPC, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -128,8 +129,8 @@ func TestGetFilterChangesRequest_NoSuchFilter(t *testing.T) {
 
 	//preparing proxy & executing request
 	filterSimulator := eth.NewFilterSimulator()
-	proxyEth := ProxyETHGetFilterChanges{qtumClient, filterSimulator}
-	got, err := proxyEth.Request(requestRPC, nil)
+	proxyEth := ProxyETHGetFilterChanges{Qtum: qtumClient, filter: filterSimulator}
+	got, err := proxyEth.Request(context.Background(), requestRPC, nil)
 	expectedErr := "Invalid filter id"
 
 	if got != nil {
@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -264,7 +265,7 @@ func TestMultipleLogsWithORdTopics(t *testing.T) {
 	//preparing proxy & executing
 	proxyEth := ProxyETHGetLogs{qtumClient}
 
-	got, err := proxyEth.Request(requestRPC, nil)
+	got, err := proxyEth.Request(context.Background(), requestRPC, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -400,7 +401,7 @@ func testGetLogsWithTopics(t *testing.T, topics []interface{}, want eth.GetLogsR
 	//preparing proxy & executing
 	proxyEth := ProxyETHGetLogs{qtumClient}
 
-	got, err := proxyEth.Request(requestRPC, nil)
+	got, err := proxyEth.Request(context.Background(), requestRPC, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -451,7 +452,7 @@ func TestGetLogsTranslateTopicWorksWithNil(t *testing.T) {
 	//preparing proxy & executing
 	proxyEth := ProxyETHGetLogs{qtumClient}
 
-	qtumRequest, err := proxyEth.ToRequest(&request)
+	qtumRequest, err := proxyEth.ToRequest(context.Background(), &request)
 	if err != nil {
 		t.Fatal(err)
 	}
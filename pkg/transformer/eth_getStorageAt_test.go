@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -34,7 +35,7 @@ func TestGetStorageAtRequestWithNoLeadingZeros(t *testing.T) {
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHGetStorageAt{qtumClient}
-	got, err := proxyEth.Request(request, nil)
+	got, err := proxyEth.Request(context.Background(), request, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -75,7 +76,7 @@ func TestGetStorageAtRequestWithLeadingZeros(t *testing.T) {
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHGetStorageAt{qtumClient}
-	got, err := proxyEth.Request(request, nil)
+	got, err := proxyEth.Request(context.Background(), request, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -117,7 +118,7 @@ func TestGetStorageAtUnknownFieldRequest(t *testing.T) {
 
 	//preparing proxy & executing request
 	proxyEth := ProxyETHGetStorageAt{qtumClient}
-	got, err := proxyEth.Request(request, nil)
+	got, err := proxyEth.Request(context.Background(), request, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
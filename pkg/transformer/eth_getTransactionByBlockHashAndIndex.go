@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -8,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
 )
 
 // ProxyETHGetTransactionByBlockHashAndIndex implements ETHProxy
@@ -19,7 +21,7 @@ func (p *ProxyETHGetTransactionByBlockHashAndIndex) Method() string {
 	return "eth_getTransactionByBlockHashAndIndex"
 }
 
-func (p *ProxyETHGetTransactionByBlockHashAndIndex) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHGetTransactionByBlockHashAndIndex) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var req eth.GetTransactionByBlockHashAndIndex
 	if err := json.Unmarshal(rawreq.Params, &req); err != nil {
 		return nil, errors.Wrap(err, "couldn't unmarshal request")
@@ -28,30 +30,36 @@ func (p *ProxyETHGetTransactionByBlockHashAndIndex) Request(rawreq *eth.JSONRPCR
 		return nil, errors.New("invalid argument 0: empty hex string")
 	}
 
-	return p.request(&req)
+	return p.request(ctx, &req)
 }
 
-func (p *ProxyETHGetTransactionByBlockHashAndIndex) request(req *eth.GetTransactionByBlockHashAndIndex) (interface{}, error) {
+func (p *ProxyETHGetTransactionByBlockHashAndIndex) request(ctx context.Context, req *eth.GetTransactionByBlockHashAndIndex) (interface{}, error) {
 	transactionIndex, err := hexutil.DecodeUint64(req.TransactionIndex)
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid argument 1")
 	}
 
-	// Proxy eth_getBlockByHash and return the transaction at requested index
-	getBlockByNumber := ProxyETHGetBlockByHash{Qtum: p.Qtum}
-	blockByNumber, err := getBlockByNumber.request(&eth.GetBlockByHashRequest{BlockHash: req.BlockHash, FullTransaction: true})
-
+	// the block lists its transactions in order, skipped ones wouldn't shift the index
+	blockHash := utils.RemoveHexPrefix(req.BlockHash)
+	block, err := p.GetBlock(ctx, blockHash)
 	if err != nil {
-		return nil, err
+		if errors.Cause(err) == qtum.ErrInvalidAddress {
+			return nil, nil
+		}
+		return nil, errors.WithMessage(err, "couldn't get block")
 	}
-
-	if blockByNumber == nil {
+	if uint64(len(block.Txs)) <= transactionIndex {
 		return nil, nil
 	}
 
-	if len(blockByNumber.Transactions) <= int(transactionIndex) {
+	txHash := block.Txs[transactionIndex]
+	ethTx, err := getTransactionByHash(ctx, prefetchTransactions(ctx, p.Qtum, []string{txHash}), txHash)
+	if err != nil {
+		return nil, err
+	}
+	if ethTx == nil {
+		// the genesis coinbase can't be looked up
 		return nil, nil
 	}
-
-	return blockByNumber.Transactions[int(transactionIndex)], nil
+	return *ethTx, nil
 }
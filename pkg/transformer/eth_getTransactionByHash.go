@@ -1,7 +1,10 @@
 package transformer
 
 import (
+	"context"
+	"encoding/hex"
 	"encoding/json"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
@@ -20,7 +23,7 @@ func (p *ProxyETHGetTransactionByHash) Method() string {
 	return "eth_getTransactionByHash"
 }
 
-func (p *ProxyETHGetTransactionByHash) Request(req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHGetTransactionByHash) Request(ctx context.Context, req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var txHash eth.GetTransactionByHashRequest
 	if err := json.Unmarshal(req.Params, &txHash); err != nil {
 		return nil, errors.Wrap(err, "couldn't unmarshal request")
@@ -32,46 +35,62 @@ func (p *ProxyETHGetTransactionByHash) Request(req *eth.JSONRPCRequest, c echo.C
 	qtumReq := &qtum.GetTransactionRequest{
 		TxID: utils.RemoveHexPrefix(string(txHash)),
 	}
-	return p.request(qtumReq)
+	return p.request(ctx, qtumReq)
 }
 
-func (p *ProxyETHGetTransactionByHash) request(req *qtum.GetTransactionRequest) (*eth.GetTransactionByHashResponse, error) {
-	ethTx, err := getTransactionByHash(p.Qtum, req.TxID)
+func (p *ProxyETHGetTransactionByHash) request(ctx context.Context, req *qtum.GetTransactionRequest) (*eth.GetTransactionByHashResponse, error) {
+	ethTx, err := getTransactionByHash(ctx, prefetchTransactions(ctx, p.Qtum, []string{req.TxID}), req.TxID)
 	if err != nil {
 		return nil, err
 	}
 	return ethTx, nil
 }
 
-// TODO: think of returning flag if it's a reward transaction for miner
-func getTransactionByHash(p *qtum.Qtum, hash string) (*eth.GetTransactionByHashResponse, error) {
-	qtumTx, err := p.GetTransaction(hash)
+func getTransactionByHash(ctx context.Context, p *qtum.Qtum, hash string) (*eth.GetTransactionByHashResponse, error) {
+	qtumTx, err := p.GetTransaction(ctx, hash)
 	if err != nil {
 		if errors.Cause(err) != qtum.ErrInvalidAddress {
 			return nil, err
 		}
-		ethTx, err := getRewardTransactionByHash(p, hash)
+		// not a wallet transaction
+		qtumTx, err = getIndexedTransaction(ctx, p, hash)
 		if err != nil {
 			if errors.Cause(err) == qtum.ErrInvalidAddress {
 				return nil, nil
 			}
-			rawTx, err := p.GetRawTransaction(hash, false)
-			if err != nil {
-				if errors.Cause(err) == qtum.ErrInvalidAddress {
-					return nil, nil
-				}
-				return nil, err
-			} else {
-				qtumTx = &qtum.GetTransactionResponse{
-					BlockHash:  rawTx.BlockHash,
-					BlockIndex: 1, // TODO: Possible to get this somewhere?
-					Hex:        rawTx.Hex,
-				}
-			}
+			return nil, err
+		}
+	}
+	return newETHTransaction(ctx, p, qtumTx)
+}
+
+// getIndexedTransaction looks up any transaction indexed by qtumd, with its position in its block
+func getIndexedTransaction(ctx context.Context, p *qtum.Qtum, hash string) (*qtum.GetTransactionResponse, error) {
+	rawTx, err := p.GetRawTransaction(ctx, hash, false)
+	if err != nil {
+		return nil, err
+	}
+	qtumTx := &qtum.GetTransactionResponse{
+		ID:            hash,
+		BlockHash:     rawTx.BlockHash,
+		Confirmations: rawTx.Confirmations,
+		BlockTime:     rawTx.BlockTime,
+		Time:          rawTx.Time,
+		Hex:           rawTx.Hex,
+	}
+	if !rawTx.IsPending() {
+		if qtumTx.BlockIndex, err = getTransactionIndexInBlock(ctx, p, hash, rawTx.BlockHash); err != nil {
+			return nil, errors.WithMessage(err, "couldn't get transaction index in block")
 		}
-		return ethTx, nil
 	}
-	qtumDecodedRawTx, err := p.DecodeRawTransaction(qtumTx.Hex)
+	return qtumTx, nil
+}
+
+// newETHTransaction converts a Qtum transaction. Contract transactions are sent by their contract sender, the others
+// by the owner of the output spent by their first input to the first other address they pay, see
+// getNonContractTxSenderAddress and findNonContractTxReceiverAddress
+func newETHTransaction(ctx context.Context, p *qtum.Qtum, qtumTx *qtum.GetTransactionResponse) (*eth.GetTransactionByHashResponse, error) {
+	qtumDecodedRawTx, err := p.DecodeRawTransaction(ctx, qtumTx.Hex)
 	if err != nil {
 		return nil, errors.WithMessage(err, "couldn't get raw transaction")
 	}
@@ -79,16 +98,11 @@ func getTransactionByHash(p *qtum.Qtum, hash string) (*eth.GetTransactionByHashR
 	ethTx := &eth.GetTransactionByHashResponse{
 		Hash:  utils.AddHexPrefix(qtumDecodedRawTx.ID),
 		Nonce: "0x0",
-
-		// TODO: researching
-		// ? Do we need those values
-		V: "",
-		R: "",
-		S: "",
 	}
+	setTransactionSignature(ctx, p, ethTx, qtumTx.Hex)
 
 	if !qtumTx.IsPending() { // otherwise, the following values must be nulls
-		blockNumber, err := getBlockNumberByHash(p, qtumTx.BlockHash)
+		blockNumber, err := getBlockNumberByHash(ctx, p, qtumTx.BlockHash)
 		if err != nil {
 			return nil, errors.WithMessage(err, "couldn't get block number by hash")
 		}
@@ -129,36 +143,11 @@ func getTransactionByHash(p *qtum.Qtum, hash string) (*eth.GetTransactionByHashR
 		return ethTx, nil
 	}
 
-	if qtumTx.Generated {
-		ethTx.From = utils.AddHexPrefix(qtum.ZeroAddress)
-	} else {
-		// TODO: Figure out proper way to do this
-		// There is a problem with this function, sometimes it returns errors on regtest, empty block?
-		// commenting it out as its being overwritten below anyway
-		/*
-			ethTx.From, err = getNonContractTxSenderAddress(p, qtumDecodedRawTx.Vins)
-			if err != nil {
-				return nil, errors.WithMessage(err, "couldn't get non contract transaction sender address")
-			}
-		*/
-		// TODO: discuss
-		// ? Does func above return incorrect address for graph-node (len is < 40)
-		// ! Temporary solution
-		ethTx.From = utils.AddHexPrefix(qtum.ZeroAddress)
-	}
-	ethTx.To, err = findNonContractTxReceiverAddress(qtumDecodedRawTx.Vouts)
+	ethTx.From, err = getNonContractTxSenderAddress(ctx, p, qtumDecodedRawTx.Vins)
 	if err != nil {
-		// TODO: discuss, research
-		// ? Some vouts doesn't have `receive` category at all
-		ethTx.To = utils.AddHexPrefix(qtum.ZeroAddress)
-
-		// TODO: uncomment, after todo above will be resolved
-		// return nil, errors.WithMessage(err, "couldn't get non contract transaction receiver address")
+		return nil, errors.WithMessage(err, "couldn't get non contract transaction sender address")
 	}
-	// TODO: discuss
-	// ? Does func above return incorrect address for graph-node (len is < 40)
-	// ! Temporary solution
-	ethTx.To = utils.AddHexPrefix(qtum.ZeroAddress)
+	ethTx.To = findNonContractTxReceiverAddress(qtumDecodedRawTx.Vouts, ethTx.From)
 
 	// TODO: researching
 	// ! Temporary solution
@@ -177,86 +166,38 @@ func getTransactionByHash(p *qtum.Qtum, hash string) (*eth.GetTransactionByHashR
 	return ethTx, nil
 }
 
-// TODO: discuss
-// ? There are `witness` transactions, that is not acquireable nither via `gettransaction`, nor `getrawtransaction`
-func getRewardTransactionByHash(p *qtum.Qtum, hash string) (*eth.GetTransactionByHashResponse, error) {
-	rawQtumTx, err := p.GetRawTransaction(hash, false)
-	if err != nil {
-		return nil, errors.WithMessage(err, "couldn't get raw reward transaction")
-	}
-
-	ethTx := &eth.GetTransactionByHashResponse{
-		Hash:  utils.AddHexPrefix(hash),
-		Nonce: "0x0",
-
-		// TODO: discuss
-		// ? Expect this value to be always zero
-		// Geth returns 0x if there is no input data for a transaction
-		Input: "0x",
-
-		// TODO: discuss
-		// ? Are zero values applicable
-		Gas:      "0x0",
-		GasPrice: "0x0",
-
-		// TODO: researching
-		// ? Do we need those values
-		V: "",
-		R: "",
-		S: "",
-	}
-
-	if !rawQtumTx.IsPending() {
-		blockIndex, err := getTransactionIndexInBlock(p, hash, rawQtumTx.BlockHash)
-		if err != nil {
-			return nil, errors.WithMessage(err, "couldn't get transaction index in block")
-		}
-		ethTx.TransactionIndex = hexutil.EncodeUint64(uint64(blockIndex))
-
-		blockNumber, err := getBlockNumberByHash(p, rawQtumTx.BlockHash)
-		if err != nil {
-			return nil, errors.WithMessage(err, "couldn't get block number by hash")
-		}
-		ethTx.BlockNumber = hexutil.EncodeUint64(blockNumber)
-
-		ethTx.BlockHash = utils.AddHexPrefix(rawQtumTx.BlockHash)
-	}
+// setTransactionSignature fills v, r and s from the signature of the sender of a Qtum transaction, v commits to the
+// chain id as in EIP-155. Transactions without a signature, like coinbases, and those whose signer can't be
+// recovered have zero values
+func setTransactionSignature(ctx context.Context, p *qtum.Qtum, ethTx *eth.GetTransactionByHashResponse, txHex string) {
+	ethTx.V, ethTx.R, ethTx.S = "0x0", "0x0", "0x0"
 
-	for i := range rawQtumTx.Vouts {
-		// TODO: discuss
-		// ! The response may be null, even if txout is presented
-		_, err := p.GetTransactionOut(hash, i, rawQtumTx.IsPending())
+	sig, err := qtum.ExtractTransactionSignature(txHex, func(txID string, index uint32) ([]byte, error) {
+		// only P2PK spends, mostly coinstakes, look up the spent output
+		prevTx, err := p.GetRawTransaction(ctx, txID, false)
 		if err != nil {
-			return nil, errors.WithMessage(err, "couldn't get transaction out")
-		}
-		// TODO: discuss, researching
-		// ? Where is a reward amount
-		ethTx.Value = "0x0"
-	}
-
-	// TODO: discuss
-	// ? Do we have to set `from` == `0x00..00`
-	ethTx.From = utils.AddHexPrefix(qtum.ZeroAddress)
-
-	// I used Base58AddressToHex at the moment
-	// because convertQtumAddress functions causes error for
-	// P2Sh address(such as MUrenj2sPqEVTiNbHQ2RARiZYyTAAeKiDX) and BECH32 address (such as qc1qkt33x6hkrrlwlr6v59wptwy6zskyrjfe40y0lx)
-	if rawQtumTx.OP_SENDER != "" {
-		// addr, err := convertQtumAddress(rawQtumTx.OP_SENDER)
-		addr, err := p.Base58AddressToHex(rawQtumTx.OP_SENDER)
-		if err == nil {
-			ethTx.From = utils.AddHexPrefix(addr)
+			return nil, err
 		}
-	} else if len(rawQtumTx.Vins) > 0 && rawQtumTx.Vins[0].Address != "" {
-		// addr, err := convertQtumAddress(rawQtumTx.Vins[0].Address)
-		addr, err := p.Base58AddressToHex(rawQtumTx.Vins[0].Address)
-		if err == nil {
-			ethTx.From = utils.AddHexPrefix(addr)
+		if int(index) >= len(prevTx.Vouts) {
+			return nil, errors.Errorf("transaction %s has no output %d", txID, index)
 		}
-	}
-	// TODO: discuss
-	// ? Where is a `to`
-	ethTx.To = utils.AddHexPrefix(qtum.ZeroAddress)
-
-	return ethTx, nil
+		return hex.DecodeString(prevTx.Vouts[index].Details.Hex)
+	})
+	if err != nil {
+		p.GetDebugLogger().Log("msg", "couldn't extract transaction signature", "hash", ethTx.Hash, "error", err)
+		return
+	}
+	if sig == nil {
+		return
+	}
+	if !sig.Recovered {
+		p.GetDebugLogger().Log("msg", "couldn't recover transaction signer", "hash", ethTx.Hash)
+		return
+	}
+	chainId, _ := getChainId(p.Chain())
+	v := new(big.Int).Lsh(chainId, 1)
+	v.Add(v, big.NewInt(35+int64(sig.RecoveryID)))
+	ethTx.V = hexutil.EncodeBig(v)
+	ethTx.R = hexutil.EncodeBig(sig.R)
+	ethTx.S = hexutil.EncodeBig(sig.S)
 }
@@ -1,37 +1,64 @@
 package transformer
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
+	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
 )
 
 // ProxyETHEstimateGas implements ETHProxy
 type ProxyETHTxCount struct {
 	*qtum.Qtum
+	nonces *NonceTracker
 }
 
 func (p *ProxyETHTxCount) Method() string {
 	return "eth_getTransactionCount"
 }
 
-func (p *ProxyETHTxCount) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
-
-	/* not sure we need this. Need to figure out how to best unmarshal this in the future. For now this will work.
+func (p *ProxyETHTxCount) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var req eth.GetTransactionCountRequest
 	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
 		return nil, err
-	}*/
-	qtumresp, err := p.Qtum.GetTransactionCount("", "")
+	}
+	if !utils.IsEthHexAddress(req.Address) {
+		return nil, errors.Errorf("invalid address: %s", req.Address)
+	}
+
+	count, err := p.request(ctx, &req)
 	if err != nil {
 		return nil, err
 	}
 
 	// qtum res -> eth res
-	return p.response(qtumresp), nil
+	return p.response(new(big.Int).SetUint64(count)), nil
+}
+
+func (p *ProxyETHTxCount) request(ctx context.Context, req *eth.GetTransactionCountRequest) (uint64, error) {
+	switch req.Tag {
+	case "earliest":
+		return 0, nil
+	case "pending":
+		return p.nonces.NextNonce(ctx, req.Address)
+	case "", "latest":
+		blockCount, err := p.GetBlockCount(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return p.nonces.ConfirmedCount(ctx, req.Address, blockCount.Int64())
+	default:
+		height, err := utils.DecodeBig(req.Tag)
+		if err != nil {
+			return 0, errors.Wrap(err, "invalid block number")
+		}
+		return p.nonces.ConfirmedCount(ctx, req.Address, height.Int64())
+	}
 }
 
 func (p *ProxyETHTxCount) response(qtumresp *big.Int) string {
@@ -1,41 +1,104 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
+	"math/big"
 	"reflect"
 	"testing"
 
 	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/shopspring/decimal"
 )
 
 func TestGetTransactionCountRequest(t *testing.T) {
-	//preparing request
-	requestParams := []json.RawMessage{}
-	request, err := internal.PrepareEthRPCRequest(1, requestParams)
-	if err != nil {
-		t.Fatal(err)
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{`"latest"`, "0x2"},
+		{`"pending"`, "0x3"},
+		{`"earliest"`, "0x0"},
 	}
 
+	for _, test := range tests {
+		//preparing request
+		requestParams := []json.RawMessage{[]byte(`"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"`), []byte(test.tag)}
+		request, err := internal.PrepareEthRPCRequest(1, requestParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mockedClientDoer := internal.NewDoerMappedMock()
+		qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = mockedClientDoer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(1000)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// two spends within the same transaction count once, received outputs aren't counted
+		err = mockedClientDoer.AddResponse(qtum.MethodGetAddressDeltas, qtum.GetAddressDeltasResponse{
+			{TXID: "a1", Satoshis: decimal.NewFromInt(100000), Height: 10},
+			{TXID: "b2", Satoshis: decimal.NewFromInt(-50000), Height: 20},
+			{TXID: "b2", Satoshis: decimal.NewFromInt(-50000), Height: 20},
+			{TXID: "c3", Satoshis: decimal.NewFromInt(-20000), Height: 950},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = mockedClientDoer.AddResponse(qtum.MethodGetAddressMempool, qtum.GetAddressMempoolResponse{
+			{TXID: "d4", Satoshis: decimal.NewFromInt(-10000), PrevTxID: "a1"},
+			{TXID: "d4", Satoshis: decimal.NewFromInt(5000)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		//preparing proxy & executing request
+		proxyEth := ProxyETHTxCount{Qtum: qtumClient, nonces: NewNonceTracker(qtumClient)}
+		got, err := proxyEth.Request(context.Background(), request, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf(
+				"error\ninput: %s\nwant: %s\ngot: %s",
+				request,
+				test.want,
+				got,
+			)
+		}
+	}
+}
+
+func TestNonceTrackerCachesSettledBlocks(t *testing.T) {
 	mockedClientDoer := internal.NewDoerMappedMock()
 	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	//preparing proxy & executing request
-	proxyEth := ProxyETHTxCount{qtumClient}
-	got, err := proxyEth.Request(request, nil)
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressDeltas, qtum.GetAddressDeltasResponse{
+		{TXID: "b2", Satoshis: decimal.NewFromInt(-50000), Height: 20},
+		{TXID: "c3", Satoshis: decimal.NewFromInt(-20000), Height: 950},
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	want := string("0x1") //tx count is hardcoded inside the implement
-	if !reflect.DeepEqual(got, want) {
-		t.Errorf(
-			"error\ninput: %s\nwant: %s\ngot: %s",
-			request,
-			want,
-			got,
-		)
+	nonces := NewNonceTracker(qtumClient)
+	const address = "1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"
+	if count, err := nonces.ConfirmedCount(context.Background(), address, 1000); err != nil || count != 2 {
+		t.Fatalf("count %d, error %v", count, err)
+	}
+
+	// only the transaction deep enough not to be reorganized is cached
+	if settled := nonces.settled[address]; settled.height != 900 || settled.count != 1 {
+		t.Fatalf("unexpected settled count: %+v", settled)
 	}
 }
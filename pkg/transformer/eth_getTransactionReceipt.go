@@ -1,6 +1,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
@@ -16,13 +18,14 @@ var STATUS_FAILURE = "0x0"
 // ProxyETHGetTransactionReceipt implements ETHProxy
 type ProxyETHGetTransactionReceipt struct {
 	*qtum.Qtum
+	blocks *blockCache
 }
 
 func (p *ProxyETHGetTransactionReceipt) Method() string {
 	return "eth_getTransactionReceipt"
 }
 
-func (p *ProxyETHGetTransactionReceipt) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHGetTransactionReceipt) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var req eth.GetTransactionReceiptRequest
 	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
 		return nil, err
@@ -34,14 +37,16 @@ func (p *ProxyETHGetTransactionReceipt) Request(rawreq *eth.JSONRPCRequest, c ec
 		txHash  = utils.RemoveHexPrefix(string(req))
 		qtumReq = qtum.GetTransactionReceiptRequest(txHash)
 	)
-	return p.request(&qtumReq)
+	return p.request(ctx, &qtumReq)
 }
 
-func (p *ProxyETHGetTransactionReceipt) request(req *qtum.GetTransactionReceiptRequest) (*eth.GetTransactionReceiptResponse, error) {
-	qtumReceipt, err := p.Qtum.GetTransactionReceipt(string(*req))
+func (p *ProxyETHGetTransactionReceipt) request(ctx context.Context, req *qtum.GetTransactionReceiptRequest) (*eth.GetTransactionReceiptResponse, error) {
+	q := prefetchReceipt(ctx, p.Qtum, string(*req))
+	qtumReceipt, err := q.GetTransactionReceipt(ctx, string(*req))
 	if err != nil {
-		ethTx, getRewardTransactionErr := getRewardTransactionByHash(p.Qtum, string(*req))
-		if getRewardTransactionErr != nil {
+		// transactions without contract outputs have no receipt
+		qtumTx, getTransactionErr := getIndexedTransaction(ctx, q, string(*req))
+		if getTransactionErr != nil {
 			errCause := errors.Cause(err)
 			if errCause == qtum.EmptyResponseErr {
 				return nil, nil
@@ -49,15 +54,30 @@ func (p *ProxyETHGetTransactionReceipt) request(req *qtum.GetTransactionReceiptR
 			p.Qtum.GetDebugLogger().Log("msg", "Transaction does not exist", "txid", string(*req))
 			return nil, err
 		}
+		ethTx, err := newETHTransaction(ctx, q, qtumTx)
+		if err != nil {
+			return nil, errors.WithMessage(err, "couldn't convert transaction")
+		}
+		// transactions without contract outputs don't use gas
+		cumulativeGas := uint64(0)
+		if ethTx.BlockHash != "" {
+			index, err := hexutil.DecodeUint64(ethTx.TransactionIndex)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid transaction index")
+			}
+			if cumulativeGas, err = cumulativeGasUsed(ctx, q, p.blocks, utils.RemoveHexPrefix(ethTx.BlockHash), index); err != nil {
+				// the receipt is still served, without the gas of the transactions before it
+				p.GetDebugLogger().Log("msg", "couldn't get cumulative gas used", "txid", string(*req), "error", err)
+			}
+		}
 		return &eth.GetTransactionReceiptResponse{
-			TransactionHash:  ethTx.Hash,
-			TransactionIndex: ethTx.TransactionIndex,
-			BlockHash:        ethTx.BlockHash,
-			BlockNumber:      ethTx.BlockNumber,
-			// TODO: This is higher than GasUsed in geth but does it matter?
-			CumulativeGasUsed: NonContractVMGasLimit,
+			TransactionHash:   ethTx.Hash,
+			TransactionIndex:  ethTx.TransactionIndex,
+			BlockHash:         ethTx.BlockHash,
+			BlockNumber:       ethTx.BlockNumber,
+			CumulativeGasUsed: hexutil.EncodeUint64(cumulativeGas),
 			EffectiveGasPrice: "0x0",
-			GasUsed:           NonContractVMGasLimit,
+			GasUsed:           "0x0",
 			From:              ethTx.From,
 			To:                ethTx.To,
 			Logs:              []eth.Log{},
@@ -67,19 +87,14 @@ func (p *ProxyETHGetTransactionReceipt) request(req *qtum.GetTransactionReceiptR
 	}
 
 	ethReceipt := &eth.GetTransactionReceiptResponse{
-		TransactionHash:   utils.AddHexPrefix(qtumReceipt.TransactionHash),
-		TransactionIndex:  hexutil.EncodeUint64(qtumReceipt.TransactionIndex),
-		BlockHash:         utils.AddHexPrefix(qtumReceipt.BlockHash),
-		BlockNumber:       hexutil.EncodeUint64(qtumReceipt.BlockNumber),
-		ContractAddress:   utils.AddHexPrefixIfNotEmpty(qtumReceipt.ContractAddress),
-		CumulativeGasUsed: hexutil.EncodeUint64(qtumReceipt.CumulativeGasUsed),
-		GasUsed:           hexutil.EncodeUint64(qtumReceipt.GasUsed),
-		From:              utils.AddHexPrefixIfNotEmpty(qtumReceipt.From),
-		To:                utils.AddHexPrefixIfNotEmpty(qtumReceipt.To),
-
-		// TODO: researching
-		// ! Temporary accept this value to be always zero, as it is at eth logs
-		LogsBloom: eth.EmptyLogsBloom,
+		TransactionHash:  utils.AddHexPrefix(qtumReceipt.TransactionHash),
+		TransactionIndex: hexutil.EncodeUint64(qtumReceipt.TransactionIndex),
+		BlockHash:        utils.AddHexPrefix(qtumReceipt.BlockHash),
+		BlockNumber:      hexutil.EncodeUint64(qtumReceipt.BlockNumber),
+		ContractAddress:  utils.AddHexPrefixIfNotEmpty(qtumReceipt.ContractAddress),
+		GasUsed:          hexutil.EncodeUint64(qtumReceipt.GasUsed),
+		From:             utils.AddHexPrefixIfNotEmpty(qtumReceipt.From),
+		To:               utils.AddHexPrefixIfNotEmpty(qtumReceipt.To),
 	}
 
 	status := STATUS_FAILURE
@@ -92,12 +107,26 @@ func (p *ProxyETHGetTransactionReceipt) request(req *qtum.GetTransactionReceiptR
 
 	r := qtum.TransactionReceipt(*qtumReceipt)
 	ethReceipt.Logs = conversion.ExtractETHLogsFromTransactionReceipt(&r, r.Log)
+	logsBloom, err := eth.LogsBloom(ethReceipt.Logs)
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't compute logs bloom")
+	}
+	ethReceipt.LogsBloom = logsBloom.String()
+
+	// the cumulative gas qtumd reports only counts the outputs of the transaction, it is kept when the
+	// receipts of the block can't be fetched
+	cumulativeGas, err := cumulativeGasUsed(ctx, q, p.blocks, qtumReceipt.BlockHash, qtumReceipt.TransactionIndex)
+	if err != nil {
+		p.GetDebugLogger().Log("msg", "couldn't get cumulative gas used", "txid", qtumReceipt.TransactionHash, "error", err)
+		cumulativeGas = qtumReceipt.CumulativeGasUsed
+	}
+	ethReceipt.CumulativeGasUsed = hexutil.EncodeUint64(cumulativeGas)
 
-	qtumTx, err := p.Qtum.GetRawTransaction(qtumReceipt.TransactionHash, false)
+	qtumTx, err := q.GetRawTransaction(ctx, qtumReceipt.TransactionHash, false)
 	if err != nil {
 		return nil, errors.WithMessage(err, "couldn't get transaction")
 	}
-	decodedRawQtumTx, err := p.Qtum.DecodeRawTransaction(qtumTx.Hex)
+	decodedRawQtumTx, err := q.DecodeRawTransaction(ctx, qtumTx.Hex)
 	if err != nil {
 		return nil, errors.WithMessage(err, "couldn't decode raw transaction")
 	}
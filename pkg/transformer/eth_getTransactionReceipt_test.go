@@ -1,10 +1,12 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/internal"
 	"github.com/qtumproject/janus/pkg/qtum"
@@ -31,10 +33,38 @@ func TestGetTransactionReceiptForNonVMTransaction(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	prevTxID := "7f5350dc474f2953a3f30282c1afcad2fb61cdcea5bd949c808ecc6f64ce1503"
 	rawTransactionResponse := &qtum.GetRawTransactionResponse{
 		BlockHash: internal.GetTransactionByHashBlockHash,
+		Vins:      []qtum.RawTransactionVin{{ID: prevTxID, VoutN: 1}},
 	}
-	err = mockedClientDoer.AddResponseWithRequestID(3, qtum.MethodGetRawTransaction, rawTransactionResponse)
+	err = mockedClientDoer.AddResponseWithParams(qtum.MethodGetRawTransaction, json.RawMessage(`["8fcd819194cce6a8454b2bec334d3448df4f097e9cdc36707bfd569900268950",true]`), rawTransactionResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first input spends an output of the sender
+	prevTransactionResponse := &qtum.GetRawTransactionResponse{
+		Vouts: make([]qtum.RawTransactionVout, 2),
+	}
+	prevTransactionResponse.Vouts[1].Details.Hex = "76a9147926223070547d2d15b2ef5e7383e541c338ffe988ac"
+	err = mockedClientDoer.AddResponseWithParams(qtum.MethodGetRawTransaction, json.RawMessage(`["`+prevTxID+`",true]`), prevTransactionResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// paying the receiver and the change back to the sender
+	decodedTransactionResponse := &qtum.DecodedRawTransactionResponse{
+		ID:   "8fcd819194cce6a8454b2bec334d3448df4f097e9cdc36707bfd569900268950",
+		Vins: []*qtum.DecodedRawTransactionInV{{TxID: prevTxID, Vout: 1}},
+		Vouts: []*qtum.DecodedRawTransactionOutV{
+			new(qtum.DecodedRawTransactionOutV),
+			new(qtum.DecodedRawTransactionOutV),
+		},
+	}
+	decodedTransactionResponse.Vouts[0].ScriptPubKey.Hex = "76a9147926223070547d2d15b2ef5e7383e541c338ffe988ac"
+	decodedTransactionResponse.Vouts[1].ScriptPubKey.Hex = "76a9146b22910b1e302cf74803ffd1691c2ecb858d371288ac"
+	err = mockedClientDoer.AddResponse(qtum.MethodDecodeRawTransaction, decodedTransactionResponse)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -45,8 +75,8 @@ func TestGetTransactionReceiptForNonVMTransaction(t *testing.T) {
 	}
 
 	//preparing proxy & executing request
-	proxyEth := ProxyETHGetTransactionReceipt{qtumClient}
-	got, err := proxyEth.Request(request, nil)
+	proxyEth := ProxyETHGetTransactionReceipt{Qtum: qtumClient}
+	got, err := proxyEth.Request(context.Background(), request, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -56,12 +86,12 @@ func TestGetTransactionReceiptForNonVMTransaction(t *testing.T) {
 		TransactionIndex:  "0x1",
 		BlockHash:         "0xbba11e1bacc69ba535d478cf1f2e542da3735a517b0b8eebaf7e6bb25eeb48c5",
 		BlockNumber:       "0xf8f",
-		GasUsed:           NonContractVMGasLimit,
+		GasUsed:           "0x0",
 		Logs:              []eth.Log{},
 		EffectiveGasPrice: "0x0",
-		CumulativeGasUsed: NonContractVMGasLimit,
-		To:                utils.AddHexPrefix(qtum.ZeroAddress),
-		From:              utils.AddHexPrefix(qtum.ZeroAddress),
+		CumulativeGasUsed: "0x0",
+		To:                "0x6b22910b1e302cf74803ffd1691c2ecb858d3712",
+		From:              "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
 		LogsBloom:         eth.EmptyLogsBloom,
 		Status:            STATUS_SUCCESS,
 	}
@@ -74,3 +104,112 @@ func TestGetTransactionReceiptForNonVMTransaction(t *testing.T) {
 		)
 	}
 }
+
+func TestGetTransactionReceiptForContractTransaction(t *testing.T) {
+	requestParams := []json.RawMessage{[]byte(`"0x8fcd819194cce6a8454b2bec334d3448df4f097e9cdc36707bfd569900268950"`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transfer := qtum.Log{
+		Address: "db46f738bf32cdafb9a4a70eb8b44c76646bcaf0",
+		Topics: []string{
+			"ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+			"0000000000000000000000007926223070547d2d15b2ef5e7383e541c338ffe9",
+		},
+	}
+	// the receipt of the second transaction of the block, after one using 21000 gas
+	receipt := internal.QtumTransactionReceipt([]qtum.Log{transfer})
+	receipt.TransactionHash = internal.GetBlockResponse.Txs[1]
+	receipt.TransactionIndex = 1
+	receipt.GasUsed = 51234
+	receipt.CumulativeGasUsed = 51234
+	receipt.Excepted = "None"
+	previous := internal.QtumTransactionReceipt(nil)
+	previous.GasUsed = 21000
+	for i, receipts := range [][]qtum.TransactionReceipt{{previous}, {receipt}} {
+		err = mockedClientDoer.AddResponseWithParams(qtum.MethodGetTransactionReceipt, json.RawMessage(`["`+internal.GetBlockResponse.Txs[i]+`"]`), receipts)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = mockedClientDoer.AddResponse(qtum.MethodGetRawTransaction, &qtum.GetRawTransactionResponse{BlockHash: internal.GetTransactionByHashBlockHash}); err != nil {
+		t.Fatal(err)
+	}
+	if err = mockedClientDoer.AddResponse(qtum.MethodDecodeRawTransaction, &qtum.DecodedRawTransactionResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = mockedClientDoer.AddResponse(qtum.MethodGetBlock, internal.GetBlockResponse); err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHGetTransactionReceipt{Qtum: qtumClient}
+	got, err := proxyEth.Request(context.Background(), request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ethReceipt := got.(*eth.GetTransactionReceiptResponse)
+	if ethReceipt.GasUsed != "0xc822" || ethReceipt.CumulativeGasUsed != "0x11a2a" {
+		t.Errorf("want gas used 0xc822 and cumulative gas used 0x11a2a, got %s and %s", ethReceipt.GasUsed, ethReceipt.CumulativeGasUsed)
+	}
+
+	var want eth.Bloom
+	for _, value := range []string{transfer.Address, transfer.Topics[0], transfer.Topics[1]} {
+		want.Add(hexutil.MustDecode(utils.AddHexPrefix(value)))
+	}
+	if ethReceipt.LogsBloom != want.String() {
+		t.Errorf("want logs bloom %s\ngot %s", want, ethReceipt.LogsBloom)
+	}
+}
+
+func TestGetTransactionReceiptWithoutBlockReceipts(t *testing.T) {
+	requestParams := []json.RawMessage{[]byte(`"0x8fcd819194cce6a8454b2bec334d3448df4f097e9cdc36707bfd569900268950"`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt := internal.QtumTransactionReceipt(nil)
+	receipt.TransactionHash = internal.GetBlockResponse.Txs[1]
+	receipt.TransactionIndex = 1
+	receipt.GasUsed = 51234
+	receipt.CumulativeGasUsed = 51234
+	receipt.Excepted = "None"
+	err = mockedClientDoer.AddResponseWithParams(qtum.MethodGetTransactionReceipt, json.RawMessage(`["`+internal.GetBlockResponse.Txs[1]+`"]`), []qtum.TransactionReceipt{receipt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = mockedClientDoer.AddResponse(qtum.MethodGetRawTransaction, &qtum.GetRawTransactionResponse{BlockHash: internal.GetTransactionByHashBlockHash}); err != nil {
+		t.Fatal(err)
+	}
+	if err = mockedClientDoer.AddResponse(qtum.MethodDecodeRawTransaction, &qtum.DecodedRawTransactionResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	// the block is pruned or unknown to qtumd
+	if err = mockedClientDoer.AddError(qtum.MethodGetBlock, &eth.JSONRPCError{Code: -1, Message: "Block not available (pruned data)"}); err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHGetTransactionReceipt{Qtum: qtumClient, blocks: newBlockCache()}
+	got, err := proxyEth.Request(context.Background(), request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cumulativeGas := got.(*eth.GetTransactionReceiptResponse).CumulativeGasUsed; cumulativeGas != "0xc822" {
+		t.Errorf("want the cumulative gas used reported by qtumd 0xc822, got %s", cumulativeGas)
+	}
+}
@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -16,7 +17,7 @@ func TestGetUncleByBlockHashAndIndexReturnsNil(t *testing.T) {
 	}
 
 	proxyEth := ETHGetUncleByBlockHashAndIndex{}
-	got, err := proxyEth.Request(request, nil)
+	got, err := proxyEth.Request(context.Background(), request, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
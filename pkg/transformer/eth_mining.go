@@ -1,6 +1,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/labstack/echo"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
@@ -15,12 +17,12 @@ func (p *ProxyETHMining) Method() string {
 	return "eth_mining"
 }
 
-func (p *ProxyETHMining) Request(_ *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
-	return p.request()
+func (p *ProxyETHMining) Request(ctx context.Context, _ *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+	return p.request(ctx)
 }
 
-func (p *ProxyETHMining) request() (*eth.MiningResponse, error) {
-	qtumresp, err := p.Qtum.GetMining()
+func (p *ProxyETHMining) request(ctx context.Context) (*eth.MiningResponse, error) {
+	qtumresp, err := p.Qtum.GetMining(ctx)
 	if err != nil {
 		return nil, err
 	}
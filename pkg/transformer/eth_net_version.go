@@ -1,6 +1,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/labstack/echo"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
@@ -15,13 +17,13 @@ func (p *ProxyETHNetVersion) Method() string {
 	return "net_version"
 }
 
-func (p *ProxyETHNetVersion) Request(_ *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
-	return p.request()
+func (p *ProxyETHNetVersion) Request(ctx context.Context, _ *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+	return p.request(ctx)
 }
 
-func (p *ProxyETHNetVersion) request() (*eth.NetVersionResponse, error) {
+func (p *ProxyETHNetVersion) request(ctx context.Context) (*eth.NetVersionResponse, error) {
 	var qtumresp *qtum.GetBlockChainInfoResponse
-	if err := p.Qtum.Request(qtum.MethodGetBlockChainInfo, nil, &qtumresp); err != nil {
+	if err := p.Qtum.RequestWithContext(ctx, qtum.MethodGetBlockChainInfo, nil, &qtumresp); err != nil {
 		return nil, err
 	}
 
@@ -1,6 +1,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
 	"github.com/qtumproject/janus/pkg/eth"
@@ -17,12 +19,12 @@ func (p *ProxyETHNewBlockFilter) Method() string {
 	return "eth_newBlockFilter"
 }
 
-func (p *ProxyETHNewBlockFilter) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
-	return p.request()
+func (p *ProxyETHNewBlockFilter) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+	return p.request(ctx)
 }
 
-func (p *ProxyETHNewBlockFilter) request() (eth.NewBlockFilterResponse, error) {
-	blockCount, err := p.GetBlockCount()
+func (p *ProxyETHNewBlockFilter) request(ctx context.Context) (eth.NewBlockFilterResponse, error) {
+	blockCount, err := p.GetBlockCount(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -31,7 +33,7 @@ func (p *ProxyETHNewBlockFilter) request() (eth.NewBlockFilterResponse, error) {
 	filter.Data.Store("lastBlockNumber", blockCount.Uint64())
 
 	if p.CanGenerate() {
-		p.GenerateIfPossible()
+		p.GenerateIfPossible(ctx)
 	}
 
 	return eth.NewBlockFilterResponse(hexutil.EncodeUint64(filter.ID)), nil
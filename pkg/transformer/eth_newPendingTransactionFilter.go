@@ -0,0 +1,39 @@
+package transformer
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/notifier"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+// ProxyETHNewPendingTransactionFilter implements ETHProxy
+type ProxyETHNewPendingTransactionFilter struct {
+	*qtum.Qtum
+	filter *eth.FilterSimulator
+	agent  *notifier.Agent
+}
+
+func (p *ProxyETHNewPendingTransactionFilter) Method() string {
+	return "eth_newPendingTransactionFilter"
+}
+
+func (p *ProxyETHNewPendingTransactionFilter) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+	return p.request()
+}
+
+func (p *ProxyETHNewPendingTransactionFilter) request() (eth.NewPendingTransactionFilterResponse, error) {
+	if p.agent == nil {
+		return "", errors.New("Pending transaction filters are not supported")
+	}
+
+	// the agent polls the mempool while the filter is being read, shared with 'newPendingTransactions' subscriptions
+	filter := p.filter.New(eth.NewPendingTransactionFilterTy)
+	filter.Data.Store("pendingTransactionsCursor", p.agent.NewPendingTransactionsCursor())
+
+	return eth.NewPendingTransactionFilterResponse(hexutil.EncodeUint64(filter.ID)), nil
+}
@@ -0,0 +1,87 @@
+package transformer
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/notifier"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestNewPendingTransactionFilterRequest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	request, err := internal.PrepareEthRPCRequest(1, []json.RawMessage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mockedClientDoer.AddResponse(qtum.MethodGetRawMempool, qtum.GetRawMempoolResponse{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetRawMempool, qtum.GetRawMempoolResponse{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agent := notifier.NewAgent(ctx, qtumClient, nil)
+	filterSimulator := eth.NewFilterSimulator()
+
+	proxyEth := ProxyETHNewPendingTransactionFilter{Qtum: qtumClient, filter: filterSimulator, agent: agent}
+	got, err := proxyEth.Request(context.Background(), request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := eth.NewPendingTransactionFilterResponse("0x1"); got != want {
+		t.Fatalf("unexpected filter id\nwant: %s\ngot: %s", want, got)
+	}
+
+	request, err = internal.PrepareEthRPCRequest(1, []json.RawMessage{[]byte(`"0x1"`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	getFilterChanges := ProxyETHGetFilterChanges{Qtum: qtumClient, filter: filterSimulator, agent: agent}
+
+	want := eth.GetFilterChangesResponse{"0xb"}
+	var changes interface{}
+	// the mempool is polled in the background, the second snapshot shows up after the default interval
+	for i := 0; i < 50; i++ {
+		changes, err = getFilterChanges.Request(context.Background(), request, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changes.(eth.GetFilterChangesResponse)) > 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf(
+			"error\ninput: %s\nwant: %s\ngot: %s",
+			request,
+			string(internal.MustMarshalIndent(want, "", "  ")),
+			string(internal.MustMarshalIndent(changes, "", "  ")),
+		)
+	}
+
+	changes, err = getFilterChanges.Request(context.Background(), request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.(eth.GetFilterChangesResponse)) != 0 {
+		t.Fatalf("pending transactions reported twice: %v", changes)
+	}
+}
@@ -1,6 +1,14 @@
 package transformer
 
 import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/eth"
@@ -11,6 +19,11 @@ import (
 // ProxyETHSendRawTransaction implements ETHProxy
 type ProxyETHSendRawTransaction struct {
 	*qtum.Qtum
+	nonces *NonceTracker
+
+	// nonces of the converted transactions not in the mempool yet, so that
+	// a transaction replayed concurrently is rejected
+	reservations nonceReservations
 }
 
 var _ ETHProxy = (*ProxyETHSendRawTransaction)(nil)
@@ -19,7 +32,7 @@ func (p *ProxyETHSendRawTransaction) Method() string {
 	return "eth_sendRawTransaction"
 }
 
-func (p *ProxyETHSendRawTransaction) Request(req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHSendRawTransaction) Request(ctx context.Context, req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var params eth.SendRawTransactionRequest
 	if err := unmarshalRequest(req.Params, &params); err != nil {
 		return nil, err
@@ -28,21 +41,132 @@ func (p *ProxyETHSendRawTransaction) Request(req *eth.JSONRPCRequest, c echo.Con
 		return nil, errors.Errorf("invalid parameter: raw transaction hexed string is empty")
 	}
 
-	return p.request(params)
+	if rawTx, err := hex.DecodeString(utils.RemoveHexPrefix(params[0])); err == nil && eth.IsRawTransaction(rawTx) {
+		return p.requestEthereumTransaction(ctx, rawTx, params[1])
+	}
+
+	return p.request(ctx, params)
+}
+
+// requestEthereumTransaction converts a signed Ethereum transaction into an equivalent Qtum transaction
+// spending the signer's UTXOs, signs it and broadcasts it. Its nonce must be the signer's next one, which
+// keeps it from being replayed
+func (p *ProxyETHSendRawTransaction) requestEthereumTransaction(ctx context.Context, rawTx []byte, qtumKey string) (eth.SendRawTransactionResponse, error) {
+	tx, err := eth.DecodeRawTransaction(rawTx)
+	if err != nil {
+		return eth.SendRawTransactionResponse(""), errors.WithMessage(err, "invalid raw transaction")
+	}
+
+	if tx.ChainID != nil {
+		chainId, _ := getChainId(p.Chain())
+		if tx.ChainID.Cmp(chainId) != 0 {
+			return eth.SendRawTransactionResponse(""), errors.Errorf("invalid chain id: %s, expected %s", hexutil.EncodeBig(tx.ChainID), hexutil.EncodeBig(chainId))
+		}
+	}
+
+	pubKey, err := tx.Sender()
+	if err != nil {
+		return eth.SendRawTransactionResponse(""), err
+	}
+
+	from, key, err := p.findSender(pubKey, qtumKey)
+	if err != nil {
+		return eth.SendRawTransactionResponse(""), err
+	}
+
+	err = p.reservations.reserve(from, tx.Nonce, func() (uint64, error) {
+		return p.nonces.NextNonce(ctx, from)
+	})
+	if err != nil {
+		return eth.SendRawTransactionResponse(""), err
+	}
+	// once broadcast, the transaction is counted by NextNonce
+	defer p.reservations.release(from, tx.Nonce)
+
+	value := tx.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	req := &eth.SendTransactionRequest{
+		From:     utils.AddHexPrefix(from),
+		Gas:      &eth.ETHInt{Int: new(big.Int).SetUint64(tx.Gas)},
+		GasPrice: &eth.ETHInt{Int: tx.GasPrice},
+		Value:    hexutil.EncodeBig(value),
+		Nonce:    hexutil.EncodeUint64(tx.Nonce),
+	}
+	if !tx.IsCreateContract() {
+		req.To = hexutil.Encode(tx.To)
+	}
+	if len(tx.Data) != 0 {
+		req.Data = hexutil.Encode(tx.Data)
+	}
+
+	p.GetDebugLogger().Log("method", p.Method(), "msg", "converting ethereum transaction", "from", req.From, "to", req.To, "type", tx.Type)
+
+	signer := &ProxyETHSignTransaction{Qtum: p.Qtum, key: key}
+	signedTx, err := signer.request(ctx, req)
+	if err != nil {
+		return eth.SendRawTransactionResponse(""), err
+	}
+
+	return p.request(ctx, eth.SendRawTransactionRequest{signedTx})
 }
 
-func (p *ProxyETHSendRawTransaction) request(params eth.SendRawTransactionRequest) (eth.SendRawTransactionResponse, error) {
+// findSender returns the hex address of the transaction signer and the key signing the converted transaction.
+// A Qtum WIF key supplied with the request must match the signer and signs for it, a supplied public key must
+// match the signer and selects the serialization of its loaded account. Otherwise the signer must be one of the
+// loaded accounts
+func (p *ProxyETHSendRawTransaction) findSender(pubKey *btcec.PublicKey, qtumKey string) (string, *btcutil.WIF, error) {
+	if qtumKey != "" {
+		if key, err := btcutil.DecodeWIF(qtumKey); err == nil {
+			if !key.PrivKey.PubKey().IsEqual(pubKey) {
+				return "", nil, errors.New("transaction signer doesn't match the supplied qtum key")
+			}
+			return hex.EncodeToString(btcutil.Hash160(key.SerializePubKey())), key, nil
+		}
+
+		serialized, err := hex.DecodeString(utils.RemoveHexPrefix(qtumKey))
+		if err != nil {
+			return "", nil, errors.Wrap(err, "invalid qtum public key")
+		}
+		suppliedPubKey, err := btcec.ParsePubKey(serialized, btcec.S256())
+		if err != nil {
+			return "", nil, errors.Wrap(err, "invalid qtum public key")
+		}
+		if !suppliedPubKey.IsEqual(pubKey) {
+			return "", nil, errors.New("transaction signer doesn't match the supplied qtum public key")
+		}
+		hexAddress := hex.EncodeToString(btcutil.Hash160(serialized))
+		key := p.GetAccounts().FindByHexAddress(hexAddress)
+		if key == nil {
+			return "", nil, errors.Errorf("No account to sign for %s, supply its WIF key instead", hexAddress)
+		}
+		return hexAddress, key, nil
+	}
+
+	// accounts may have been imported with either a compressed or an uncompressed public key
+	for _, serialized := range [][]byte{pubKey.SerializeCompressed(), pubKey.SerializeUncompressed()} {
+		hexAddress := hex.EncodeToString(btcutil.Hash160(serialized))
+		if key := p.GetAccounts().FindByHexAddress(hexAddress); key != nil {
+			return hexAddress, key, nil
+		}
+	}
+
+	return "", nil, errors.Errorf("No account matches transaction signer: %x", pubKey.SerializeCompressed())
+}
+
+func (p *ProxyETHSendRawTransaction) request(ctx context.Context, params eth.SendRawTransactionRequest) (eth.SendRawTransactionResponse, error) {
 	var (
 		qtumHexedRawTx = utils.RemoveHexPrefix(params[0])
 		req            = qtum.SendRawTransactionRequest([1]string{qtumHexedRawTx})
 	)
 
-	qtumresp, err := p.Qtum.SendRawTransaction(&req)
+	qtumresp, err := p.Qtum.SendRawTransaction(ctx, &req)
 	if err != nil {
 		if err == qtum.ErrVerifyAlreadyInChain {
 			// already committed
 			// we need to send back the tx hash
-			rawTx, err := p.Qtum.DecodeRawTransaction(qtumHexedRawTx)
+			rawTx, err := p.Qtum.DecodeRawTransaction(ctx, qtumHexedRawTx)
 			if err != nil {
 				p.GetErrorLogger().Log("msg", "Error decoding raw transaction for duplicate raw transaction", "err", err)
 				return eth.SendRawTransactionResponse(""), err
@@ -53,7 +177,7 @@ func (p *ProxyETHSendRawTransaction) request(params eth.SendRawTransactionReques
 		}
 	} else {
 		if p.CanGenerate() {
-			p.GenerateIfPossible()
+			p.GenerateIfPossible(ctx)
 		}
 	}
 
@@ -61,3 +185,82 @@ func (p *ProxyETHSendRawTransaction) request(params eth.SendRawTransactionReques
 	ethHexedTxHash := utils.AddHexPrefix(resp.Result)
 	return eth.SendRawTransactionResponse(ethHexedTxHash), nil
 }
+
+// nonceReservations holds the nonces of the converted transactions of each sender from their nonce check
+// until they reach the mempool, where NextNonce counts them. Its zero value is ready to use
+type nonceReservations struct {
+	mutex   sync.Mutex
+	senders map[string]*senderReservations
+}
+
+type senderReservations struct {
+	// held while checking a nonce of the sender
+	mutex  sync.Mutex
+	nonces map[uint64]bool
+	// refs counts the requests using the entry, which is removed along with the last one
+	refs int
+}
+
+// reserve checks that nonce is the next one of sender, after its reserved nonces, and reserves it. next
+// returns the next nonce of sender from qtumd. A reserved nonce must be released
+func (r *nonceReservations) reserve(sender string, nonce uint64, next func() (uint64, error)) error {
+	s := r.acquire(sender)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expected, err := next()
+	if err != nil {
+		r.unref(sender)
+		return errors.WithMessage(err, "couldn't get sender nonce")
+	}
+	for reserved := range s.nonces {
+		if reserved >= expected {
+			expected = reserved + 1
+		}
+	}
+	if nonce != expected {
+		r.unref(sender)
+		if nonce < expected {
+			return errors.Errorf("nonce too low: %d, expected %d", nonce, expected)
+		}
+		return errors.Errorf("nonce too high: %d, expected %d", nonce, expected)
+	}
+	s.nonces[nonce] = true
+	return nil
+}
+
+func (r *nonceReservations) release(sender string, nonce uint64) {
+	r.mutex.Lock()
+	s := r.senders[sender]
+	r.mutex.Unlock()
+
+	s.mutex.Lock()
+	delete(s.nonces, nonce)
+	s.mutex.Unlock()
+	r.unref(sender)
+}
+
+func (r *nonceReservations) acquire(sender string) *senderReservations {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.senders == nil {
+		r.senders = make(map[string]*senderReservations)
+	}
+	s, ok := r.senders[sender]
+	if !ok {
+		s = &senderReservations{nonces: make(map[uint64]bool)}
+		r.senders[sender] = s
+	}
+	s.refs++
+	return s
+}
+
+func (r *nonceReservations) unref(sender string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s := r.senders[sender]
+	s.refs--
+	if s.refs == 0 {
+		delete(r.senders, sender)
+	}
+}
@@ -0,0 +1,309 @@
+package transformer
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/shopspring/decimal"
+)
+
+var testEthereumSignerKey, _ = hex.DecodeString("4646464646464646464646464646464646464646464646464646464646464646")
+
+// signLegacyTransaction builds an EIP-155 signed transaction sending to a contract
+func signLegacyTransaction(t *testing.T, chainID int64) string {
+	to, _ := hex.DecodeString("3535353535353535353535353535353535353535")
+	unsigned := []interface{}{uint64(0), big.NewInt(40000000000), uint64(250000), to, big.NewInt(0), []byte{0x01, 0x02}}
+
+	encoded, err := rlp.EncodeToBytes(append(unsigned, big.NewInt(chainID), uint(0), uint(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), testEthereumSignerKey)
+	sig, err := btcec.SignCompact(btcec.S256(), privKey, crypto.Keccak256(encoded), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := big.NewInt(chainID*2 + 35 + int64(sig[0]-27))
+	encoded, err = rlp.EncodeToBytes(append(unsigned, v, new(big.Int).SetBytes(sig[1:33]), new(big.Int).SetBytes(sig[33:])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "0x" + hex.EncodeToString(encoded)
+}
+
+func TestSendRawEthereumTransactionRequest(t *testing.T) {
+	requestParams := []json.RawMessage{[]byte(`"` + signLegacyTransaction(t, 8889) + `"`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), testEthereumSignerKey)
+	account, err := btcutil.NewWIF(privKey, &chaincfg.MainNetParams, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qtumClient.ReloadAccounts(append(qtumClient.GetAccounts(), account))
+
+	err = mockedClientDoer.AddResponse(qtum.MethodGetNetworkInfo, qtum.NetworkInfoResponse{RelayFee: decimal.NewFromFloat(0.004)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodEstimateSmartFee, qtum.EstimateSmartFeeResponse{Errors: []string{"Insufficient data or no feerate found"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressUTXOs, qtum.GetAddressUTXOsResponse{
+		{
+			Address:     "qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW",
+			TXID:        "7e1f6e0a2a0ad7e9c0bd0dff3e0a3a1a0a6a2a1f7b4f0c0e2b9a6b1d7e2c3a4b",
+			OutputIndex: 1,
+			Satoshis:    decimal.NewFromInt(100000000),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(1000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressDeltas, qtum.GetAddressDeltasResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressMempool, qtum.GetAddressMempoolResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodSendRawTx, "d2a5ab5c6d8e3f1f5d19f6e0aab1f5cb4e5b6d3b1b0bd3c7f4f7cd5b0a1d2e3f")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHSendRawTransaction{Qtum: qtumClient, nonces: NewNonceTracker(qtumClient)}
+	got, err := proxyEth.Request(context.Background(), request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := eth.SendRawTransactionResponse("0xd2a5ab5c6d8e3f1f5d19f6e0aab1f5cb4e5b6d3b1b0bd3c7f4f7cd5b0a1d2e3f")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf(
+			"error\ninput: %s\nwant: %s\ngot: %s",
+			request,
+			string(internal.MustMarshalIndent(want, "", "  ")),
+			string(internal.MustMarshalIndent(got, "", "  ")),
+		)
+	}
+}
+
+func TestSendRawEthereumTransactionRejectsWrongChainId(t *testing.T) {
+	requestParams := []json.RawMessage{[]byte(`"` + signLegacyTransaction(t, 1) + `"`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHSendRawTransaction{Qtum: qtumClient, nonces: NewNonceTracker(qtumClient)}
+	if _, err := proxyEth.Request(context.Background(), request, nil); err == nil {
+		t.Fatal("expected transaction signed for another chain to be rejected")
+	}
+}
+
+func TestSendRawEthereumTransactionRejectsUnknownSigner(t *testing.T) {
+	requestParams := []json.RawMessage{[]byte(`"` + signLegacyTransaction(t, 8889) + `"`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHSendRawTransaction{Qtum: qtumClient, nonces: NewNonceTracker(qtumClient)}
+	if _, err := proxyEth.Request(context.Background(), request, nil); err == nil {
+		t.Fatal("expected transaction signed by an unknown key to be rejected")
+	}
+}
+
+func TestSendRawEthereumTransactionRejectsReplayedNonce(t *testing.T) {
+	requestParams := []json.RawMessage{[]byte(`"` + signLegacyTransaction(t, 8889) + `"`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), testEthereumSignerKey)
+	account, err := btcutil.NewWIF(privKey, &chaincfg.MainNetParams, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qtumClient.ReloadAccounts(append(qtumClient.GetAccounts(), account))
+
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(1000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the transaction with nonce 0 was already sent
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressDeltas, qtum.GetAddressDeltasResponse{
+		{TXID: "b2", Satoshis: decimal.NewFromInt(-50000), Height: 950},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressMempool, qtum.GetAddressMempoolResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHSendRawTransaction{Qtum: qtumClient, nonces: NewNonceTracker(qtumClient)}
+	if _, err := proxyEth.Request(context.Background(), request, nil); err == nil {
+		t.Fatal("expected transaction with an already used nonce to be rejected")
+	}
+}
+
+func TestSendRawEthereumTransactionSignedWithSuppliedKey(t *testing.T) {
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), testEthereumSignerKey)
+	key, err := btcutil.NewWIF(privKey, &chaincfg.MainNetParams, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestParams := []json.RawMessage{[]byte(`"` + signLegacyTransaction(t, 8889) + `"`), []byte(`"` + key.String() + `"`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the signer isn't a loaded account
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mockedClientDoer.AddResponse(qtum.MethodGetNetworkInfo, qtum.NetworkInfoResponse{RelayFee: decimal.NewFromFloat(0.004)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodEstimateSmartFee, qtum.EstimateSmartFeeResponse{Errors: []string{"Insufficient data or no feerate found"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressUTXOs, qtum.GetAddressUTXOsResponse{
+		{
+			Address:     "qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW",
+			TXID:        "7e1f6e0a2a0ad7e9c0bd0dff3e0a3a1a0a6a2a1f7b4f0c0e2b9a6b1d7e2c3a4b",
+			OutputIndex: 1,
+			Satoshis:    decimal.NewFromInt(100000000),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(1000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressDeltas, qtum.GetAddressDeltasResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressMempool, qtum.GetAddressMempoolResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodSendRawTx, "d2a5ab5c6d8e3f1f5d19f6e0aab1f5cb4e5b6d3b1b0bd3c7f4f7cd5b0a1d2e3f")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHSendRawTransaction{Qtum: qtumClient, nonces: NewNonceTracker(qtumClient)}
+	got, err := proxyEth.Request(context.Background(), request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := eth.SendRawTransactionResponse("0xd2a5ab5c6d8e3f1f5d19f6e0aab1f5cb4e5b6d3b1b0bd3c7f4f7cd5b0a1d2e3f")
+	if got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+
+	// a key of another signer is rejected
+	otherKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), []byte("another signer key of 32 bytes!!"))
+	other, err := btcutil.NewWIF(otherKey, &chaincfg.MainNetParams, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestParams[1] = []byte(`"` + other.String() + `"`)
+	request, err = internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proxyEth.Request(context.Background(), request, nil); err == nil {
+		t.Fatal("expected a key not matching the signer to be rejected")
+	}
+}
+
+func TestNonceReservations(t *testing.T) {
+	var reservations nonceReservations
+	next := func() (uint64, error) { return 5, nil }
+
+	if err := reservations.reserve("a", 5, next); err != nil {
+		t.Fatal(err)
+	}
+	// the reserved nonce isn't in the mempool yet
+	if err := reservations.reserve("a", 5, next); err == nil {
+		t.Fatal("expected a reserved nonce to be rejected")
+	}
+	if err := reservations.reserve("a", 7, next); err == nil {
+		t.Fatal("expected a nonce after the next one to be rejected")
+	}
+	if err := reservations.reserve("a", 6, next); err != nil {
+		t.Fatal(err)
+	}
+	// senders don't share nonces
+	if err := reservations.reserve("b", 5, next); err != nil {
+		t.Fatal(err)
+	}
+
+	reservations.release("a", 5)
+	reservations.release("a", 6)
+	reservations.release("b", 5)
+	if len(reservations.senders) != 0 {
+		t.Fatalf("expected the released senders to be removed, %d left", len(reservations.senders))
+	}
+}
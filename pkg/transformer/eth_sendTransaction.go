@@ -1,6 +1,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/eth"
@@ -11,6 +13,9 @@ import (
 
 var MinimumGasLimit = int64(22000)
 
+// MaximumGasLimit caps the gas of eth_call, calls above it are answered with 0x as qtumd would reject them
+var MaximumGasLimit = int64(40000000)
+
 // ProxyETHSendTransaction implements ETHProxy
 type ProxyETHSendTransaction struct {
 	*qtum.Qtum
@@ -20,7 +25,7 @@ func (p *ProxyETHSendTransaction) Method() string {
 	return "eth_sendTransaction"
 }
 
-func (p *ProxyETHSendTransaction) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHSendTransaction) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var req eth.SendTransactionRequest
 	err := unmarshalRequest(rawreq.Params, &req)
 	if err != nil {
@@ -34,23 +39,23 @@ func (p *ProxyETHSendTransaction) Request(rawreq *eth.JSONRPCRequest, c echo.Con
 	var result interface{}
 
 	if req.IsCreateContract() {
-		result, err = p.requestCreateContract(&req)
+		result, err = p.requestCreateContract(ctx, &req)
 	} else if req.IsSendEther() {
-		result, err = p.requestSendToAddress(&req)
+		result, err = p.requestSendToAddress(ctx, &req)
 	} else if req.IsCallContract() {
-		result, err = p.requestSendToContract(&req)
+		result, err = p.requestSendToContract(ctx, &req)
 	} else {
 		return nil, errors.New("Unknown operation")
 	}
 
 	if p.CanGenerate() && err == nil {
-		p.GenerateIfPossible()
+		p.GenerateIfPossible(ctx)
 	}
 
 	return result, err
 }
 
-func (p *ProxyETHSendTransaction) requestSendToContract(ethtx *eth.SendTransactionRequest) (*eth.SendTransactionResponse, error) {
+func (p *ProxyETHSendTransaction) requestSendToContract(ctx context.Context, ethtx *eth.SendTransactionRequest) (*eth.SendTransactionResponse, error) {
 	gasLimit, gasPrice, err := EthGasToQtum(ethtx)
 	if err != nil {
 		return nil, err
@@ -74,7 +79,7 @@ func (p *ProxyETHSendTransaction) requestSendToContract(ethtx *eth.SendTransacti
 	}
 
 	if from := ethtx.From; from != "" && utils.IsEthHexAddress(from) {
-		from, err = p.FromHexAddress(from)
+		from, err = p.FromHexAddress(ctx, from)
 		if err != nil {
 			return nil, err
 		}
@@ -82,7 +87,7 @@ func (p *ProxyETHSendTransaction) requestSendToContract(ethtx *eth.SendTransacti
 	}
 
 	var resp *qtum.SendToContractResponse
-	if err := p.Qtum.Request(qtum.MethodSendToContract, &qtumreq, &resp); err != nil {
+	if err := p.Qtum.RequestWithContext(ctx, qtum.MethodSendToContract, &qtumreq, &resp); err != nil {
 		return nil, err
 	}
 
@@ -90,10 +95,10 @@ func (p *ProxyETHSendTransaction) requestSendToContract(ethtx *eth.SendTransacti
 	return &ethresp, nil
 }
 
-func (p *ProxyETHSendTransaction) requestSendToAddress(req *eth.SendTransactionRequest) (*eth.SendTransactionResponse, error) {
+func (p *ProxyETHSendTransaction) requestSendToAddress(ctx context.Context, req *eth.SendTransactionRequest) (*eth.SendTransactionResponse, error) {
 	getQtumWalletAddress := func(addr string) (string, error) {
 		if utils.IsEthHexAddress(addr) {
-			return p.FromHexAddress(utils.RemoveHexPrefix(addr))
+			return p.FromHexAddress(ctx, utils.RemoveHexPrefix(addr))
 		}
 		return addr, nil
 	}
@@ -122,7 +127,7 @@ func (p *ProxyETHSendTransaction) requestSendToAddress(req *eth.SendTransactionR
 	}
 
 	var qtumresp qtum.SendToAddressResponse
-	if err := p.Qtum.Request(qtum.MethodSendToAddress, &qtumreq, &qtumresp); err != nil {
+	if err := p.Qtum.RequestWithContext(ctx, qtum.MethodSendToAddress, &qtumreq, &qtumresp); err != nil {
 		// this can fail with:
 		// "error": {
 		//   "code": -3,
@@ -138,7 +143,7 @@ func (p *ProxyETHSendTransaction) requestSendToAddress(req *eth.SendTransactionR
 	return &ethresp, nil
 }
 
-func (p *ProxyETHSendTransaction) requestCreateContract(req *eth.SendTransactionRequest) (*eth.SendTransactionResponse, error) {
+func (p *ProxyETHSendTransaction) requestCreateContract(ctx context.Context, req *eth.SendTransactionRequest) (*eth.SendTransactionResponse, error) {
 	gasLimit, gasPrice, err := EthGasToQtum(req)
 	if err != nil {
 		return nil, err
@@ -153,7 +158,7 @@ func (p *ProxyETHSendTransaction) requestCreateContract(req *eth.SendTransaction
 	if req.From != "" {
 		from := req.From
 		if utils.IsEthHexAddress(from) {
-			from, err = p.FromHexAddress(from)
+			from, err = p.FromHexAddress(ctx, from)
 			if err != nil {
 				return nil, err
 			}
@@ -163,7 +168,7 @@ func (p *ProxyETHSendTransaction) requestCreateContract(req *eth.SendTransaction
 	}
 
 	var resp *qtum.CreateContractResponse
-	if err := p.Qtum.Request(qtum.MethodCreateContract, qtumreq, &resp); err != nil {
+	if err := p.Qtum.RequestWithContext(ctx, qtum.MethodCreateContract, qtumreq, &resp); err != nil {
 		return nil, err
 	}
 
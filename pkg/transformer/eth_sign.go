@@ -2,6 +2,7 @@ package transformer
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 
@@ -23,7 +24,7 @@ func (p *ProxyETHSign) Method() string {
 	return "eth_sign"
 }
 
-func (p *ProxyETHSign) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHSign) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var req eth.SignRequest
 	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
 		p.GetDebugLogger().Log("method", p.Method(), "error", err)
@@ -32,7 +33,7 @@ func (p *ProxyETHSign) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (inte
 
 	addr := utils.RemoveHexPrefix(req.Account)
 
-	acc := p.Qtum.Accounts.FindByHexAddress(addr)
+	acc := p.Qtum.GetAccounts().FindByHexAddress(addr)
 	if acc == nil {
 		p.GetDebugLogger().Log("method", p.Method(), "account", addr, "msg", "Unknown account")
 		return nil, errors.Errorf("No such account: %s", addr)
@@ -1,9 +1,13 @@
 package transformer
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
 	"strings"
 
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/eth"
@@ -15,61 +19,53 @@ import (
 // ProxyETHSendTransaction implements ETHProxy
 type ProxyETHSignTransaction struct {
 	*qtum.Qtum
+	// key signs the transactions sent from its address in place of the loaded accounts, if set
+	key *btcutil.WIF
 }
 
 func (p *ProxyETHSignTransaction) Method() string {
 	return "eth_signTransaction"
 }
 
-func (p *ProxyETHSignTransaction) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHSignTransaction) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var req eth.SendTransactionRequest
 	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
 		return nil, err
 	}
 
+	return p.request(ctx, &req)
+}
+
+func (p *ProxyETHSignTransaction) request(ctx context.Context, req *eth.SendTransactionRequest) (string, error) {
 	if req.IsCreateContract() {
 		p.GetDebugLogger().Log("method", p.Method(), "msg", "transaction is a create contract request")
-		return p.requestCreateContract(&req)
+		return p.requestCreateContract(ctx, req)
 	} else if req.IsSendEther() {
 		p.GetDebugLogger().Log("method", p.Method(), "msg", "transaction is a send ether request")
-		return p.requestSendToAddress(&req)
+		return p.requestSendToAddress(ctx, req)
 	} else if req.IsCallContract() {
 		p.GetDebugLogger().Log("method", p.Method(), "msg", "transaction is a call contract request")
-		return p.requestSendToContract(&req)
+		return p.requestSendToContract(ctx, req)
 	} else {
 		p.GetDebugLogger().Log("method", p.Method(), "msg", "transaction is an unknown request")
 	}
 
-	return nil, errors.New("Unknown operation")
+	return "", errors.New("Unknown operation")
 }
 
-func (p *ProxyETHSignTransaction) getRequiredUtxos(from string, neededAmount decimal.Decimal) ([]qtum.RawTxInputs, decimal.Decimal, error) {
-	//convert address to qtum address
-	addr := utils.RemoveHexPrefix(from)
-	base58Addr, err := p.FromHexAddress(addr)
-	if err != nil {
-		return nil, decimal.Decimal{}, err
-	}
-	// need to get utxos with txid and vouts. In order to do this we get a list of unspent transactions and begin summing them up
-	var getaddressutxos *qtum.GetAddressUTXOsRequest = &qtum.GetAddressUTXOsRequest{Addresses: []string{base58Addr}}
-	qtumresp, err := p.GetAddressUTXOs(getaddressutxos)
-	if err != nil {
-		return nil, decimal.Decimal{}, err
-	}
-
-	//Convert minSumAmount to Satoshis
-	minimumSum := convertFromQtumToSatoshis(neededAmount)
-	var utxos []qtum.RawTxInputs
-	var minUTXOsSum decimal.Decimal
-	for _, utxo := range *qtumresp {
-		minUTXOsSum = minUTXOsSum.Add(utxo.Satoshis)
-		utxos = append(utxos, qtum.RawTxInputs{TxID: utxo.TXID, Vout: utxo.OutputIndex})
-		if minUTXOsSum.GreaterThanOrEqual(minimumSum) {
-			return utxos, minUTXOsSum, nil
+// findAccount returns the loaded account used to sign transactions sent from a hex address
+func (p *ProxyETHSignTransaction) findAccount(from string) (*qtum.Account, error) {
+	addr := strings.ToLower(utils.RemoveHexPrefix(from))
+	if p.key != nil {
+		if acc := (&qtum.Account{WIF: p.key}); acc.ToHexAddress() == addr {
+			return acc, nil
 		}
 	}
-
-	return nil, decimal.Decimal{}, fmt.Errorf("Insufficient UTXO value attempted to be sent")
+	acc := p.Qtum.GetAccounts().FindByHexAddress(addr)
+	if acc == nil {
+		return nil, errors.Errorf("No such account: %s", addr)
+	}
+	return &qtum.Account{WIF: acc}, nil
 }
 
 func calculateChange(balance, neededAmount decimal.Decimal) (decimal.Decimal, error) {
@@ -83,177 +79,205 @@ func calculateNeededAmount(value, gasLimit, gasPrice decimal.Decimal) decimal.De
 	return value.Add(gasLimit.Mul(gasPrice))
 }
 
-func (p *ProxyETHSignTransaction) requestSendToContract(ethtx *eth.SendTransactionRequest) (string, error) {
-	gasLimit, gasPrice, err := EthGasToQtum(ethtx)
+// signTransaction builds a transaction paying amount into an output locked by pkScript, funded by
+// the account's UTXOs covering neededAmount and the relay fee of the transaction bytes.
+// The change goes back to the sender and the transaction is signed with the account's key.
+// Amounts are in QTUM
+func (p *ProxyETHSignTransaction) signTransaction(ctx context.Context, acc *qtum.Account, amount decimal.Decimal, neededAmount decimal.Decimal, pkScript []byte) (string, error) {
+	senderPkScript, err := qtum.PayToPubKeyHashScript(btcutil.Hash160(acc.SerializePubKey()))
 	if err != nil {
 		return "", err
 	}
 
-	amount := decimal.NewFromFloat(0.0)
-	if ethtx.Value != "" {
-		var err error
-		amount, err = EthValueToQtumAmount(ethtx.Value, ZeroSatoshi)
-		if err != nil {
-			return "", errors.Wrap(err, "EthValueToQtumAmount:")
-		}
-	}
-
-	newGasPrice, err := decimal.NewFromString(gasPrice)
+	base58Addr, err := convertETHAddress(acc.ToHexAddress(), p.Chain())
 	if err != nil {
 		return "", err
 	}
-	neededAmount := calculateNeededAmount(amount, decimal.NewFromBigInt(gasLimit, 0), newGasPrice)
 
-	inputs, balance, err := p.getRequiredUtxos(ethtx.From, neededAmount)
+	feeRate, err := p.GetFeeRate(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	change, err := calculateChange(balance, neededAmount)
+	// the selector adds the fee of every input it picks, the rest of the transaction is paid upfront
+	needed := convertFromQtumToSatoshis(neededAmount)
+	baseFee := qtum.EstimateFee(qtum.EstimateTransactionSize(0, acc.CompressPubKey, pkScript, senderPkScript), feeRate)
+	inputs, err := selectUTXOs(ctx, p.Qtum, base58Addr, needed.Add(baseFee), qtum.FeePerInput(feeRate, acc.CompressPubKey))
 	if err != nil {
 		return "", err
 	}
 
-	contractInteractTx := &qtum.SendToContractRawRequest{
-		ContractAddress: utils.RemoveHexPrefix(ethtx.To),
-		Datahex:         utils.RemoveHexPrefix(ethtx.Data),
-		Amount:          amount,
-		GasLimit:        gasLimit,
-		GasPrice:        gasPrice,
+	balance := qtum.SumUTXOs(inputs)
+	size := qtum.EstimateTransactionSize(len(inputs), acc.CompressPubKey, pkScript, senderPkScript)
+	fee := qtum.EstimateFee(size, feeRate)
+	change, err := calculateChange(balance, needed.Add(fee))
+	if err != nil {
+		return "", err
 	}
-
-	if from := ethtx.From; from != "" && utils.IsEthHexAddress(from) {
-		from, err = p.FromHexAddress(from)
-		if err != nil {
+	if change.LessThanOrEqual(qtum.FeePerInput(feeRate, acc.CompressPubKey)) {
+		// change not worth spending later is left to the staker instead of creating an output
+		size = qtum.EstimateTransactionSize(len(inputs), acc.CompressPubKey, pkScript)
+		fee = balance.Sub(needed)
+		change = decimal.Zero
+	}
+
+	p.GetDebugLogger().Log(
+		"method", p.Method(),
+		"msg", "transaction fee breakdown",
+		"inputs", len(inputs),
+		"balance", balance,
+		"value", convertFromQtumToSatoshis(amount),
+		"gas", needed.Sub(convertFromQtumToSatoshis(amount)),
+		"size", size,
+		"feeRate", feeRate,
+		"fee", fee,
+		"change", change,
+	)
+
+	tx := qtum.NewTransactionBuilder(p.IsMain())
+	for _, utxo := range inputs {
+		prevPkScript := senderPkScript
+		if utxo.Script != "" {
+			prevPkScript, err = hex.DecodeString(utxo.Script)
+			if err != nil {
+				return "", errors.Wrapf(err, "invalid script of UTXO %s:%d", utxo.TXID, utxo.OutputIndex)
+			}
+		}
+		if err := tx.AddInput(utxo.TXID, uint32(utxo.OutputIndex), prevPkScript); err != nil {
 			return "", err
 		}
-		contractInteractTx.SenderAddress = from
 	}
 
-	fromAddr := utils.RemoveHexPrefix(ethtx.From)
-
-	acc := p.Qtum.Accounts.FindByHexAddress(strings.ToLower(fromAddr))
-	if acc == nil {
-		return "", errors.Errorf("No such account: %s", fromAddr)
+	tx.AddOutput(convertFromQtumToSatoshis(amount).IntPart(), pkScript)
+	if change.IsPositive() {
+		tx.AddOutput(change.IntPart(), senderPkScript)
 	}
 
-	rawtxreq := []interface{}{inputs, []interface{}{map[string]*qtum.SendToContractRawRequest{"contract": contractInteractTx}, map[string]decimal.Decimal{contractInteractTx.SenderAddress: change}}}
-	var rawTx string
-	if err := p.Qtum.Request(qtum.MethodCreateRawTx, rawtxreq, &rawTx); err != nil {
+	if err := tx.Sign(acc.WIF); err != nil {
 		return "", err
 	}
 
-	var resp *qtum.SignRawTxResponse
-	if err := p.Qtum.Request(qtum.MethodSignRawTx, []interface{}{rawTx}, &resp); err != nil {
+	rawTx, err := tx.Hex()
+	if err != nil {
 		return "", err
 	}
-	if !resp.Complete {
-		return "", fmt.Errorf("something went wrong with signing the transaction; transaction incomplete")
-	}
-	return utils.AddHexPrefix(resp.Hex), nil
+	return utils.AddHexPrefix(rawTx), nil
 }
 
-func (p *ProxyETHSignTransaction) requestSendToAddress(req *eth.SendTransactionRequest) (string, error) {
-	getQtumWalletAddress := func(addr string) (string, error) {
-		if utils.IsEthHexAddress(addr) {
-			return p.FromHexAddress(utils.RemoveHexPrefix(addr))
-		}
-		return addr, nil
+// gasPriceInSatoshis converts a gas price in QTUM, as returned by EthGasToQtum, to satoshis
+func gasPriceInSatoshis(gasPrice string) (decimal.Decimal, int64, error) {
+	price, err := decimal.NewFromString(gasPrice)
+	if err != nil {
+		return decimal.Decimal{}, 0, err
 	}
+	return price, convertFromQtumToSatoshis(price).IntPart(), nil
+}
 
-	to, err := getQtumWalletAddress(req.To)
+// pubKeyHashFromAddress decodes either a hex or a base58 Qtum address
+func pubKeyHashFromAddress(addr string) ([]byte, error) {
+	if utils.IsEthHexAddress(addr) {
+		return hex.DecodeString(utils.RemoveHexPrefix(addr))
+	}
+	pubKeyHash, _, err := base58.CheckDecode(addr)
 	if err != nil {
-		return "", err
+		return nil, errors.Wrapf(err, "invalid address: %s", addr)
 	}
+	return pubKeyHash, nil
+}
 
-	from, err := getQtumWalletAddress(req.From)
+func (p *ProxyETHSignTransaction) requestSendToContract(ctx context.Context, ethtx *eth.SendTransactionRequest) (string, error) {
+	gasLimit, gasPrice, err := EthGasToQtum(ethtx)
 	if err != nil {
 		return "", err
 	}
 
-	amount, err := EthValueToQtumAmount(req.Value, ZeroSatoshi)
-	if err != nil {
-		return "", errors.Wrap(err, "EthValueToQtumAmount:")
+	amount := decimal.NewFromFloat(0.0)
+	if ethtx.Value != "" {
+		var err error
+		amount, err = EthValueToQtumAmount(ethtx.Value, ZeroSatoshi)
+		if err != nil {
+			return "", errors.Wrap(err, "EthValueToQtumAmount:")
+		}
 	}
 
-	inputs, balance, err := p.getRequiredUtxos(req.From, amount)
+	newGasPrice, gasPriceSatoshis, err := gasPriceInSatoshis(gasPrice)
 	if err != nil {
 		return "", err
 	}
+	neededAmount := calculateNeededAmount(amount, decimal.NewFromBigInt(gasLimit, 0), newGasPrice)
 
-	change, err := calculateChange(balance, amount)
+	acc, err := p.findAccount(ethtx.From)
 	if err != nil {
 		return "", err
 	}
 
-	var addressValMap = map[string]decimal.Decimal{to: amount, from: change}
-	rawtxreq := []interface{}{inputs, addressValMap}
-	var rawTx string
-	if err := p.Qtum.Request(qtum.MethodCreateRawTx, rawtxreq, &rawTx); err != nil {
-		return "", err
+	contract, err := hex.DecodeString(utils.RemoveHexPrefix(ethtx.To))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid contract address")
+	}
+	data, err := hex.DecodeString(utils.RemoveHexPrefix(ethtx.Data))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid data")
 	}
 
-	var resp *qtum.SignRawTxResponse
-	signrawtxreq := []interface{}{rawTx}
-	if err := p.Qtum.Request(qtum.MethodSignRawTx, signrawtxreq, &resp); err != nil {
+	script, err := qtum.ContractCallScript(contract, data, gasLimit.Int64(), gasPriceSatoshis)
+	if err != nil {
 		return "", err
 	}
-	if !resp.Complete {
-		return "", fmt.Errorf("something went wrong with signing the transaction; transaction incomplete")
-	}
-	return utils.AddHexPrefix(resp.Hex), nil
+
+	return p.signTransaction(ctx, acc, amount, neededAmount, script)
 }
 
-func (p *ProxyETHSignTransaction) requestCreateContract(req *eth.SendTransactionRequest) (string, error) {
-	gasLimit, gasPrice, err := EthGasToQtum(req)
+func (p *ProxyETHSignTransaction) requestSendToAddress(ctx context.Context, req *eth.SendTransactionRequest) (string, error) {
+	to, err := pubKeyHashFromAddress(req.To)
 	if err != nil {
 		return "", err
 	}
 
-	from := req.From
-	if utils.IsEthHexAddress(from) {
-		from, err = p.FromHexAddress(from)
-		if err != nil {
-			return "", err
-		}
+	amount, err := EthValueToQtumAmount(req.Value, ZeroSatoshi)
+	if err != nil {
+		return "", errors.Wrap(err, "EthValueToQtumAmount:")
 	}
 
-	contractDeploymentTx := &qtum.CreateContractRawRequest{
-		ByteCode:      utils.RemoveHexPrefix(req.Data),
-		GasLimit:      gasLimit,
-		GasPrice:      gasPrice,
-		SenderAddress: from,
+	acc, err := p.findAccount(req.From)
+	if err != nil {
+		return "", err
 	}
 
-	newGasPrice, err := decimal.NewFromString(gasPrice)
+	script, err := qtum.PayToPubKeyHashScript(to)
 	if err != nil {
 		return "", err
 	}
-	neededAmount := calculateNeededAmount(decimal.NewFromFloat(0.0), decimal.NewFromBigInt(gasLimit, 0), newGasPrice)
 
-	inputs, balance, err := p.getRequiredUtxos(req.From, neededAmount)
+	return p.signTransaction(ctx, acc, amount, amount, script)
+}
+
+func (p *ProxyETHSignTransaction) requestCreateContract(ctx context.Context, req *eth.SendTransactionRequest) (string, error) {
+	gasLimit, gasPrice, err := EthGasToQtum(req)
 	if err != nil {
 		return "", err
 	}
 
-	change, err := calculateChange(balance, neededAmount)
+	newGasPrice, gasPriceSatoshis, err := gasPriceInSatoshis(gasPrice)
 	if err != nil {
 		return "", err
 	}
+	neededAmount := calculateNeededAmount(decimal.NewFromFloat(0.0), decimal.NewFromBigInt(gasLimit, 0), newGasPrice)
 
-	rawtxreq := []interface{}{inputs, []interface{}{map[string]*qtum.CreateContractRawRequest{"contract": contractDeploymentTx}, map[string]decimal.Decimal{from: change}}}
-	var rawTx string
-	if err := p.Qtum.Request(qtum.MethodCreateRawTx, rawtxreq, &rawTx); err != nil {
+	acc, err := p.findAccount(req.From)
+	if err != nil {
 		return "", err
 	}
 
-	var resp *qtum.SignRawTxResponse
-	signrawtxreq := []interface{}{rawTx}
-	if err := p.Qtum.Request(qtum.MethodSignRawTx, signrawtxreq, &resp); err != nil {
-		return "", err
+	byteCode, err := hex.DecodeString(utils.RemoveHexPrefix(req.Data))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid contract byte code")
 	}
-	if !resp.Complete {
-		return "", fmt.Errorf("something went wrong with signing the transaction; transaction incomplete")
+
+	script, err := qtum.ContractCreateScript(byteCode, gasLimit.Int64(), gasPriceSatoshis)
+	if err != nil {
+		return "", err
 	}
-	return utils.AddHexPrefix(resp.Hex), nil
+
+	return p.signTransaction(ctx, acc, decimal.NewFromFloat(0.0), neededAmount, script)
 }
@@ -0,0 +1,36 @@
+package transformer
+
+import (
+	"context"
+
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+// ProxyETHSyncing implements ETHProxy
+type ProxyETHSyncing struct {
+	*qtum.Qtum
+	status qtum.SyncStatus
+}
+
+func (p *ProxyETHSyncing) Method() string {
+	return "eth_syncing"
+}
+
+func (p *ProxyETHSyncing) Request(ctx context.Context, _ *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+	return p.request(ctx)
+}
+
+// request returns false when the Qtum node is synced, the sync progress otherwise
+func (p *ProxyETHSyncing) request(ctx context.Context) (interface{}, error) {
+	qtumresp, err := p.GetBlockChainInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if status := p.status.Update(&qtumresp); status != nil {
+		return status, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,62 @@
+package transformer
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestSyncingRequest(t *testing.T) {
+	requestParams := []json.RawMessage{} //eth_syncing has no params
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	responses := []qtum.GetBlockChainInfoResponse{
+		{Blocks: 1000, Headers: 1500, InitialBlockDownload: true},
+		{Blocks: 1200, Headers: 1500, InitialBlockDownload: true},
+		{Blocks: 1500, Headers: 1501},
+		// the headers aren't synced yet, the highest block is estimated from the verification progress
+		{Blocks: 1600, Headers: 1600, InitialBlockDownload: true, Verificationprogress: 0.5},
+	}
+	for _, response := range responses {
+		if err := mockedClientDoer.AddResponse(qtum.MethodGetBlockChainInfo, response); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wants := []interface{}{
+		&eth.SyncingStatus{StartingBlock: "0x3e8", CurrentBlock: "0x3e8", HighestBlock: "0x5dc"},
+		&eth.SyncingStatus{StartingBlock: "0x3e8", CurrentBlock: "0x4b0", HighestBlock: "0x5dc"},
+		false,
+		&eth.SyncingStatus{StartingBlock: "0x640", CurrentBlock: "0x640", HighestBlock: "0xc80"},
+	}
+
+	proxyEth := ProxyETHSyncing{Qtum: qtumClient}
+	for _, want := range wants {
+		got, err := proxyEth.Request(context.Background(), request, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf(
+				"error\ninput: %s\nwant: %s\ngot: %s",
+				request,
+				string(internal.MustMarshalIndent(want, "", "  ")),
+				string(internal.MustMarshalIndent(got, "", "  ")),
+			)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
 	"github.com/qtumproject/janus/pkg/eth"
@@ -17,7 +19,7 @@ func (p *ProxyETHUninstallFilter) Method() string {
 	return "eth_uninstallFilter"
 }
 
-func (p *ProxyETHUninstallFilter) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyETHUninstallFilter) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var req eth.UninstallFilterRequest
 	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
 		return nil, err
@@ -0,0 +1,77 @@
+package transformer
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+// getBlockMiner returns the hex address of the staker of a PoS block or of the miner of a PoW block
+func getBlockMiner(ctx context.Context, q *qtum.Qtum, blocks *blockCache, header *qtum.GetBlockHeaderResponse, block *qtum.GetBlockResponse) (string, error) {
+	if miner, ok := blocks.miner(block.Hash); ok {
+		return miner, nil
+	}
+	pubKeyHash, err := resolveBlockMiner(ctx, q, header, block)
+	if err != nil {
+		return "", err
+	}
+	miner := utils.AddHexPrefix(hex.EncodeToString(pubKeyHash))
+	blocks.setMiner(block.Hash, miner)
+	return miner, nil
+}
+
+// resolveBlockMiner finds the public key hash paid by the coinstake transaction of a PoS block, or by the
+// coinbase of a PoW block. Coinstakes paying to a script other than P2PK or P2PKH fall back to the signer of
+// their first input, which is the staker.
+//
+// With offline staking the first P2PK or P2PKH coinstake output returns the stake to the delegator, which is
+// the miner reported: the super staker is only paid the delegation fee by a later output, that can't be told
+// apart from a stake split across outputs without the proof of delegation
+func resolveBlockMiner(ctx context.Context, q *qtum.Qtum, header *qtum.GetBlockHeaderResponse, block *qtum.GetBlockResponse) ([]byte, error) {
+	// the coinstake follows the empty coinbase
+	index := 0
+	if header.IsProofOfStake() {
+		index = 1
+	}
+	if index >= len(block.Txs) {
+		return nil, errors.Errorf("block %s has no transaction at index %d", block.Hash, index)
+	}
+	txHash := block.Txs[index]
+
+	rawTx, err := q.GetRawTransaction(ctx, txHash, false)
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't get raw transaction")
+	}
+	for _, vout := range rawTx.Vouts {
+		script, err := hex.DecodeString(vout.Details.Hex)
+		if err != nil {
+			continue
+		}
+		if pubKeyHash, ok := qtum.PayeePubKeyHash(script); ok {
+			return pubKeyHash, nil
+		}
+	}
+
+	if !header.IsProofOfStake() {
+		return nil, errors.Errorf("coinbase %s has no P2PK or P2PKH output", txHash)
+	}
+	decodedTx, err := q.DecodeRawTransaction(ctx, rawTx.Hex)
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't decode raw transaction")
+	}
+	if len(decodedTx.Vins) == 0 {
+		return nil, errors.Errorf("coinstake %s has no inputs", txHash)
+	}
+	scriptSig, err := hex.DecodeString(decodedTx.Vins[0].ScriptSig.Hex)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't decode script signature")
+	}
+	pubKeyHash, ok := qtum.SignerPubKeyHash(scriptSig)
+	if !ok {
+		return nil, errors.Errorf("couldn't find the staker of coinstake %s", txHash)
+	}
+	return pubKeyHash, nil
+}
@@ -0,0 +1,58 @@
+package transformer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestGetBlockMinerFromCoinstakeOutput(t *testing.T) {
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a coinstake paying to the staker's public key
+	coinstake := qtum.GetRawTransactionResponse{
+		Vouts: []qtum.RawTransactionVout{{}, {}},
+	}
+	coinstake.Vouts[1].Details.Hex = "2103520b1500a400483f19b93c4cb277a2f29693ea9d6739daaf6ae6e971d29e3140ac"
+	if err := mockedClientDoer.AddResponse(qtum.MethodGetRawTransaction, &coinstake); err != nil {
+		t.Fatal(err)
+	}
+
+	header := &qtum.GetBlockHeaderResponse{Flags: "proof-of-stake"}
+	block := &qtum.GetBlockResponse{
+		Hash: "5b4d4e0b5fa4c1e0a2b0c5d8d76c4f9a6d7b2e1c0f3a4b5c6d7e8f9a0b1c2d3e",
+		Txs: []string{
+			"3208dc44733cbfa11654ad5651305428de473ef1e61a1ec07b0c1a5f4843be91",
+			"8fcd819194cce6a8454b2bec334d3448df4f097e9cdc36707bfd569900268950",
+		},
+	}
+	want := "0x6b22910b1e302cf74803ffd1691c2ecb858d3712"
+
+	blocks := newBlockCache()
+	got, err := getBlockMiner(context.Background(), qtumClient, blocks, header, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("want miner %s, got %s", want, got)
+	}
+
+	// the miner is cached by block hash
+	cachedClient, err := internal.CreateMockedClient(internal.NewDoerMappedMock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = getBlockMiner(context.Background(), cachedClient, blocks, header, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("want cached miner %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,147 @@
+package transformer
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+// nonceSettledDepth is the number of confirmations after which the transactions of a block are cached,
+// more recent blocks may still be reorganized and are rescanned on every lookup
+const nonceSettledDepth = 100
+
+// NonceTracker counts the transactions sent from each hex address, that is transactions spending
+// at least one of its outputs, to serve as Ethereum nonces
+type NonceTracker struct {
+	qtum *qtum.Qtum
+
+	mutex   sync.Mutex
+	settled map[string]settledNonce
+}
+
+// settledNonce is the number of transactions sent from an address up to and including a block height
+type settledNonce struct {
+	height int64
+	count  uint64
+}
+
+func NewNonceTracker(qtumClient *qtum.Qtum) *NonceTracker {
+	return &NonceTracker{
+		qtum:    qtumClient,
+		settled: make(map[string]settledNonce),
+	}
+}
+
+// Rebuild scans the chain for the transactions sent from the loaded accounts
+func (t *NonceTracker) Rebuild(ctx context.Context) error {
+	blockCount, err := t.qtum.GetBlockCount(ctx)
+	if err != nil {
+		return err
+	}
+
+	accounts := t.qtum.GetAccounts()
+	for _, wif := range accounts {
+		acc := &qtum.Account{WIF: wif}
+		if _, err := t.ConfirmedCount(ctx, acc.ToHexAddress(), blockCount.Int64()); err != nil {
+			return err
+		}
+	}
+
+	t.qtum.GetDebugLogger().Log("component", "NonceTracker", "msg", "rebuilt transaction counts", "accounts", len(accounts), "height", blockCount.Int64())
+	return nil
+}
+
+// ConfirmedCount returns the number of transactions sent from a hex address up to and including a block height
+func (t *NonceTracker) ConfirmedCount(ctx context.Context, hexAddress string, height int64) (uint64, error) {
+	hexAddress = strings.ToLower(utils.RemoveHexPrefix(hexAddress))
+	base58Addr, err := convertETHAddress(hexAddress, t.qtum.Chain())
+	if err != nil {
+		return 0, err
+	}
+
+	t.mutex.Lock()
+	cached, ok := t.settled[hexAddress]
+	t.mutex.Unlock()
+	if !ok || height <= cached.height {
+		// historical lookups scan the whole history
+		cached = settledNonce{}
+	}
+
+	req := &qtum.GetAddressDeltasRequest{Addresses: []string{base58Addr}}
+	if cached.height > 0 {
+		req.Start = cached.height + 1
+		req.End = height
+	}
+	deltas, err := t.qtum.GetAddressDeltas(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		settledHeight = height - nonceSettledDepth
+		count         = cached.count
+		settledCount  = cached.count
+		seen          = make(map[string]bool)
+	)
+	for _, delta := range *deltas {
+		if delta.Height > height || delta.Height <= cached.height || !delta.Satoshis.IsNegative() || seen[delta.TXID] {
+			continue
+		}
+		seen[delta.TXID] = true
+		count++
+		if delta.Height <= settledHeight {
+			settledCount++
+		}
+	}
+
+	if settledHeight > cached.height {
+		t.mutex.Lock()
+		if settledHeight > t.settled[hexAddress].height {
+			t.settled[hexAddress] = settledNonce{height: settledHeight, count: settledCount}
+		}
+		t.mutex.Unlock()
+	}
+
+	return count, nil
+}
+
+// NextNonce returns the nonce of the next transaction sent from a hex address, counting mempool transactions
+func (t *NonceTracker) NextNonce(ctx context.Context, hexAddress string) (uint64, error) {
+	blockCount, err := t.qtum.GetBlockCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count, err := t.ConfirmedCount(ctx, hexAddress, blockCount.Int64())
+	if err != nil {
+		return 0, err
+	}
+	pending, err := t.PendingCount(ctx, hexAddress)
+	if err != nil {
+		return 0, err
+	}
+	return count + pending, nil
+}
+
+// PendingCount returns the number of mempool transactions sent from a hex address
+func (t *NonceTracker) PendingCount(ctx context.Context, hexAddress string) (uint64, error) {
+	base58Addr, err := convertETHAddress(strings.ToLower(utils.RemoveHexPrefix(hexAddress)), t.qtum.Chain())
+	if err != nil {
+		return 0, err
+	}
+
+	mempool, err := t.qtum.GetAddressMempool(ctx, &qtum.GetAddressMempoolRequest{Addresses: []string{base58Addr}})
+	if err != nil {
+		return 0, err
+	}
+
+	seen := make(map[string]bool)
+	for _, delta := range *mempool {
+		if delta.PrevTxID != "" {
+			seen[delta.TXID] = true
+		}
+	}
+	return uint64(len(seen)), nil
+}
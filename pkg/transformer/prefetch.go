@@ -0,0 +1,115 @@
+package transformer
+
+import (
+	"context"
+
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+// prefetchTransactions batches the requests getTransactionByHash sends for each of the hashes and returns a view
+// of the client answering them, a block with hundreds of transactions then takes two round trips to qtumd instead of
+// thousands. When a batch fails p is returned and the requests are sent one at a time as before. The extra requests
+// are sent along with the first batch
+func prefetchTransactions(ctx context.Context, p *qtum.Qtum, hashes []string, extra ...*qtum.BatchRequest) *qtum.Qtum {
+	if len(hashes) == 0 && len(extra) == 0 {
+		return p
+	}
+
+	var (
+		requests = make([]*qtum.BatchRequest, 0, 2*len(hashes)+len(extra))
+		txs      = make([]*qtum.GetTransactionResponse, len(hashes))
+		rawTxs   = make([]*qtum.GetRawTransactionResponse, len(hashes))
+	)
+	for i, hash := range hashes {
+		txs[i] = new(qtum.GetTransactionResponse)
+		rawTxs[i] = new(qtum.GetRawTransactionResponse)
+		requests = append(
+			requests,
+			qtum.NewBatchRequest(qtum.MethodGetTransaction, &qtum.GetTransactionRequest{TxID: hash}, txs[i]),
+			qtum.NewBatchRequest(qtum.MethodGetRawTransaction, &qtum.GetRawTransactionRequest{TxID: hash, Verbose: true}, rawTxs[i]),
+		)
+	}
+	view, err := p.Prefetch(ctx, append(requests, extra...))
+	if err != nil {
+		p.GetDebugLogger().Log("function", "prefetchTransactions", "msg", "couldn't prefetch transactions", "error", err)
+		return p
+	}
+
+	// the transactions are decoded and located in their blocks, the transactions their first inputs spend hold
+	// their senders
+	var next []*qtum.BatchRequest
+	for i := range hashes {
+		tx, txErr := txs[i], requests[2*i].Err
+		rawTx, rawTxErr := rawTxs[i], requests[2*i+1].Err
+		switch {
+		case txErr == nil:
+			next = append(next, qtum.NewBatchRequest(qtum.MethodDecodeRawTransaction, qtum.DecodeRawTransactionRequest(tx.Hex), nil))
+			if !tx.IsPending() {
+				next = append(next, getBlockBatchRequest(tx.BlockHash))
+			}
+		case rawTxErr == nil:
+			next = append(next, qtum.NewBatchRequest(qtum.MethodDecodeRawTransaction, qtum.DecodeRawTransactionRequest(rawTx.Hex), nil))
+			if !rawTx.IsPending() {
+				next = append(next, getBlockBatchRequest(rawTx.BlockHash))
+			}
+		}
+		if rawTxErr == nil {
+			next = append(next, prevTransactionBatchRequests(rawTx)...)
+		}
+	}
+	return prefetchNext(ctx, view, next)
+}
+
+// prevTransactionBatchRequests ask for the transaction spent by the first input, coinbases spend none
+func prevTransactionBatchRequests(rawTx *qtum.GetRawTransactionResponse) []*qtum.BatchRequest {
+	if len(rawTx.Vins) == 0 || rawTx.Vins[0].ID == "" {
+		return nil
+	}
+	return []*qtum.BatchRequest{
+		qtum.NewBatchRequest(qtum.MethodGetRawTransaction, &qtum.GetRawTransactionRequest{TxID: rawTx.Vins[0].ID, Verbose: true}, nil),
+	}
+}
+
+// prefetchReceipt batches the requests of building the receipt of a transaction
+func prefetchReceipt(ctx context.Context, p *qtum.Qtum, hash string) *qtum.Qtum {
+	var (
+		rawTx    = new(qtum.GetRawTransactionResponse)
+		requests = []*qtum.BatchRequest{
+			qtum.NewBatchRequest(qtum.MethodGetTransactionReceipt, qtum.GetTransactionReceiptRequest(hash), nil),
+			qtum.NewBatchRequest(qtum.MethodGetRawTransaction, &qtum.GetRawTransactionRequest{TxID: hash, Verbose: true}, rawTx),
+		}
+	)
+	view, err := p.Prefetch(ctx, requests)
+	if err != nil {
+		p.GetDebugLogger().Log("function", "prefetchReceipt", "msg", "couldn't prefetch receipt", "error", err)
+		return p
+	}
+	if requests[1].Err != nil {
+		return view
+	}
+
+	next := []*qtum.BatchRequest{
+		qtum.NewBatchRequest(qtum.MethodDecodeRawTransaction, qtum.DecodeRawTransactionRequest(rawTx.Hex), nil),
+	}
+	if !rawTx.IsPending() {
+		next = append(next, getBlockBatchRequest(rawTx.BlockHash))
+	}
+	next = append(next, prevTransactionBatchRequests(rawTx)...)
+	return prefetchNext(ctx, view, next)
+}
+
+func getBlockBatchRequest(hash string) *qtum.BatchRequest {
+	return qtum.NewBatchRequest(qtum.MethodGetBlock, &qtum.GetBlockRequest{Hash: hash}, nil)
+}
+
+func prefetchNext(ctx context.Context, view *qtum.Qtum, requests []*qtum.BatchRequest) *qtum.Qtum {
+	if len(requests) == 0 {
+		return view
+	}
+	next, err := view.Prefetch(ctx, requests)
+	if err != nil {
+		view.GetDebugLogger().Log("function", "prefetchNext", "msg", "couldn't prefetch", "error", err)
+		return view
+	}
+	return next
+}
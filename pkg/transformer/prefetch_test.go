@@ -0,0 +1,56 @@
+package transformer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/internal"
+)
+
+// countingDoer counts the HTTP requests sent to qtumd
+type countingDoer struct {
+	internal.Doer
+	requests int32
+}
+
+func (d *countingDoer) Do(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&d.requests, 1)
+	return d.Doer.Do(req)
+}
+
+func TestGetBlockByHashTransactionsAreBatched(t *testing.T) {
+	request, err := internal.PrepareEthRPCRequest(1, []json.RawMessage{[]byte(`"` + internal.GetTransactionByHashBlockHexHash + `"`), []byte(`true`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doer := &countingDoer{Doer: internal.NewDoerMappedMock()}
+	qtumClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.SetupGetBlockByHashResponses(t, doer)
+
+	proxyEth := ProxyETHGetBlockByHash{Qtum: qtumClient}
+	got, err := proxyEth.Request(context.Background(), request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := &internal.GetTransactionByHashResponseWithTransactions; !reflect.DeepEqual(got, want) {
+		t.Errorf(
+			"error\ninput: %s\nwant: %s\ngot: %s",
+			request,
+			string(internal.MustMarshalIndent(want, "", "  ")),
+			string(internal.MustMarshalIndent(got, "", "  ")),
+		)
+	}
+
+	// the block, the transactions and their decoding are each fetched in a single batch
+	if requests := atomic.LoadInt32(&doer.requests); requests != 3 {
+		t.Fatalf("expected 3 requests to qtumd, got %d", requests)
+	}
+}
@@ -1,6 +1,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/eth"
@@ -19,7 +21,7 @@ func (p *ProxyQTUMGetUTXOs) Method() string {
 	return "qtum_getUTXOs"
 }
 
-func (p *ProxyQTUMGetUTXOs) Request(req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *ProxyQTUMGetUTXOs) Request(ctx context.Context, req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var params eth.GetUTXOsRequest
 	if err := unmarshalRequest(req.Params, &params); err != nil {
 		return nil, errors.WithMessage(err, "couldn't unmarshal request parameters")
@@ -30,38 +32,52 @@ func (p *ProxyQTUMGetUTXOs) Request(req *eth.JSONRPCRequest, c echo.Context) (in
 		return nil, errors.WithMessage(err, "couldn't validate parameters value")
 	}
 
-	return p.request(params)
+	return p.request(ctx, params)
 }
 
-func (p *ProxyQTUMGetUTXOs) request(params eth.GetUTXOsRequest) (*eth.GetUTXOsResponse, error) {
+func (p *ProxyQTUMGetUTXOs) request(ctx context.Context, params eth.GetUTXOsRequest) (*eth.GetUTXOsResponse, error) {
 	address, err := convertETHAddress(utils.RemoveHexPrefix(params.Address), p.Chain())
 	if err != nil {
 		return nil, errors.WithMessage(err, "couldn't convert Ethereum address to Qtum address")
 	}
 
-	req := qtum.GetAddressUTXOsRequest{
-		Addresses: []string{address},
+	// the UTXOs only have to sum up to the minimum amount, the caller pays the fee of spending them
+	selected, err := selectUTXOs(ctx, p.Qtum, address, convertFromQtumToSatoshis(params.MinSumAmount), decimal.Zero)
+	if errors.Cause(err) == qtum.ErrInsufficientUTXOs {
+		return nil, errors.WithMessage(err, "required minimum amount is greater than total amount of spendable UTXOs")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	utxos := make([]eth.QtumUTXO, 0, len(selected))
+	for _, utxo := range selected {
+		utxos = append(utxos, toEthResponseType(utxo))
 	}
+	return (*eth.GetUTXOsResponse)(&utxos), nil
+}
 
-	resp, err := p.Qtum.GetAddressUTXOs(&req)
+// selectUTXOs returns the spendable UTXOs of a base58 address picked by the coin selector, whose value covers
+// target plus feePerInput for each of them, in satoshis
+func selectUTXOs(ctx context.Context, p *qtum.Qtum, address string, target decimal.Decimal, feePerInput decimal.Decimal) ([]qtum.UTXO, error) {
+	utxos, err := p.GetAddressUTXOs(ctx, &qtum.GetAddressUTXOsRequest{Addresses: []string{address}})
 	if err != nil {
 		return nil, err
 	}
 
-	//Convert minSumAmount to Satoshis
-	minimumSum := convertFromQtumToSatoshis(params.MinSumAmount)
+	blockCount, err := p.GetBlockCount(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	var utxos []eth.QtumUTXO
-	var minUTXOsSum decimal.Decimal
-	for _, utxo := range *resp {
-		minUTXOsSum = minUTXOsSum.Add(utxo.Satoshis)
-		utxos = append(utxos, toEthResponseType(utxo))
-		if minUTXOsSum.GreaterThanOrEqual(minimumSum) {
-			return (*eth.GetUTXOsResponse)(&utxos), nil
-		}
+	mempool, err := p.GetAddressMempool(ctx, &qtum.GetAddressMempoolRequest{Addresses: []string{address}})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, errors.Errorf("required minimum amount is greater than total amount of UTXOs")
+	spendable := qtum.FilterSpendableUTXOs(*utxos, blockCount.Int64(), *mempool)
+
+	return p.GetCoinSelector().Select(spendable, target, feePerInput)
 }
 
 func toEthResponseType(utxo qtum.UTXO) eth.QtumUTXO {
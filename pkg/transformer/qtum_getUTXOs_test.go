@@ -0,0 +1,75 @@
+package transformer
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/shopspring/decimal"
+)
+
+func TestGetUTXOsRequestCoversMinSumAmount(t *testing.T) {
+	requestParams := []json.RawMessage{[]byte(`"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"`), []byte(`1`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the UTXOs sum up to exactly the minimum amount, the fee of spending them isn't added to it
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressUTXOs, qtum.GetAddressUTXOsResponse{
+		{
+			Address:     "qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW",
+			TXID:        "7e1f6e0a2a0ad7e9c0bd0dff3e0a3a1a0a6a2a1f7b4f0c0e2b9a6b1d7e2c3a4b",
+			OutputIndex: 0,
+			Satoshis:    decimal.NewFromInt(60000000),
+			Height:      big.NewInt(100),
+		},
+		{
+			Address:     "qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW",
+			TXID:        "d2a5ab5c6d8e3f1f5d19f6e0aab1f5cb4e5b6d3b1b0bd3c7f4f7cd5b0a1d2e3f",
+			OutputIndex: 1,
+			Satoshis:    decimal.NewFromInt(40000000),
+			Height:      big.NewInt(100),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(1000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressMempool, qtum.GetAddressMempoolResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyQTUMGetUTXOs{qtumClient}
+	got, err := proxyEth.Request(context.Background(), request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utxos := *got.(*eth.GetUTXOsResponse)
+	sum := decimal.Zero
+	for _, utxo := range utxos {
+		amount, err := decimal.NewFromString(utxo.Amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum = sum.Add(amount)
+	}
+	if len(utxos) != 2 || !sum.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("want both UTXOs summing up to 1 QTUM, got %+v", utxos)
+	}
+}
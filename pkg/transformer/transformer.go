@@ -1,6 +1,12 @@
 package transformer
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/go-kit/kit/log"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
@@ -14,6 +20,14 @@ type Transformer struct {
 	debugMode    bool
 	logger       log.Logger
 	transformers map[string]ETHProxy
+
+	// deadlines of the requests by method, defaultTimeout applies to the other methods
+	defaultTimeout time.Duration
+	methodTimeouts map[string]time.Duration
+
+	// disabled methods answer as if they didn't exist, they can be changed while janus runs
+	disabled      map[string]bool
+	disabledMutex sync.RWMutex
 }
 
 // New creates a new Transformer
@@ -59,23 +73,52 @@ func (t *Transformer) Register(p ETHProxy) error {
 	return nil
 }
 
-// Transform takes a Transformer and transforms the request from ETH request and returns the proxy request
-func (t *Transformer) Transform(req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+// Transform takes a Transformer and transforms the request from ETH request and returns the proxy request,
+// ctx cancels the requests sent to qtumd on its behalf
+func (t *Transformer) Transform(ctx context.Context, req *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	proxy, err := t.getProxy(req.Method)
 	if err != nil {
 		return nil, errors.WithMessage(err, "couldn't get proxy")
 	}
-	resp, err := proxy.Request(req, c)
+	if timeout := t.timeout(req.Method); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	resp, err := proxy.Request(ctx, req, c)
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errors.WithMessagef(ErrRequestTimeout, "couldn't proxy %s request: %s", req.Method, err)
+		}
 		return nil, errors.WithMessagef(err, "couldn't proxy %s request", req.Method)
 	}
 	return resp, nil
 }
 
+func (t *Transformer) timeout(method string) time.Duration {
+	if timeout, ok := t.methodTimeouts[method]; ok {
+		return timeout
+	}
+	return t.defaultTimeout
+}
+
+// HasMethod returns whether a proxy handles the method
+func (t *Transformer) HasMethod(method string) bool {
+	_, ok := t.transformers[method]
+	return ok
+}
+
 func (t *Transformer) getProxy(method string) (ETHProxy, error) {
+	t.disabledMutex.RLock()
+	disabled := t.disabled[method]
+	t.disabledMutex.RUnlock()
+
 	proxy, ok := t.transformers[method]
-	if !ok {
-		return nil, errors.Errorf("The method %s does not exist/is not available", method)
+	if !ok || disabled {
+		return nil, &eth.JSONRPCError{
+			Code:    -32601,
+			Message: fmt.Sprintf("The method %s does not exist/is not available", method),
+		}
 	}
 	return proxy, nil
 }
@@ -85,15 +128,23 @@ func (t *Transformer) IsDebugEnabled() bool {
 }
 
 // DefaultProxies are the default proxy methods made available
-func DefaultProxies(qtumRPCClient *qtum.Qtum, agent *notifier.Agent, cacher *BlockSyncer) []ETHProxy {
+func DefaultProxies(ctx context.Context, qtumRPCClient *qtum.Qtum, agent *notifier.Agent, cacher *BlockSyncer) []ETHProxy {
 	filter := eth.NewFilterSimulator()
-	getFilterChanges := &ProxyETHGetFilterChanges{Qtum: qtumRPCClient, filter: filter}
+	getFilterChanges := &ProxyETHGetFilterChanges{Qtum: qtumRPCClient, filter: filter, agent: agent}
 	ethCall := &ProxyETHCall{Qtum: qtumRPCClient}
+	nonces := NewNonceTracker(qtumRPCClient)
+	blocks := newBlockCache()
 
 	if cacher != nil {
-		cacher.Start()
+		cacher.Start(ctx)
 	}
 
+	go func() {
+		if err := nonces.Rebuild(ctx); err != nil {
+			qtumRPCClient.GetErrorLogger().Log("msg", "Failed to rebuild transaction counts", "err", err)
+		}
+	}()
+
 	return []ETHProxy{
 		ethCall,
 		&ProxyNetListening{Qtum: qtumRPCClient},
@@ -102,24 +153,26 @@ func DefaultProxies(qtumRPCClient *qtum.Qtum, agent *notifier.Agent, cacher *Blo
 		(&ProxyETHBlockNumber{Qtum: qtumRPCClient}).WithBlockCacher(cacher),
 		&ProxyETHHashrate{Qtum: qtumRPCClient},
 		&ProxyETHMining{Qtum: qtumRPCClient},
+		&ProxyETHSyncing{Qtum: qtumRPCClient},
 		&ProxyETHNetVersion{Qtum: qtumRPCClient},
 		&ProxyETHGetTransactionByHash{Qtum: qtumRPCClient},
 		&ProxyETHGetTransactionByBlockNumberAndIndex{Qtum: qtumRPCClient},
 		&ProxyETHGetLogs{Qtum: qtumRPCClient},
-		&ProxyETHGetTransactionReceipt{Qtum: qtumRPCClient},
+		&ProxyETHGetTransactionReceipt{Qtum: qtumRPCClient, blocks: blocks},
 		&ProxyETHSendTransaction{Qtum: qtumRPCClient},
 		&ProxyETHAccounts{Qtum: qtumRPCClient},
 		&ProxyETHGetCode{Qtum: qtumRPCClient},
 
 		&ProxyETHNewFilter{Qtum: qtumRPCClient, filter: filter},
 		&ProxyETHNewBlockFilter{Qtum: qtumRPCClient, filter: filter},
+		&ProxyETHNewPendingTransactionFilter{Qtum: qtumRPCClient, filter: filter, agent: agent},
 		getFilterChanges,
 		&ProxyETHGetFilterLogs{ProxyETHGetFilterChanges: getFilterChanges},
 		&ProxyETHUninstallFilter{Qtum: qtumRPCClient, filter: filter},
 
 		&ProxyETHEstimateGas{ProxyETHCall: ethCall},
-		(&ProxyETHGetBlockByNumber{Qtum: qtumRPCClient}).WithBlockCacher(cacher),
-		&ProxyETHGetBlockByHash{Qtum: qtumRPCClient},
+		(&ProxyETHGetBlockByNumber{Qtum: qtumRPCClient, blocks: blocks}).WithBlockCacher(cacher),
+		&ProxyETHGetBlockByHash{Qtum: qtumRPCClient, blocks: blocks},
 		&ProxyETHGetBalance{Qtum: qtumRPCClient},
 		&ProxyETHGetStorageAt{Qtum: qtumRPCClient},
 		&ETHGetCompilers{},
@@ -131,9 +184,9 @@ func DefaultProxies(qtumRPCClient *qtum.Qtum, agent *notifier.Agent, cacher *Blo
 		&Web3Sha3{},
 		&ProxyETHSign{Qtum: qtumRPCClient},
 		&ProxyETHGasPrice{Qtum: qtumRPCClient},
-		&ProxyETHTxCount{Qtum: qtumRPCClient},
+		&ProxyETHTxCount{Qtum: qtumRPCClient, nonces: nonces},
 		&ProxyETHSignTransaction{Qtum: qtumRPCClient},
-		&ProxyETHSendRawTransaction{Qtum: qtumRPCClient},
+		&ProxyETHSendRawTransaction{Qtum: qtumRPCClient, nonces: nonces},
 
 		&ETHSubscribe{Qtum: qtumRPCClient, Agent: agent},
 		&ETHUnsubscribe{Qtum: qtumRPCClient, Agent: agent},
@@ -151,9 +204,103 @@ func SetDebug(debug bool) func(*Transformer) error {
 	}
 }
 
+// SetRequestTimeouts bounds the time spent on requests, methodTimeouts overrides defaultTimeout for some methods.
+// 0 leaves requests without a deadline
+func SetRequestTimeouts(defaultTimeout time.Duration, methodTimeouts map[string]time.Duration) func(*Transformer) error {
+	return func(t *Transformer) error {
+		if defaultTimeout < 0 {
+			return errors.New("request timeout must not be negative")
+		}
+		for method, timeout := range methodTimeouts {
+			if timeout < 0 {
+				return errors.Errorf("request timeout of %s must not be negative", method)
+			}
+			if _, ok := t.transformers[method]; !ok {
+				return errors.Errorf("request timeout set for unknown method %s", method)
+			}
+		}
+		t.defaultTimeout = defaultTimeout
+		t.methodTimeouts = methodTimeouts
+		return nil
+	}
+}
+
 func SetLogger(l log.Logger) func(*Transformer) error {
 	return func(t *Transformer) error {
 		t.logger = log.WithPrefix(l, "component", "transformer")
 		return nil
 	}
 }
+
+// Namespaces are the prefixes of the methods that can be enabled or disabled together
+var Namespaces = []string{"eth", "net", "web3", "personal", "qtum", "debug"}
+
+// WalletMethods use the accounts loaded in janus or the wallet of qtumd
+var WalletMethods = []string{"eth_accounts", "eth_sendTransaction", "eth_sign", "eth_signTransaction", "personal_unlockAccount"}
+
+// MethodFilter enables the methods of some namespaces, methods listed individually override their namespace
+type MethodFilter struct {
+	// Namespaces are enabled, all of them when empty
+	Namespaces      []string
+	EnabledMethods  []string
+	DisabledMethods []string
+}
+
+func namespace(method string) string {
+	return strings.SplitN(method, "_", 2)[0]
+}
+
+// SetMethodFilter disables the methods filtered out, they are answered with a -32601 method not found error
+func SetMethodFilter(filter MethodFilter) func(*Transformer) error {
+	return func(t *Transformer) error {
+		return t.ReloadMethodFilter(filter)
+	}
+}
+
+// ReloadMethodFilter replaces the methods disabled, the filter is left unchanged when invalid
+func (t *Transformer) ReloadMethodFilter(filter MethodFilter) error {
+	namespaces := make(map[string]bool, len(filter.Namespaces))
+	for _, ns := range filter.Namespaces {
+		if !contains(Namespaces, ns) {
+			return errors.Errorf("unknown namespace %s", ns)
+		}
+		namespaces[ns] = true
+	}
+	for _, methods := range [][]string{filter.EnabledMethods, filter.DisabledMethods} {
+		for _, method := range methods {
+			if _, ok := t.transformers[method]; !ok {
+				return errors.Errorf("unknown method %s", method)
+			}
+		}
+	}
+
+	disabled := make(map[string]bool)
+	for method := range t.transformers {
+		if len(namespaces) != 0 && !namespaces[namespace(method)] {
+			disabled[method] = true
+		}
+	}
+	for _, method := range filter.DisabledMethods {
+		disabled[method] = true
+	}
+	for _, method := range filter.EnabledMethods {
+		if contains(filter.DisabledMethods, method) {
+			return errors.Errorf("method %s is both enabled and disabled", method)
+		}
+		delete(disabled, method)
+	}
+
+	t.disabledMutex.Lock()
+	t.disabled = disabled
+	t.disabledMutex.Unlock()
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
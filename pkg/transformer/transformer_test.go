@@ -0,0 +1,116 @@
+package transformer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+)
+
+// waitingProxy answers once its context is done
+type waitingProxy struct {
+	method string
+}
+
+func (p *waitingProxy) Method() string {
+	return p.method
+}
+
+func (p *waitingProxy) Request(ctx context.Context, _ *eth.JSONRPCRequest, _ echo.Context) (interface{}, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Second):
+		return "done", nil
+	}
+}
+
+func TestTransformRequestTimeouts(t *testing.T) {
+	qtumClient, err := internal.CreateMockedClient(internal.NewDoerMappedMock())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transformer, err := New(
+		qtumClient,
+		[]ETHProxy{&waitingProxy{"eth_getLogs"}, &waitingProxy{"eth_blockNumber"}},
+		SetRequestTimeouts(10*time.Millisecond, map[string]time.Duration{"eth_getLogs": 0}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = transformer.Transform(context.Background(), &eth.JSONRPCRequest{Method: "eth_blockNumber"}, nil)
+	if errors.Cause(err) != ErrRequestTimeout {
+		t.Fatalf("expected the default timeout to apply, got %v", err)
+	}
+
+	got, err := transformer.Transform(context.Background(), &eth.JSONRPCRequest{Method: "eth_getLogs"}, nil)
+	if err != nil || got != "done" {
+		t.Fatalf("expected eth_getLogs to have no deadline, got %v, %v", got, err)
+	}
+
+	// a client going away cancels its request
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = transformer.Transform(ctx, &eth.JSONRPCRequest{Method: "eth_getLogs"}, nil)
+	if errors.Cause(err) != context.Canceled {
+		t.Fatalf("expected the request to be cancelled, got %v", err)
+	}
+
+	_, err = New(qtumClient, nil, SetRequestTimeouts(time.Second, map[string]time.Duration{"eth_unknown": time.Second}))
+	if err == nil {
+		t.Fatal("expected a timeout of an unknown method to be rejected")
+	}
+}
+
+func TestTransformMethodFilter(t *testing.T) {
+	qtumClient, err := internal.CreateMockedClient(internal.NewDoerMappedMock())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxies := []ETHProxy{
+		&waitingProxy{"eth_blockNumber"},
+		&waitingProxy{"eth_sign"},
+		&waitingProxy{"net_version"},
+		&waitingProxy{"web3_clientVersion"},
+	}
+	transformer, err := New(qtumClient, proxies, SetRequestTimeouts(time.Millisecond, nil), SetMethodFilter(MethodFilter{
+		Namespaces:      []string{"eth", "net"},
+		EnabledMethods:  []string{"web3_clientVersion"},
+		DisabledMethods: []string{"eth_sign"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for method, enabled := range map[string]bool{
+		"eth_blockNumber":    true,
+		"eth_sign":           false,
+		"net_version":        true,
+		"web3_clientVersion": true,
+		"eth_unknown":        false,
+	} {
+		_, err := transformer.Transform(context.Background(), &eth.JSONRPCRequest{Method: method}, nil)
+		jerr, notFound := errors.Cause(err).(*eth.JSONRPCError)
+		notFound = notFound && jerr.Code == -32601
+		if notFound == enabled {
+			t.Errorf("expected %s to be enabled: %v, got %v", method, enabled, err)
+		}
+	}
+
+	for _, filter := range []MethodFilter{
+		{Namespaces: []string{"unknown"}},
+		{DisabledMethods: []string{"eth_unknown"}},
+		{EnabledMethods: []string{"eth_sign"}, DisabledMethods: []string{"eth_sign"}},
+	} {
+		if _, err := New(qtumClient, proxies, SetMethodFilter(filter)); err == nil {
+			t.Errorf("expected filter %+v to be rejected", filter)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"errors"
 
 	"github.com/labstack/echo"
@@ -9,9 +10,12 @@ import (
 
 var UnmarshalRequestErr = errors.New("Input is invalid")
 
+// ErrRequestTimeout is returned when a request takes longer than the deadline of its method
+var ErrRequestTimeout = &eth.JSONRPCError{Code: -32000, Message: "request timed out"}
+
 type Option func(*Transformer) error
 
 type ETHProxy interface {
-	Request(*eth.JSONRPCRequest, echo.Context) (interface{}, error)
+	Request(context.Context, *eth.JSONRPCRequest, echo.Context) (interface{}, error)
 	Method() string
 }
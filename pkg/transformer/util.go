@@ -2,6 +2,7 @@ package transformer
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -132,44 +133,70 @@ func unmarshalRequest(data []byte, v interface{}) error {
 	return nil
 }
 
-// NOTE:
-// 	- is not for reward transactions
-// 	- Vin[i].N (vout number) -> get Transaction(txID).Vout[N].Address
+// getNonContractTxSenderAddress returns the hex address owning the output spent by the first input, which is
+// the sender by the same convention qtumd uses for contract calls without OP_SENDER. Coinbases and inputs spending
+// other than P2PK or P2PKH outputs, like P2SH and segwit ones, have the zero address
+//
 // 	- returning address already has 0x prefix
-func getNonContractTxSenderAddress(p *qtum.Qtum, vins []*qtum.DecodedRawTransactionInV) (string, error) {
-	for _, vin := range vins {
-		prevQtumTx, err := p.GetRawTransaction(vin.TxID, false)
-		if err != nil {
-			return "", errors.WithMessage(err, "couldn't get vin's previous transaction")
-		}
-		for _, out := range prevQtumTx.Vouts {
-			for _, address := range out.Details.Addresses {
-				return utils.AddHexPrefix(address), nil
-			}
+func getNonContractTxSenderAddress(ctx context.Context, p *qtum.Qtum, vins []*qtum.DecodedRawTransactionInV) (string, error) {
+	zeroAddress := utils.AddHexPrefix(qtum.ZeroAddress)
+	if len(vins) == 0 || vins[0].TxID == "" {
+		// the coinbase input has no previous output
+		return zeroAddress, nil
+	}
+	prevQtumTx, err := p.GetRawTransaction(ctx, vins[0].TxID, false)
+	if err != nil {
+		if errors.Cause(err) == qtum.ErrInvalidAddress {
+			// qtumd runs without -txindex
+			p.GetDebugLogger().Log("function", "getNonContractTxSenderAddress", "msg", "previous transaction isn't indexed", "txid", vins[0].TxID)
+			return zeroAddress, nil
 		}
+		return "", errors.WithMessage(err, "couldn't get vin's previous transaction")
+	}
+	if vins[0].Vout < 0 || vins[0].Vout >= int64(len(prevQtumTx.Vouts)) {
+		return "", errors.Errorf("previous transaction %s has no output %d", vins[0].TxID, vins[0].Vout)
+	}
+	script, err := hex.DecodeString(prevQtumTx.Vouts[vins[0].Vout].Details.Hex)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid previous output script")
 	}
-	return "", errors.New("not found")
+	pubKeyHash, ok := qtum.PayeePubKeyHash(script)
+	if !ok {
+		return zeroAddress, nil
+	}
+	return utils.AddHexPrefix(hex.EncodeToString(pubKeyHash)), nil
 }
 
-// NOTE:
-// 	- is not for reward transactions
-// 	- returning address already has 0x prefix
+// findNonContractTxReceiverAddress returns the hex address of the first output paying a P2PK or P2PKH script other
+// than the sender's, outputs paying the sender back being change. Transactions only paying the sender, like
+// coinstakes, are sent to the sender, and those without such outputs to the zero address
 //
-// 	TODO: researching
-// 	- Vout[0].Addresses[i] != "" - temporary solution
-func findNonContractTxReceiverAddress(vouts []*qtum.DecodedRawTransactionOutV) (string, error) {
+// 	- returning address already has 0x prefix
+func findNonContractTxReceiverAddress(vouts []*qtum.DecodedRawTransactionOutV, sender string) string {
+	senderPaid := false
 	for _, vout := range vouts {
-		for _, address := range vout.ScriptPubKey.Addresses {
-			if address != "" {
-				return utils.AddHexPrefix(address), nil
-			}
+		script, err := hex.DecodeString(vout.ScriptPubKey.Hex)
+		if err != nil {
+			continue
+		}
+		pubKeyHash, ok := qtum.PayeePubKeyHash(script)
+		if !ok {
+			continue
 		}
+		address := utils.AddHexPrefix(hex.EncodeToString(pubKeyHash))
+		if address != sender {
+			return address
+		}
+		senderPaid = true
+	}
+	if senderPaid {
+		return sender
 	}
-	return "", errors.New("not found")
+	return utils.AddHexPrefix(qtum.ZeroAddress)
 }
 
-func getBlockNumberByHash(p *qtum.Qtum, hash string) (uint64, error) {
-	block, err := p.GetBlock(hash)
+func getBlockNumberByHash(ctx context.Context, p *qtum.Qtum, hash string) (uint64, error) {
+	block, err := p.GetBlock(ctx, hash)
 	if err != nil {
 		return 0, errors.WithMessage(err, "couldn't get block")
 	}
@@ -177,8 +204,8 @@ func getBlockNumberByHash(p *qtum.Qtum, hash string) (uint64, error) {
 	return uint64(block.Height), nil
 }
 
-func getTransactionIndexInBlock(p *qtum.Qtum, txHash string, blockHash string) (int64, error) {
-	block, err := p.GetBlock(blockHash)
+func getTransactionIndexInBlock(ctx context.Context, p *qtum.Qtum, txHash string, blockHash string) (int64, error) {
+	block, err := p.GetBlock(ctx, blockHash)
 	if err != nil {
 		return -1, errors.WithMessage(err, "couldn't get block")
 	}
@@ -211,7 +238,7 @@ func formatQtumNonce(nonce int) string {
 // 	- string "earliest" for the genesis block
 // 	- string "pending" - for the pending state/transactions
 // Uses defaultVal to differntiate from a eth_getBlockByNumber req and eth_getLogs/eth_newFilter
-func getBlockNumberByRawParam(p *qtum.Qtum, rawParam json.RawMessage, defaultVal bool) (*big.Int, error) {
+func getBlockNumberByRawParam(ctx context.Context, p *qtum.Qtum, rawParam json.RawMessage, defaultVal bool) (*big.Int, error) {
 	var param string
 	if isBytesOfString(rawParam) {
 		param = string(rawParam[1 : len(rawParam)-1]) // trim \" runes
@@ -223,13 +250,13 @@ func getBlockNumberByRawParam(p *qtum.Qtum, rawParam json.RawMessage, defaultVal
 		return nil, errors.Errorf("invalid parameter format - string or integer is expected")
 	}
 
-	return getBlockNumberByParam(p, param, defaultVal)
+	return getBlockNumberByParam(ctx, p, param, defaultVal)
 }
 
-func getBlockNumberByParam(p *qtum.Qtum, param string, defaultVal bool) (*big.Int, error) {
+func getBlockNumberByParam(ctx context.Context, p *qtum.Qtum, param string, defaultVal bool) (*big.Int, error) {
 	if len(param) < 1 {
 		if defaultVal {
-			res, err := p.GetBlockChainInfo()
+			res, err := p.GetBlockChainInfo(ctx)
 			if err != nil {
 				return nil, err
 			}
@@ -243,7 +270,7 @@ func getBlockNumberByParam(p *qtum.Qtum, param string, defaultVal bool) (*big.In
 
 	switch param {
 	case "latest":
-		res, err := p.GetBlockChainInfo()
+		res, err := p.GetBlockChainInfo(ctx)
 		if err != nil {
 			return nil, err
 		}
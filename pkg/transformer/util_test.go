@@ -1,10 +1,13 @@
 package transformer
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
@@ -165,3 +168,110 @@ func TestSendTransactionRequestHasDefaultGasPriceAndAmount(t *testing.T) {
 		t.Fatalf("Default gas amount does not match expected default, got: %s want: %s", req.Gas.Int.String(), eth.DefaultGasAmountForQtum.String())
 	}
 }
+
+func TestNonContractTxSenderAddress(t *testing.T) {
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prevTx := &qtum.GetRawTransactionResponse{Vouts: make([]qtum.RawTransactionVout, 3)}
+	// P2PKH
+	prevTx.Vouts[0].Details.Hex = "76a9147926223070547d2d15b2ef5e7383e541c338ffe988ac"
+	// P2PK
+	prevTx.Vouts[1].Details.Hex = "2103520b1500a400483f19b93c4cb277a2f29693ea9d6739daaf6ae6e971d29e3140ac"
+	// P2SH
+	prevTx.Vouts[2].Details.Hex = "a9147926223070547d2d15b2ef5e7383e541c338ffe987"
+	prevTxID := "7f5350dc474f2953a3f30282c1afcad2fb61cdcea5bd949c808ecc6f64ce1503"
+	if err := mockedClientDoer.AddResponseWithParams(qtum.MethodGetRawTransaction, json.RawMessage(`["`+prevTxID+`",true]`), prevTx); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		vins []*qtum.DecodedRawTransactionInV
+		want string
+	}{
+		{"coinbase", []*qtum.DecodedRawTransactionInV{{}}, "0x0000000000000000000000000000000000000000"},
+		{"P2PKH", []*qtum.DecodedRawTransactionInV{{TxID: prevTxID, Vout: 0}}, "0x7926223070547d2d15b2ef5e7383e541c338ffe9"},
+		{"P2PK coinstake", []*qtum.DecodedRawTransactionInV{{TxID: prevTxID, Vout: 1}}, "0x6b22910b1e302cf74803ffd1691c2ecb858d3712"},
+		{"P2SH", []*qtum.DecodedRawTransactionInV{{TxID: prevTxID, Vout: 2}}, "0x0000000000000000000000000000000000000000"},
+		{"multiple inputs", []*qtum.DecodedRawTransactionInV{{TxID: prevTxID, Vout: 1}, {TxID: prevTxID, Vout: 0}}, "0x6b22910b1e302cf74803ffd1691c2ecb858d3712"},
+	}
+	for _, c := range cases {
+		got, err := getNonContractTxSenderAddress(context.Background(), qtumClient, c.vins)
+		if err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		if got != c.want {
+			t.Errorf("%s: want sender %s, got %s", c.name, c.want, got)
+		}
+	}
+
+	if _, err := getNonContractTxSenderAddress(context.Background(), qtumClient, []*qtum.DecodedRawTransactionInV{{TxID: prevTxID, Vout: 3}}); err == nil {
+		t.Error("expected an error spending a missing output")
+	}
+}
+
+func TestNonContractTxReceiverAddress(t *testing.T) {
+	vouts := func(scripts ...string) []*qtum.DecodedRawTransactionOutV {
+		outs := make([]*qtum.DecodedRawTransactionOutV, len(scripts))
+		for i, script := range scripts {
+			outs[i] = new(qtum.DecodedRawTransactionOutV)
+			outs[i].ScriptPubKey.Hex = script
+		}
+		return outs
+	}
+	var (
+		sender   = "0x7926223070547d2d15b2ef5e7383e541c338ffe9"
+		toSender = "76a9147926223070547d2d15b2ef5e7383e541c338ffe988ac"
+		receiver = "0x6b22910b1e302cf74803ffd1691c2ecb858d3712"
+		toP2PK   = "2103520b1500a400483f19b93c4cb277a2f29693ea9d6739daaf6ae6e971d29e3140ac"
+		zero     = "0x0000000000000000000000000000000000000000"
+	)
+
+	cases := []struct {
+		name   string
+		vouts  []*qtum.DecodedRawTransactionOutV
+		sender string
+		want   string
+	}{
+		{"change first", vouts(toSender, toP2PK), sender, receiver},
+		{"coinstake", vouts("", toSender, toSender), sender, sender},
+		{"coinbase", vouts(toP2PK), zero, receiver},
+		{"empty coinbase", vouts(""), zero, zero},
+	}
+	for _, c := range cases {
+		if got := findNonContractTxReceiverAddress(c.vouts, c.sender); got != c.want {
+			t.Errorf("%s: want receiver %s, got %s", c.name, c.want, got)
+		}
+	}
+}
+
+func TestIndexedTransactionIsLocatedInItsBlock(t *testing.T) {
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txHash := internal.GetBlockResponse.Txs[1]
+	if err := mockedClientDoer.AddResponse(qtum.MethodGetRawTransaction, &qtum.GetRawTransactionResponse{
+		BlockHash: internal.GetTransactionByHashBlockHash,
+		Hex:       "00",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mockedClientDoer.AddResponse(qtum.MethodGetBlock, internal.GetBlockResponse); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := getIndexedTransaction(context.Background(), qtumClient, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.BlockIndex != 1 || tx.BlockHash != internal.GetTransactionByHashBlockHash || tx.Hex != "00" {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+}
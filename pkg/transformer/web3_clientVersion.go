@@ -1,6 +1,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/labstack/echo"
 	"github.com/qtumproject/janus/pkg/eth"
 )
@@ -14,7 +16,7 @@ func (p *Web3ClientVersion) Method() string {
 	return "web3_clientVersion"
 }
 
-func (p *Web3ClientVersion) Request(_ *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *Web3ClientVersion) Request(ctx context.Context, _ *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	return "QTUM ETHTestRPC/ethereum-js", nil
 }
 
@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -16,7 +17,7 @@ func (p *Web3Sha3) Method() string {
 	return "web3_sha3"
 }
 
-func (p *Web3Sha3) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
+func (p *Web3Sha3) Request(ctx context.Context, rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, error) {
 	var err error
 	var req eth.Web3Sha3Request
 	if err = json.Unmarshal(rawreq.Params, &req); err != nil {